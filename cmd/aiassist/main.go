@@ -28,14 +28,7 @@ func init() {
 }
 
 func main() {
-	// Initialize configuration to get language setting
-	cfg := config.Get()
-
-	// Setup global interrupt handler
-	// This creates a context that gets cancelled on Ctrl+C
-	// and prints exit message before terminating
-	cmd.SetupInterruptHandler(cfg.GetLanguage())
-
+	// The global interrupt handler is set up once flags are applied, see applyGlobalFlags
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
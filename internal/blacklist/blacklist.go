@@ -50,6 +50,17 @@ func (c *Checker) IsBlacklisted(command string) (bool, string) {
 	return false, ""
 }
 
+// Match reports whether command matches pattern using the same rules as IsBlacklisted.
+// It is shared with other pattern lists, such as the auto-approve allowlist.
+func Match(pattern, command string) bool {
+	cmdParts := strings.Fields(command)
+	if len(cmdParts) == 0 {
+		return false
+	}
+	cmdParts[0] = filepath.Base(cmdParts[0])
+	return matchPattern(pattern, cmdParts)
+}
+
 // matchPattern reports whether cmdParts matches the given pattern.
 func matchPattern(pattern string, cmdParts []string) bool {
 	patParts := strings.Fields(pattern)
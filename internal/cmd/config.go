@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/llaoj/aiassist/internal/config"
 	"github.com/llaoj/aiassist/internal/ui"
 	"github.com/spf13/cobra"
@@ -24,9 +25,300 @@ var configViewCmd = &cobra.Command{
 	},
 }
 
+var configInitCmd = &cobra.Command{
+	Use:     "init",
+	Short:   "Create or update configuration interactively",
+	Long:    "Interactive wizard that sets the language, adds a provider with its API key and models, and chooses the default model",
+	Args:    cobra.NoArgs,
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigInit()
+	},
+}
+
+var configGetReveal bool
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "Get a configuration value",
+	Long: `Print the value at a configuration path.
+
+Paths:
+  language
+  default_model
+  blacklist
+  consul.enabled | consul.address | consul.key | consul.token
+  providers.<name>.base_url | providers.<name>.api_key | providers.<name>.enabled
+  providers.<name>.models
+  providers.<name>.models.<model>.enabled`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := config.Get().GetValue(args[0])
+		if err != nil {
+			return err
+		}
+		if !configGetReveal && (strings.HasSuffix(args[0], ".api_key") || args[0] == "consul.token") {
+			value = maskSecret(value)
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <path> <value>",
+	Short: "Set a configuration value",
+	Long: `Set the value at a configuration path and save the configuration file.
+See 'aiassist config get --help' for the list of paths. Lists are comma-separated.
+
+Examples:
+  aiassist config set language zh
+  aiassist config set default_model openai/gpt-4o
+  aiassist config set providers.openai.api_key env:OPENAI_KEY
+  aiassist config set providers.openai.models.gpt-4o.enabled false
+  aiassist config set blacklist "rm *,dd *"`,
+	Args:    cobra.ExactArgs(2),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().SetValue(args[0], args[1]); err != nil {
+			return err
+		}
+		color.Green("✓ %s updated\n", args[0])
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate configuration",
+	Long:  "Check the configuration schema, duplicate provider and model names, URLs and that default_model exists",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validateConfig()
+	},
+}
+
+var configProviderCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Manage providers",
+}
+
+var (
+	providerAddBaseURL  string
+	providerAddAPIKey   string
+	providerAddModels   []string
+	providerAddDisabled bool
+)
+
+var configProviderAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a provider",
+	Long: `Add an OpenAI-compatible provider.
+
+Example:
+  aiassist config provider add openai --base-url https://api.openai.com/v1 \
+    --api-key env:OPENAI_KEY --model gpt-4o --model gpt-4o-mini`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Get()
+		name := args[0]
+		if err := config.ValidateProviderName(name); err != nil {
+			return err
+		}
+		if cfg.GetProvider(name) != nil {
+			return fmt.Errorf("provider %s already exists (change it with: aiassist config set providers.%s.<field> <value>)", name, name)
+		}
+
+		provider := &config.ProviderConfig{
+			BaseURL: strings.TrimRight(providerAddBaseURL, "/"),
+			APIKey:  providerAddAPIKey,
+			Enabled: !providerAddDisabled,
+		}
+		for _, model := range providerAddModels {
+			provider.Models = append(provider.Models, &config.ModelConfig{Name: model, Enabled: true})
+		}
+
+		if err := cfg.AddProvider(name, provider); err != nil {
+			return err
+		}
+		color.Green("✓ Provider %s added\n", name)
+		return nil
+	},
+}
+
+var configProviderRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a provider",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().DeleteProvider(args[0]); err != nil {
+			return err
+		}
+		color.Green("✓ Provider %s removed\n", args[0])
+		return nil
+	},
+}
+
+var configProviderEnableCmd = &cobra.Command{
+	Use:     "enable <name>",
+	Short:   "Enable a provider",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().SetProviderEnabled(args[0], true); err != nil {
+			return err
+		}
+		color.Green("✓ Provider %s enabled\n", args[0])
+		return nil
+	},
+}
+
+var configProviderDisableCmd = &cobra.Command{
+	Use:     "disable <name>",
+	Short:   "Disable a provider",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().SetProviderEnabled(args[0], false); err != nil {
+			return err
+		}
+		color.Green("✓ Provider %s disabled\n", args[0])
+		return nil
+	},
+}
+
+var configModelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Manage models",
+}
+
+var configModelEnableCmd = &cobra.Command{
+	Use:     "enable <provider/model>",
+	Short:   "Enable a model",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().SetModelEnabled(args[0], true); err != nil {
+			return err
+		}
+		color.Green("✓ Model %s enabled\n", args[0])
+		return nil
+	},
+}
+
+var configModelDisableCmd = &cobra.Command{
+	Use:     "disable <provider/model>",
+	Short:   "Disable a model",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().SetModelEnabled(args[0], false); err != nil {
+			return err
+		}
+		color.Green("✓ Model %s disabled\n", args[0])
+		return nil
+	},
+}
+
+var configModelDefaultCmd = &cobra.Command{
+	Use:     "default <provider/model>",
+	Short:   "Set the default model",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: requireLocalConfig,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Get().SetDefaultModel(args[0]); err != nil {
+			return err
+		}
+		color.Green("✓ Default model set to %s\n", args[0])
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configViewCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configGetCmd.Flags().BoolVar(&configGetReveal, "reveal", false, "Show secrets (api_key, consul.token) unmasked")
+
+	configProviderAddCmd.Flags().StringVar(&providerAddBaseURL, "base-url", "", "OpenAI-compatible API base URL")
+	configProviderAddCmd.Flags().StringVar(&providerAddAPIKey, "api-key", "", "API key or reference (env:VAR, file:PATH, exec:CMD, vault:PATH#FIELD)")
+	configProviderAddCmd.Flags().StringSliceVar(&providerAddModels, "model", nil, "Model name (repeatable or comma-separated)")
+	configProviderAddCmd.Flags().BoolVar(&providerAddDisabled, "disabled", false, "Add the provider disabled")
+	configProviderAddCmd.MarkFlagRequired("base-url")
+	configProviderAddCmd.MarkFlagRequired("model")
+
+	configProviderCmd.AddCommand(configProviderAddCmd)
+	configProviderCmd.AddCommand(configProviderRemoveCmd)
+	configProviderCmd.AddCommand(configProviderEnableCmd)
+	configProviderCmd.AddCommand(configProviderDisableCmd)
+	configCmd.AddCommand(configProviderCmd)
+
+	configModelCmd.AddCommand(configModelEnableCmd)
+	configModelCmd.AddCommand(configModelDisableCmd)
+	configModelCmd.AddCommand(configModelDefaultCmd)
+	configCmd.AddCommand(configModelCmd)
+}
+
+// requireLocalConfig refuses commands that write the config file when Consul mode is active,
+// since the local file then only holds connection info and the real config lives in Consul KV
+func requireLocalConfig(cmd *cobra.Command, args []string) error {
+	cfg := config.Get()
+	if cfg.IsConsulMode() {
+		return fmt.Errorf("configuration is managed by Consul (key: %s), changes must be made in Consul KV", cfg.Consul.Key)
+	}
+	return nil
+}
+
+// maskSecret hides most of a literal secret; secret references are shown as-is
+func maskSecret(value string) string {
+	if config.IsSecretReference(value) {
+		return value
+	}
+	if len(value) >= 12 {
+		return value[:8] + "..." + value[len(value)-4:]
+	}
+	return strings.Repeat("*", len(value))
+}
+
+// overrideMark marks values set by a global flag or AIASSIST_* environment variable
+func overrideMark(overridden bool) string {
+	if overridden {
+		return " [OVERRIDE]"
+	}
+	return ""
+}
+
+func validateConfig() error {
+	cfg := config.Get()
+
+	var problems []error
+	if !cfg.IsConsulMode() && cfg.ConfigExists() {
+		if err := config.ValidateFile(cfg.ConfigFile); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	problems = append(problems, cfg.Validate()...)
+
+	if len(cfg.GetAllProviders()) == 0 {
+		problems = append(problems, fmt.Errorf("providers: no providers configured"))
+	}
+
+	if len(problems) == 0 {
+		color.Green("✓ Configuration is valid\n")
+		return nil
+	}
+
+	for _, problem := range problems {
+		color.Red("✗ %v\n", problem)
+	}
+	return fmt.Errorf("configuration is invalid (%d problems)", len(problems))
 }
 
 func viewConfig() {
@@ -42,14 +334,18 @@ func viewConfig() {
 	if lang == config.LanguageChinese {
 		langDisplay = "中文"
 	}
-	fmt.Printf("Language: %s (%s)\n", langDisplay, lang)
+	overrides := cfg.GetOverrides()
+	fmt.Printf("Language: %s (%s)%s\n", langDisplay, lang, overrideMark(overrides.Language != ""))
 
 	// Default Model
-	defaultModel := cfg.DefaultModel
+	defaultModel := cfg.GetDefaultModel()
 	if defaultModel == "" {
 		fmt.Printf("Default Model: Not set\n")
 	} else {
-		fmt.Printf("Default Model: %s\n", defaultModel)
+		fmt.Printf("Default Model: %s%s\n", defaultModel, overrideMark(overrides.Model != ""))
+	}
+	if overrides.Provider != "" {
+		fmt.Printf("Provider: %s [OVERRIDE]\n", overrides.Provider)
 	}
 
 	// Config file location
@@ -69,10 +365,9 @@ func viewConfig() {
 			}
 			fmt.Printf("%d. %s [%s]\n", i+1, p.Name, status)
 			fmt.Printf("   Base URL: %s\n", p.BaseURL)
-			if len(p.APIKey) >= 12 {
-				fmt.Printf("   API Key: %s...%s\n", p.APIKey[:8], p.APIKey[len(p.APIKey)-4:])
-			} else if len(p.APIKey) > 0 {
-				fmt.Printf("   API Key: %s\n", strings.Repeat("*", len(p.APIKey)))
+			if len(p.APIKey) > 0 {
+				// References are shown as-is, never the resolved secret
+				fmt.Printf("   API Key: %s\n", maskSecret(p.APIKey))
 			} else {
 				fmt.Printf("   API Key: (not set)\n")
 			}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/i18n"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// providerPreset is a well-known OpenAI-compatible provider offered by the init wizard
+type providerPreset struct {
+	label   string
+	name    string
+	baseURL string
+	models  string
+}
+
+var providerPresets = []providerPreset{
+	{label: "Alibaba Cloud Bailian (Qwen)", name: "bailian", baseURL: "https://dashscope.aliyuncs.com/compatible-mode/v1", models: "qwen-max,qwen-plus"},
+	{label: "DeepSeek", name: "deepseek", baseURL: "https://api.deepseek.com/v1", models: "deepseek-chat"},
+	{label: "OpenAI", name: "openai", baseURL: "https://api.openai.com/v1", models: "gpt-4o,gpt-4o-mini"},
+	{label: "Other OpenAI-compatible API", name: "", baseURL: "", models: ""},
+}
+
+func runConfigInit() error {
+	err := configWizard()
+	if errors.Is(err, ui.ErrInterrupted) {
+		fmt.Println()
+		color.Yellow("Configuration wizard cancelled, no further changes made\n")
+		return nil
+	}
+	return err
+}
+
+func configWizard() error {
+	cfg := config.Get()
+	translator := i18n.New(cfg.GetLanguage())
+
+	color.Cyan(ui.Separator() + "\n")
+	color.Cyan("AI Shell Assistant configuration\n")
+	color.Cyan(ui.Separator() + "\n")
+	fmt.Printf("Config File: %s\n\n", cfg.ConfigFile)
+
+	if cfg.ConfigExists() {
+		update, err := ui.PromptConfirm("Configuration file already exists. Add a provider and update it?", translator)
+		if err != nil {
+			return err
+		}
+		if !update {
+			return nil
+		}
+		fmt.Println()
+	}
+
+	// Language
+	langIndex, err := ui.PromptSelect("Select language:", []string{"English (en)", "中文 (zh)"}, translator)
+	if err != nil {
+		return err
+	}
+	lang := config.LanguageEnglish
+	if langIndex == 1 {
+		lang = config.LanguageChinese
+	}
+	if err := cfg.SetLanguage(lang); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	for {
+		if err := wizardAddProvider(cfg, translator); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		another, err := ui.PromptConfirm("Add another provider?", translator)
+		if err != nil {
+			return err
+		}
+		if !another {
+			break
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	if err := wizardSelectDefaultModel(cfg, translator); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	color.Green("✓ Configuration saved to %s\n", cfg.ConfigFile)
+	if problems := cfg.Validate(); len(problems) > 0 {
+		for _, problem := range problems {
+			color.Yellow("⚠ %v\n", problem)
+		}
+	}
+	return nil
+}
+
+func wizardAddProvider(cfg *config.Config, translator *i18n.I18n) error {
+	labels := make([]string, len(providerPresets))
+	for i, preset := range providerPresets {
+		labels[i] = preset.label
+	}
+	presetIndex, err := ui.PromptSelect("Select provider:", labels, translator)
+	if err != nil {
+		return err
+	}
+	preset := providerPresets[presetIndex]
+	fmt.Println()
+
+	var name string
+	for {
+		if name, err = wizardInput("Provider name:", preset.name, translator); err != nil {
+			return err
+		}
+		if err = config.ValidateProviderName(name); err == nil {
+			break
+		}
+		color.Yellow("%v\n", err)
+	}
+	baseURL, err := wizardInput("Base URL:", preset.baseURL, translator)
+	if err != nil {
+		return err
+	}
+	apiKey, err := ui.PromptInput("API key (literal key or reference such as env:OPENAI_KEY, file:/path, exec:cmd, vault:path#field):", translator)
+	if err != nil {
+		return err
+	}
+	models, err := wizardInput("Models (comma-separated):", preset.models, translator)
+	if err != nil {
+		return err
+	}
+
+	provider := &config.ProviderConfig{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		APIKey:  strings.TrimSpace(apiKey),
+		Enabled: true,
+	}
+	for _, model := range strings.Split(models, ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			provider.Models = append(provider.Models, &config.ModelConfig{Name: model, Enabled: true})
+		}
+	}
+	if len(provider.Models) == 0 {
+		return fmt.Errorf("at least one model is required")
+	}
+
+	if err := cfg.AddProvider(name, provider); err != nil {
+		return err
+	}
+	color.Green("✓ Provider %s saved\n", name)
+	return nil
+}
+
+func wizardSelectDefaultModel(cfg *config.Config, translator *i18n.I18n) error {
+	var models []string
+	for _, provider := range cfg.GetEnabledProviders() {
+		for _, model := range provider.Models {
+			if model.Enabled {
+				models = append(models, provider.Name+"/"+model.Name)
+			}
+		}
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	// Current default first so pressing Enter keeps it
+	current := cfg.GetDefaultModel()
+	for i, model := range models {
+		if model == current {
+			models[0], models[i] = models[i], models[0]
+			break
+		}
+	}
+
+	index, err := ui.PromptSelect("Select default model:", models, translator)
+	if err != nil {
+		return err
+	}
+	return cfg.SetDefaultModel(models[index])
+}
+
+// wizardInput prompts for a required value, prefilled with a default
+func wizardInput(prompt, defaultValue string, translator *i18n.I18n) (string, error) {
+	for {
+		value, err := ui.PromptInputWithValue(prompt, defaultValue, translator)
+		if err != nil {
+			return "", err
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			return value, nil
+		}
+		color.Yellow("A value is required\n")
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/kb"
+	"github.com/spf13/cobra"
+)
+
+// kbSnippetLines limits the lines of each section shown by kb search
+const kbSnippetLines = 6
+
+var flagKBTop int
+
+var kbCmd = &cobra.Command{
+	Use:   "kb",
+	Short: "Manage the runbook knowledge base",
+	Long: `Index markdown runbooks so that the sections relevant to each question are
+sent to the model with their sources, and answers follow documented procedures.
+
+The index is stored in ~/.aiassist/kb/index.json and searched locally with BM25.`,
+}
+
+var kbIndexCmd = &cobra.Command{
+	Use:   "index [dir...]",
+	Short: "Index the markdown runbooks in directories",
+	Long: `Index the markdown files (.md, .markdown) in the given directories, replacing
+the existing index. Without directories, the directories of the existing index
+are indexed again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := kb.Path()
+		dirs := args
+		if len(dirs) == 0 {
+			existing, err := kb.Load(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("no runbook index yet, give the directories to index")
+			}
+			if err != nil {
+				return err
+			}
+			dirs = existing.Dirs
+		}
+
+		idx, err := kb.Build(dirs)
+		if err != nil {
+			return err
+		}
+		if err := idx.Save(path); err != nil {
+			return fmt.Errorf("failed to save runbook index: %w", err)
+		}
+
+		color.Green("✓ Indexed %d sections from %d files in %s\n", len(idx.Chunks), idx.Files, strings.Join(idx.Dirs, ", "))
+		color.Cyan("Index file: %s\n", path)
+		if !config.Get().KBEnabled() {
+			color.Yellow("Warning: the knowledge base is disabled in the configuration (kb.disabled)\n")
+		}
+		return nil
+	},
+}
+
+var kbSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the runbook index",
+	Long:  `Show the runbook sections that would be sent to the model for a question.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idx, err := kb.Load(kb.Path())
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no runbook index yet, run 'aiassist kb index <dir>' first")
+		}
+		if err != nil {
+			return err
+		}
+
+		top := flagKBTop
+		if top <= 0 {
+			top = config.Get().GetKBTopK()
+		}
+		results := idx.Search(strings.Join(args, " "), top)
+		if len(results) == 0 {
+			color.Yellow("No matching runbook sections\n")
+			return nil
+		}
+
+		for _, r := range results {
+			color.Green("%s  %s (score %.2f)\n", r.Citation(), r.Heading, r.Score)
+			lines := strings.Split(r.Text, "\n")
+			if len(lines) > kbSnippetLines {
+				lines = append(lines[:kbSnippetLines], "...")
+			}
+			for _, line := range lines {
+				fmt.Println("  " + line)
+			}
+			fmt.Println()
+		}
+		color.Cyan("Indexed at: %s\n", idx.IndexedAt.Format(time.DateTime))
+		return nil
+	},
+}
+
+func init() {
+	kbSearchCmd.Flags().IntVarP(&flagKBTop, "top", "k", 0, "Number of sections to show (default: kb.top_k of the configuration, 3)")
+
+	kbCmd.AddCommand(kbIndexCmd)
+	kbCmd.AddCommand(kbSearchCmd)
+	rootCmd.AddCommand(kbCmd)
+}
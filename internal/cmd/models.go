@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/llm"
+	"github.com/llaoj/aiassist/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Inspect and diagnose configured models",
+}
+
+var (
+	modelsListRemote bool
+	modelsListAdd    bool
+	modelsListEnable bool
+)
+
+var modelsListCmd = &cobra.Command{
+	Use:   "list [provider]",
+	Short: "List configured models, or models offered by providers",
+	Long: `List configured models with their status.
+
+With --remote, query each provider's /models endpoint and show which of the offered
+models are configured and enabled locally. With --add, models discovered remotely are
+added to the local config (disabled unless --enable is given). --add is not available
+in Consul mode.`,
+	Args: cobra.MaximumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if modelsListAdd {
+			if !modelsListRemote {
+				return fmt.Errorf("--add requires --remote")
+			}
+			return requireLocalConfig(cmd, args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		providerName := ""
+		if len(args) > 0 {
+			providerName = args[0]
+		}
+		if modelsListRemote {
+			return listRemoteModels(providerName)
+		}
+		return listLocalModels(providerName)
+	},
+}
+
+var modelsTestTimeout time.Duration
+
+var modelsTestCmd = &cobra.Command{
+	Use:   "test [provider|provider/model]",
+	Short: "Test connectivity to configured models",
+	Long: `Send a minimal request to each enabled model (or the given provider/model) through
+the same proxy settings used by sessions, and report latency, HTTP status, whether the
+API key is accepted and whether tool calling and streaming are supported.
+
+Exits with a non-zero status if any tested model fails, so it can be used in
+provisioning health checks.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := ""
+		if len(args) > 0 {
+			filter = args[0]
+		}
+		return testModels(filter)
+	},
+}
+
+func init() {
+	modelsListCmd.Flags().BoolVar(&modelsListRemote, "remote", false, "Query providers' /models endpoints")
+	modelsListCmd.Flags().BoolVar(&modelsListAdd, "add", false, "Add discovered models to the local config")
+	modelsListCmd.Flags().BoolVar(&modelsListEnable, "enable", false, "Enable models added with --add")
+
+	modelsTestCmd.Flags().DurationVar(&modelsTestTimeout, "timeout", 60*time.Second, "Timeout for testing each model")
+
+	modelsCmd.AddCommand(modelsListCmd)
+	modelsCmd.AddCommand(modelsTestCmd)
+	rootCmd.AddCommand(modelsCmd)
+}
+
+// modelTarget is a configured model selected for a diagnostic command
+type modelTarget struct {
+	provider *config.ProviderConfig
+	model    *config.ModelConfig
+}
+
+func (t modelTarget) key() string {
+	return t.provider.Name + "/" + t.model.Name
+}
+
+// selectModels returns the models matching filter ("", "provider" or "provider/model").
+// Without a filter only enabled models of enabled providers are returned; a model named
+// explicitly is returned even when disabled.
+func selectModels(filter string) ([]modelTarget, error) {
+	cfg := config.Get()
+	providerFilter, modelFilter, _ := strings.Cut(filter, "/")
+
+	var targets []modelTarget
+	for _, provider := range cfg.GetAllProviders() {
+		if providerFilter != "" && provider.Name != providerFilter {
+			continue
+		}
+		for _, model := range provider.Models {
+			if modelFilter != "" {
+				if model.Name == modelFilter {
+					targets = append(targets, modelTarget{provider: provider, model: model})
+				}
+				continue
+			}
+			if provider.Enabled && model.Enabled {
+				targets = append(targets, modelTarget{provider: provider, model: model})
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		if filter != "" {
+			return nil, fmt.Errorf("no configured model matches %s", filter)
+		}
+		return nil, fmt.Errorf("no enabled models configured")
+	}
+	return targets, nil
+}
+
+// selectProviders returns all providers, or only the named one
+func selectProviders(providerName string) ([]*config.ProviderConfig, error) {
+	providers := config.Get().GetAllProviders()
+	if providerName == "" {
+		if len(providers) == 0 {
+			return nil, fmt.Errorf("no providers configured")
+		}
+		return providers, nil
+	}
+
+	for _, provider := range providers {
+		if provider.Name == providerName {
+			return []*config.ProviderConfig{provider}, nil
+		}
+	}
+	return nil, fmt.Errorf("provider %s not found", providerName)
+}
+
+func listLocalModels(providerName string) error {
+	providers, err := selectProviders(providerName)
+	if err != nil {
+		return err
+	}
+
+	defaultModel := config.Get().GetDefaultModel()
+	for _, provider := range providers {
+		fmt.Println()
+		if provider.Enabled {
+			fmt.Printf("%s [✓ Enabled]\n", provider.Name)
+		} else {
+			fmt.Printf("%s [✗ Disabled]\n", provider.Name)
+		}
+
+		for _, model := range provider.Models {
+			modelKey := provider.Name + "/" + model.Name
+			defaultMark := ""
+			if modelKey == defaultModel {
+				defaultMark = " [DEFAULT]"
+			}
+			if model.Enabled {
+				color.Green("  ✓ %s%s\n", modelKey, defaultMark)
+			} else {
+				fmt.Printf("  ✗ %s (disabled)%s\n", modelKey, defaultMark)
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func listRemoteModels(providerName string) error {
+	providers, err := selectProviders(providerName)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.Get()
+	failed := 0
+	for _, provider := range providers {
+		// Without an explicit provider, only query the ones actually in use
+		if providerName == "" && !provider.Enabled {
+			continue
+		}
+
+		fmt.Printf("\n%s (%s)\n", provider.Name, provider.BaseURL)
+
+		apiKey, err := provider.ResolveAPIKey()
+		if err != nil {
+			color.Red("  ✗ %v\n", err)
+			failed++
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		stopSpinner := ui.StartSpinner("Fetching models")
+		remoteModels, err := llm.ListRemoteModels(ctx, provider.BaseURL, apiKey, http.ProxyFromEnvironment)
+		stopSpinner()
+		cancel()
+		if err != nil {
+			color.Red("  ✗ %v\n", err)
+			failed++
+			continue
+		}
+
+		local := make(map[string]*config.ModelConfig, len(provider.Models))
+		for _, model := range provider.Models {
+			local[model.Name] = model
+		}
+
+		var discovered []string
+		offered := make(map[string]bool, len(remoteModels))
+		for _, name := range remoteModels {
+			offered[name] = true
+			model, configured := local[name]
+			switch {
+			case !configured:
+				fmt.Printf("  + %s (not configured)\n", name)
+				discovered = append(discovered, name)
+			case model.Enabled:
+				color.Green("  ✓ %s (enabled)\n", name)
+			default:
+				fmt.Printf("  ✗ %s (configured, disabled)\n", name)
+			}
+		}
+
+		for _, model := range provider.Models {
+			if !offered[model.Name] {
+				color.Yellow("  ⚠ %s (configured but not offered by provider)\n", model.Name)
+			}
+		}
+
+		fmt.Printf("  %d offered, %d not configured\n", len(remoteModels), len(discovered))
+
+		if modelsListAdd && len(discovered) > 0 {
+			added, err := cfg.AddModels(provider.Name, discovered, modelsListEnable)
+			if err != nil {
+				return err
+			}
+			state := "disabled"
+			if modelsListEnable {
+				state = "enabled"
+			}
+			color.Green("  ✓ Added %d models to %s (%s)\n", added, provider.Name, state)
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("failed to list models for %d providers", failed)
+	}
+	return nil
+}
+
+func testModels(filter string) error {
+	targets, err := selectModels(filter)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	resolvedKeys := make(map[string]string)
+	for _, target := range targets {
+		fmt.Printf("\n%s\n", target.key())
+
+		apiKey, ok := resolvedKeys[target.provider.Name]
+		if !ok {
+			apiKey, err = target.provider.ResolveAPIKey()
+			if err != nil {
+				color.Red("  ✗ %v\n", err)
+				failed++
+				continue
+			}
+			resolvedKeys[target.provider.Name] = apiKey
+		}
+
+		llmModel := newProviderModel(target.provider, apiKey, target.model.Name)
+
+		ctx, cancel := context.WithTimeout(context.Background(), modelsTestTimeout)
+		stopSpinner := ui.StartSpinner("Testing")
+		result := llmModel.Probe(ctx)
+		stopSpinner()
+		cancel()
+
+		latency := result.Latency.Round(time.Millisecond)
+		if result.OK() {
+			color.Green("  Status:       ✓ OK (HTTP %d, %v)\n", result.StatusCode, latency)
+		} else if result.StatusCode != 0 {
+			color.Red("  Status:       ✗ %v (%v)\n", result.Err, latency)
+		} else {
+			color.Red("  Status:       ✗ %v\n", result.Err)
+		}
+
+		switch {
+		case result.StatusCode == 0:
+			color.Yellow("  Auth:         ? unknown (no response)\n")
+		case result.AuthValid:
+			color.Green("  Auth:         ✓ valid\n")
+		default:
+			color.Red("  Auth:         ✗ rejected (HTTP %d)\n", result.StatusCode)
+		}
+
+		if !result.OK() {
+			failed++
+			continue
+		}
+		printCapability("Tool calling", result.ToolCalling.Supported, result.ToolCalling.Detail)
+		printCapability("Streaming", result.Streaming.Supported, result.Streaming.Detail)
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d models failed", failed, len(targets))
+	}
+	color.Green("✓ All %d models passed\n", len(targets))
+	return nil
+}
+
+func printCapability(name string, supported bool, detail string) {
+	label := fmt.Sprintf("  %-14s", name+":")
+	if supported {
+		color.Green("%s✓ supported\n", label)
+		return
+	}
+	color.Yellow("%s✗ not supported (%s)\n", label, detail)
+}
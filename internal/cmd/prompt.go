@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/prompt"
+	"github.com/llaoj/aiassist/internal/sysinfo"
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Manage system prompts",
+	Long: `Preview the system prompts sent to the model.
+
+Prompts can be customized with Go text/template files in ~/.aiassist/prompts/:
+<name>.tmpl replaces the built-in prompt (available as {{.Default}}), and
+common.tmpl is appended to every prompt. Templates can also be set in the
+prompts section of the configuration, e.g. shared through Consul.
+
+Template variables: .Name, .Default, .Language, .Sysinfo (nil when disabled)
+and .Blacklist. Functions: join, contains.`,
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:       "show <" + strings.Join(prompt.Names, "|") + ">",
+	Short:     "Show a rendered system prompt",
+	Long:      `Render a system prompt with the current templates, system information and blacklist.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: prompt.Names,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if config.Get().SysinfoEnabled() {
+			info, err := sysinfo.LoadOrCollect()
+			if err != nil {
+				color.Yellow("Warning: failed to load system info: %v\n", err)
+			}
+			prompt.SetSystemInfo(info)
+		}
+
+		text, sources, err := prompt.Render(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(text)
+		fmt.Println()
+		if len(sources) == 0 {
+			color.Cyan("Templates: none (built-in prompt)\n")
+		} else {
+			color.Cyan("Templates: %s\n", strings.Join(sources, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(promptCmd)
+}
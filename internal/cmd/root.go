@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/interactive"
+	"github.com/llaoj/aiassist/internal/prompt"
+	"github.com/llaoj/aiassist/internal/sysinfo"
 	"github.com/spf13/cobra"
 )
 
@@ -21,31 +31,240 @@ Examples:
   aiassist                      # Interactive mode
   aiassist "your question"       # Ask question and exit
   cmd | aiassist                # Analyze piped data
-  cmd | aiassist "question"      # Analyze piped data with context`,
+  cmd | aiassist "question"      # Analyze piped data with context
+  tail -f app.log | aiassist --follow   # Keep analyzing a live stream
+  cat big.log | aiassist --preprocess on   # Summarize logs locally first
+  journalctl -u nginx | aiassist -i     # Analyze, then run suggested commands
+  dmesg | aiassist -o json              # Structured result for scripts and CI
+  aiassist --auto-approve=query "why is disk full"   # Run query commands unattended (cron/CI)
+  aiassist --model openai/gpt-4o "question"   # Pick a model for this run
+  aiassist --host admin@web1 "why is nginx slow"   # Run commands on another host over SSH
+  aiassist --group web "why are 502s rising"       # Compare query outputs across an inventory group`,
 	FParseErrWhitelist: cobra.FParseErrWhitelist{
 		UnknownFlags: true,
 	},
 	DisableFlagParsing: false,
-	Args:               cobra.ArbitraryArgs,
+	// Errors are printed once by main, without usage noise
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          cobra.ArbitraryArgs,
+	PreRunE:       validateRunFlags,
 	Run: func(cmd *cobra.Command, args []string) {
 		var initialQuestion string
 		if len(args) > 0 {
 			initialQuestion = args[0]
 		}
 
-		fileInfo, _ := os.Stdin.Stat()
-		if (fileInfo.Mode() & os.ModeCharDevice) == 0 {
+		piped := stdinIsPipe()
+		if flagOutput == outputJSON {
+			runReportMode(initialQuestion, piped)
+			return
+		}
+		if flagAutoApprove != string(interactive.ApproveNone) && !piped {
+			runAutonomousMode(initialQuestion)
+			return
+		}
+
+		if piped {
+			if flagFollow {
+				runFollowMode(initialQuestion)
+				return
+			}
 			runPipeMode(initialQuestion)
 		} else {
 			runInteractiveMode(initialQuestion)
 		}
 	},
+	PostRun: func(cmd *cobra.Command, args []string) {
+		sysinfo.WaitForRefresh(refreshWaitTimeout)
+	},
 }
 
+// Global flags, each overriding the matching AIASSIST_* environment variable
+var (
+	flagConfigFile  string
+	flagModel       string
+	flagLanguage    string
+	flagProvider    string
+	flagNoSysinfo   bool
+	flagMaxDepth    int
+	flagPTY         bool
+	flagVerifyFlags bool
+)
+
+// Pipe mode flags
+var (
+	flagFollow         bool
+	flagWindowLines    int
+	flagWindowInterval time.Duration
+	flagPreprocess     string
+	flagInteractive    bool
+	flagOutput         string
+	flagFailOn         string
+	flagAutoApprove    string
+	flagAllow          []string
+	flagMaxSteps       int
+	flagHosts          []string
+	flagInventory      string
+	flagGroup          string
+	flagConsulService  string
+	flagParallel       int
+	flagShowRedactions bool
+)
+
 func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&flagConfigFile, "config", "", "Config file (env: AIASSIST_CONFIG, default ~/.aiassist/config.yaml)")
+	flags.StringVar(&flagModel, "model", "", "Model to use as provider/model (env: AIASSIST_MODEL)")
+	flags.StringVar(&flagLanguage, "lang", "", "Language: en or zh (env: AIASSIST_LANG)")
+	flags.StringVar(&flagProvider, "provider", "", "Only use models of this provider (env: AIASSIST_PROVIDER)")
+	flags.BoolVar(&flagNoSysinfo, "no-sysinfo", false, "Don't send system information to the model (env: AIASSIST_NO_SYSINFO)")
+	flags.IntVar(&flagMaxDepth, "max-depth", 0, "Maximum command analysis depth (env: AIASSIST_MAX_DEPTH, default 10)")
+	flags.BoolVar(&flagPTY, "pty", false, "Run commands in a pseudo-terminal, for commands whose output differs without one (env: AIASSIST_PTY)")
+	flags.BoolVar(&flagVerifyFlags, "verify-flags", false, "Check the flags of suggested commands against --help and man pages, and have wrong ones corrected (env: AIASSIST_VERIFY_FLAGS)")
+
+	rootCmd.Flags().BoolVarP(&flagFollow, "follow", "f", false, "Pipe mode: keep reading input (e.g. tail -f) and analyze it in rolling windows")
+	rootCmd.Flags().IntVar(&flagWindowLines, "window-lines", interactive.DefaultFollowWindowLines, "Follow mode: analyze after this many lines")
+	rootCmd.Flags().DurationVar(&flagWindowInterval, "window-interval", interactive.DefaultFollowWindowInterval, "Follow mode: analyze at least this often when new lines arrived")
+	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", outputText, "Output format: text or json (json answers once without executing commands)")
+	rootCmd.Flags().StringVar(&flagFailOn, "fail-on", "critical", "JSON output: exit with status 2 when the reported severity is at least this level (low, medium, high, critical)")
+	rootCmd.Flags().StringVar(&flagAutoApprove, "auto-approve", string(interactive.ApproveNone), "Run commands without prompting: none, query (query commands only) or all (also modify commands matching --allow)")
+	rootCmd.Flags().StringArrayVar(&flagAllow, "allow", nil, "Allowlist pattern for modify commands with --auto-approve=all, e.g. \"systemctl restart nginx\" (repeatable)")
+	rootCmd.Flags().IntVar(&flagMaxSteps, "max-steps", interactive.DefaultMaxSteps, "Maximum number of commands executed with --auto-approve")
+	rootCmd.Flags().StringSliceVar(&flagHosts, "host", nil, "Run commands on this host over SSH: [user@]host[:port], an ~/.ssh/config alias or an inventory host name (repeatable: several hosts form a fleet)")
+	rootCmd.Flags().StringVar(&flagInventory, "inventory", "", "Inventory file with host names and groups (default ~/.aiassist/inventory.yaml)")
+	rootCmd.Flags().StringVar(&flagGroup, "group", "", "Run query commands on all hosts of this inventory group in parallel")
+	rootCmd.Flags().StringVar(&flagConsulService, "consul-service", "", "Run query commands on all nodes of this Consul catalog service in parallel")
+	rootCmd.Flags().IntVar(&flagParallel, "parallel", executor.DefaultFleetParallel, "Maximum number of fleet hosts a command runs on at a time")
+	rootCmd.Flags().BoolVar(&flagShowRedactions, "show-redactions", false, "Print the sensitive values replaced by placeholders before each request to the model (to stderr)")
+	rootCmd.Flags().BoolVarP(&flagInteractive, "interactive", "i", false, "Pipe mode: continue into an interactive session to run suggested commands")
+	rootCmd.Flags().StringVar(&flagPreprocess, "preprocess", string(interactive.PreprocessAuto), "Pipe mode: summarize logs locally before analysis: auto (when too large), on or off")
+
+	rootCmd.PersistentPreRunE = applyGlobalFlags
 	rootCmd.AddCommand(versionCmd)
 }
 
+// Output formats
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// validateRunFlags checks the flags of the root command before a session starts
+func validateRunFlags(cmd *cobra.Command, args []string) error {
+	if flagFollow && flagInteractive {
+		return fmt.Errorf("--interactive cannot be combined with --follow")
+	}
+	if _, err := interactive.ParsePreprocessMode(flagPreprocess); err != nil {
+		return err
+	}
+
+	policy, err := interactive.ParseApprovalPolicy(flagAutoApprove)
+	if err != nil {
+		return err
+	}
+	if len(flagAllow) > 0 && policy != interactive.ApproveAll {
+		return fmt.Errorf("--allow requires --auto-approve=all")
+	}
+	if flagMaxSteps <= 0 {
+		return fmt.Errorf("--max-steps must be positive")
+	}
+	if flagParallel <= 0 {
+		return fmt.Errorf("--parallel must be positive")
+	}
+	if policy != interactive.ApproveNone {
+		if flagFollow || flagInteractive || flagOutput == outputJSON {
+			return fmt.Errorf("--auto-approve cannot be combined with --follow, --interactive or --output json")
+		}
+		if len(args) == 0 && !stdinIsPipe() {
+			return fmt.Errorf("--auto-approve requires a question or piped input")
+		}
+	}
+
+	switch flagOutput {
+	case outputText:
+	case outputJSON:
+		if flagFollow || flagInteractive {
+			return fmt.Errorf("--output json cannot be combined with --follow or --interactive")
+		}
+		if len(args) == 0 && !stdinIsPipe() {
+			return fmt.Errorf("--output json requires a question or piped input")
+		}
+		if !slices.Contains(prompt.SeverityLevels[1:], flagFailOn) {
+			return fmt.Errorf("invalid --fail-on level %q (supported: %s)", flagFailOn, strings.Join(prompt.SeverityLevels[1:], ", "))
+		}
+	default:
+		return fmt.Errorf("invalid output format %q (supported: %s, %s)", flagOutput, outputText, outputJSON)
+	}
+	return nil
+}
+
+// stdinIsPipe reports whether stdin is piped or redirected rather than a terminal
+func stdinIsPipe() bool {
+	fileInfo, err := os.Stdin.Stat()
+	return err == nil && (fileInfo.Mode()&os.ModeCharDevice) == 0
+}
+
+// applyGlobalFlags applies global flags on top of the file, Consul and environment values.
+// Precedence: flag > environment variable > Consul > config file > default.
+func applyGlobalFlags(cmd *cobra.Command, args []string) error {
+	if flagConfigFile != "" {
+		if err := config.InitWithFile(flagConfigFile); err != nil {
+			return fmt.Errorf("failed to initialize config: %w", err)
+		}
+	}
+
+	if flagMaxDepth < 0 {
+		return fmt.Errorf("--max-depth must not be negative")
+	}
+
+	overrides := config.Overrides{
+		Model:    flagModel,
+		Language: flagLanguage,
+		Provider: flagProvider,
+		MaxDepth: flagMaxDepth,
+	}
+	// Boolean flags given on the command line win in either direction, e.g.
+	// --pty=false over AIASSIST_PTY=true
+	flags := cmd.Flags()
+	if flags.Changed("no-sysinfo") {
+		overrides.NoSysinfo = &flagNoSysinfo
+	}
+	if flags.Changed("pty") {
+		overrides.PTY = &flagPTY
+	}
+	if flags.Changed("verify-flags") {
+		overrides.VerifyFlags = &flagVerifyFlags
+	}
+	if err := config.Get().ApplyOverrides(overrides); err != nil {
+		if !fixesConfig(cmd) {
+			return err
+		}
+		// A model or provider that no longer exists must not block the commands fixing it
+		color.New(color.FgYellow).Fprintf(os.Stderr, "Warning: %v, --model, --provider, %s and %s are ignored\n", err, config.EnvModel, config.EnvProvider)
+		config.Get().ClearModelOverrides()
+		overrides.Model, overrides.Provider = "", ""
+		if err := config.Get().ApplyOverrides(overrides); err != nil {
+			return err
+		}
+	}
+
+	// The exit message is in the language chosen with --lang or --config
+	SetupInterruptHandler(config.Get().GetLanguage())
+	return nil
+}
+
+// fixesConfig reports whether cmd is one of the config or models commands used to
+// inspect and fix the configuration
+func fixesConfig(cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd == configCmd || cmd == modelsCmd {
+			return true
+		}
+	}
+	return false
+}
+
 func SetVersionInfo(version, commit, buildDate string) {
 	appVersion = version
 	appCommit = commit
@@ -1,17 +1,34 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
 	"github.com/llaoj/aiassist/internal/i18n"
 	"github.com/llaoj/aiassist/internal/interactive"
 	"github.com/llaoj/aiassist/internal/llm"
+	"github.com/llaoj/aiassist/internal/remote"
+	"github.com/llaoj/aiassist/internal/sysinfo"
 )
 
+// refreshWaitTimeout bounds how long exiting waits for a background refresh of
+// the system information cache
+const refreshWaitTimeout = 2 * time.Second
+
+// exit exits with code once a background refresh of the system information cache
+// finished, so that no partially written cache is left behind
+func exit(code int) {
+	sysinfo.WaitForRefresh(refreshWaitTimeout)
+	os.Exit(code)
+}
+
 func initializeSession() (*interactive.Session, *i18n.I18n) {
 	cfg := config.Get()
 	translator := i18n.New(cfg.GetLanguage())
@@ -20,7 +37,7 @@ func initializeSession() (*interactive.Session, *i18n.I18n) {
 	if !cfg.ConfigExists() {
 		color.Red(translator.T("config.not_found") + "\n")
 		fmt.Println(translator.T("config.hint_run_setup"))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Check if any providers are configured
@@ -28,38 +45,31 @@ func initializeSession() (*interactive.Session, *i18n.I18n) {
 	if len(enabledProviders) == 0 {
 		color.Red(translator.T("error.no_models") + "\n")
 		color.Red(translator.T("error.hint_no_models") + "\n")
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Initialize LLM manager
 	manager := llm.NewManager(cfg)
 
 	// Register configured providers as OpenAI-compatible providers
-	// Use http.ProxyFromEnvironment for automatic proxy selection from environment variables
 	for _, provider := range enabledProviders {
+		// Resolve api_key references (env:, file:, exec:, vault:) only when the provider is used
+		apiKey, err := provider.ResolveAPIKey()
+		if err != nil {
+			color.Yellow("Warning: Skipping provider %s: %v\n", provider.Name, err)
+			continue
+		}
+
+		// Of a disabled provider that is only listed for the model selected with --model,
+		// only that model is used
+		providerUsable := provider.Enabled || cfg.GetOverrides().Provider == provider.Name
 		for _, modelCfg := range provider.Models {
-			// Skip disabled models
-			if !modelCfg.Enabled {
+			// Skip disabled models, unless explicitly selected with --model
+			if !(modelCfg.Enabled && providerUsable) && !cfg.IsModelSelected(provider.Name+"/"+modelCfg.Name) {
 				continue
 			}
 
-			modelKey := fmt.Sprintf("%s/%s", provider.Name, modelCfg.Name)
-			llmModel := llm.NewOpenAICompatibleModel(
-				modelKey,
-				provider.BaseURL,
-				provider.APIKey,
-				modelCfg.Name,
-			)
-
-			// Configure HTTP proxy from environment variables
-			// Uses http.ProxyFromEnvironment which automatically selects:
-			// - HTTPS_PROXY for HTTPS URLs
-			// - HTTP_PROXY for HTTP URLs
-			if err := llmModel.SetProxyFunc(http.ProxyFromEnvironment); err != nil {
-				color.Yellow("Warning: Failed to configure proxy for %s: %v\n", modelKey, err)
-			}
-
-			manager.RegisterModel(llmModel)
+			manager.RegisterModel(newProviderModel(provider, apiKey, modelCfg.Name))
 		}
 	}
 
@@ -67,10 +77,144 @@ func initializeSession() (*interactive.Session, *i18n.I18n) {
 	if len(manager.GetStatus()) == 0 {
 		color.Red(translator.T("error.no_models") + "\n")
 		color.Red(translator.T("error.hint_no_models") + "\n")
-		os.Exit(1)
+		exit(1)
+	}
+
+	session := interactive.NewSession(manager, translator)
+
+	// Validated in PreRunE
+	policy, _ := interactive.ParseApprovalPolicy(flagAutoApprove)
+	session.SetAutoApprove(interactive.AutoApproveOptions{
+		Policy:   policy,
+		Allow:    flagAllow,
+		MaxSteps: flagMaxSteps,
+	})
+	session.SetShowRedactions(flagShowRedactions)
+
+	if len(flagHosts) > 0 || flagGroup != "" || flagConsulService != "" {
+		connectRemote(session)
+	}
+
+	return session, translator
+}
+
+// connectRemote makes the session run commands on the hosts given with --host,
+// --group or --consul-service over SSH. A single --host runs any command, several
+// hosts form a fleet running query commands in parallel.
+func connectRemote(session *interactive.Session) {
+	inv, err := loadInventory()
+	if err != nil {
+		color.Red("Error: %v\n", err)
+		exit(1)
+	}
+	targets, err := remoteTargets(inv)
+	if err != nil {
+		color.Red("Error: %v\n", err)
+		exit(1)
+	}
+
+	if len(targets) == 1 && flagGroup == "" && flagConsulService == "" {
+		client, err := remote.Dial(targets[0], remote.DefaultOptions())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			exit(1)
+		}
+		if err := session.SetRemote(client); err != nil {
+			client.Close()
+			color.Red("Error: %v\n", err)
+			exit(1)
+		}
+		color.Green("✓ Connected to %s\n", client.Target())
+		return
+	}
+
+	clients, errs := remote.DialAll(targets, remote.DefaultOptions(), flagParallel)
+	var runners []executor.Runner
+	for i, client := range clients {
+		if errs[i] != nil {
+			color.Yellow("Warning: %v\n", errs[i])
+			continue
+		}
+		runners = append(runners, client)
+	}
+	if err := session.SetFleet(runners, flagParallel); err != nil {
+		color.Red("Error: %v\n", err)
+		exit(1)
+	}
+	color.Green("✓ Connected to %d of %d hosts\n", len(runners), len(targets))
+}
+
+// remoteTargets returns the SSH targets of --host, --group and --consul-service,
+// without duplicates
+func remoteTargets(inv *remote.Inventory) ([]string, error) {
+	var hosts []remote.Host
+	if flagGroup != "" {
+		if inv == nil {
+			return nil, fmt.Errorf("--group requires an inventory (--inventory or ~/.aiassist/inventory.yaml)")
+		}
+		if hosts = inv.Group(flagGroup); len(hosts) == 0 {
+			return nil, fmt.Errorf("inventory group %s has no hosts", flagGroup)
+		}
+	}
+	if flagConsulService != "" {
+		var address, token string
+		if consul := config.Get().Consul; consul != nil {
+			address, token = consul.Address, consul.Token
+		}
+		members, err := remote.ConsulServiceHosts(address, token, flagConsulService)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, members...)
 	}
 
-	return interactive.NewSession(manager, translator), translator
+	var targets []string
+	for _, host := range flagHosts {
+		targets = append(targets, remote.ResolveTarget(host, inv))
+	}
+	for _, host := range hosts {
+		targets = append(targets, host.Target())
+	}
+	slices.Sort(targets)
+	return slices.Compact(targets), nil
+}
+
+// loadInventory loads --inventory, or the default inventory if it exists
+func loadInventory() (*remote.Inventory, error) {
+	path := flagInventory
+	if path == "" {
+		defaultPath, err := remote.DefaultInventoryPath()
+		if err != nil {
+			return nil, nil
+		}
+		if _, err := os.Stat(defaultPath); err != nil {
+			return nil, nil
+		}
+		path = defaultPath
+	}
+	return remote.LoadInventory(path)
+}
+
+// newProviderModel creates an OpenAI-compatible model for a configured provider.
+// Sessions and diagnostic commands share it so they use the same proxy settings.
+func newProviderModel(provider *config.ProviderConfig, apiKey, modelName string) *llm.OpenAICompatibleModel {
+	modelKey := fmt.Sprintf("%s/%s", provider.Name, modelName)
+	llmModel := llm.NewOpenAICompatibleModel(
+		modelKey,
+		provider.BaseURL,
+		apiKey,
+		modelName,
+	)
+
+	// Configure HTTP proxy from environment variables
+	// Uses http.ProxyFromEnvironment which automatically selects:
+	// - HTTPS_PROXY for HTTPS URLs
+	// - HTTP_PROXY for HTTP URLs
+	if err := llmModel.SetProxyFunc(http.ProxyFromEnvironment); err != nil {
+		color.Yellow("Warning: Failed to configure proxy for %s: %v\n", modelKey, err)
+	}
+
+	return llmModel
 }
 
 func runInteractiveMode(initialQuestion string) {
@@ -80,17 +224,83 @@ func runInteractiveMode(initialQuestion string) {
 	if err != nil {
 		fmt.Println()
 		color.Red(translator.T("error.general", err) + "\n")
-		os.Exit(1)
+		exit(1)
 	}
 }
 
 func runPipeMode(initialQuestion string) {
 	session, _ := initializeSession()
 
-	err := session.RunWithPipe(initialQuestion)
+	// Validated in PreRunE
+	preprocess, _ := interactive.ParsePreprocessMode(flagPreprocess)
+	err := session.RunWithPipe(initialQuestion, interactive.PipeOptions{
+		Preprocess:  preprocess,
+		Interactive: flagInteractive,
+	})
+	if err != nil {
+		fmt.Println()
+		color.Red("Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// runAutonomousMode answers a question without prompting, running commands
+// according to --auto-approve, and prints a final report
+func runAutonomousMode(initialQuestion string) {
+	session, _ := initializeSession()
+
+	if err := session.RunAutonomous(initialQuestion); err != nil {
+		fmt.Println()
+		color.Red("Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// runReportMode answers once and prints a JSON report to stdout. Diagnostics go to stderr.
+// Exits with status 1 if no analysis could be produced, and with status 2 if the
+// reported severity reaches --fail-on.
+func runReportMode(initialQuestion string, piped bool) {
+	color.NoColor = true
+	color.Output = os.Stderr
+
+	session, _ := initializeSession()
+
+	var report *interactive.Report
+	if piped {
+		// Validated in PreRunE
+		preprocess, _ := interactive.ParsePreprocessMode(flagPreprocess)
+		report = session.ReportWithPipe(initialQuestion, interactive.PipeOptions{
+			Preprocess: preprocess,
+		})
+	} else {
+		report = session.ReportQuestion(initialQuestion)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	switch {
+	case report.Failed():
+		exit(1)
+	case report.SeverityAtLeast(flagFailOn):
+		exit(2)
+	}
+}
+
+func runFollowMode(initialQuestion string) {
+	session, _ := initializeSession()
+
+	err := session.RunWithPipeFollow(initialQuestion, interactive.FollowOptions{
+		WindowLines:    flagWindowLines,
+		WindowInterval: flagWindowInterval,
+	})
 	if err != nil {
 		fmt.Println()
 		color.Red("Error: %v\n", err)
-		os.Exit(1)
+		exit(1)
 	}
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/llaoj/aiassist/internal/sysinfo"
@@ -34,6 +35,9 @@ var sysinfoViewCmd = &cobra.Command{
 
 		path, _ := sysinfo.GetSysInfoPath()
 		color.Cyan("Cache file: %s\n", path)
+		if !info.CollectedAt.IsZero() {
+			color.Cyan("Collected at: %s\n", info.CollectedAt.Format(time.DateTime))
+		}
 
 		return nil
 	},
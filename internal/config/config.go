@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -39,6 +40,45 @@ type ConsulConfig struct {
 	Token   string `yaml:"token,omitempty"` // ACL token (optional)
 }
 
+// SysinfoConfig controls the system information sent to the model
+type SysinfoConfig struct {
+	Disabled   bool                        `yaml:"disabled,omitempty"`   // Don't send system information
+	TTL        time.Duration               `yaml:"ttl,omitempty"`        // Age after which the cache is refreshed in the background, e.g. "12h"
+	Collectors map[string]*CollectorConfig `yaml:"collectors,omitempty"` // Settings per collector, by collector name
+}
+
+// CollectorConfig controls a single system information collector
+type CollectorConfig struct {
+	Disabled bool          `yaml:"disabled,omitempty"` // Skip the collector
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // Time budget, e.g. "2s"; 0 uses the collector's default
+}
+
+// RedactionConfig controls the masking of sensitive data before it is sent to the model
+type RedactionConfig struct {
+	Disabled bool                `yaml:"disabled,omitempty"` // Send data as is
+	Exclude  []string            `yaml:"exclude,omitempty"`  // Built-in detectors not to apply, e.g. ipv4
+	Patterns []*RedactionPattern `yaml:"patterns,omitempty"` // Custom detectors
+}
+
+// RedactionPattern is a custom detector of sensitive values
+type RedactionPattern struct {
+	Name  string `yaml:"name"`  // Kind of value, used in placeholders
+	Regex string `yaml:"regex"` // Matches the value; only the first group is redacted if there are groups
+}
+
+// KBConfig controls the runbook excerpts sent to the model, see aiassist kb index
+type KBConfig struct {
+	Disabled bool `yaml:"disabled,omitempty"` // Don't search the runbooks
+	TopK     int  `yaml:"top_k,omitempty"`    // Number of runbook sections sent with each question
+}
+
+// ExecutionConfig controls how suggested commands are executed
+type ExecutionConfig struct {
+	PTY           bool `yaml:"pty,omitempty"`            // Run commands in a pseudo-terminal
+	TerminalWidth int  `yaml:"terminal_width,omitempty"` // Terminal columns in PTY mode
+	VerifyFlags   bool `yaml:"verify_flags,omitempty"`   // Check flags of suggested commands against --help and man pages
+}
+
 // Config represents global configuration
 type Config struct {
 	Language     string            `yaml:"language"`
@@ -46,15 +86,28 @@ type Config struct {
 	Consul       *ConsulConfig     `yaml:"consul,omitempty"` // Consul config center settings
 	Providers    []*ProviderConfig `yaml:"providers"`
 	Blacklist    []string          `yaml:"blacklist,omitempty"` // Command blacklist with glob pattern support
+	MaxDepth     int               `yaml:"max_depth,omitempty"` // Maximum recursion depth for command analysis
+	Sysinfo      *SysinfoConfig    `yaml:"sysinfo,omitempty"`   // System information settings
+	Execution    *ExecutionConfig  `yaml:"execution,omitempty"` // Command execution settings
+	Redaction    *RedactionConfig  `yaml:"redaction,omitempty"` // Sensitive data redaction settings
+	Prompts      map[string]string `yaml:"prompts,omitempty"`   // Prompt templates by prompt name, e.g. shared through Consul
+	KB           *KBConfig         `yaml:"kb,omitempty"`        // Runbook knowledge base settings
 
 	ConfigDir  string       `yaml:"-"`
 	ConfigFile string       `yaml:"-"`
+	overrides  Overrides    // Runtime overrides from flags and environment, never saved
 	mu         sync.RWMutex `yaml:"-"`
 }
 
 var globalConfig *Config
 
 func Init() error {
+	return InitWithFile("")
+}
+
+// InitWithFile initializes the global configuration from configFile.
+// An empty configFile means $AIASSIST_CONFIG, or ~/.aiassist/config.yaml if that isn't set.
+func InitWithFile(configFile string) error {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return err
@@ -67,7 +120,17 @@ func Init() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configFile := filepath.Join(configDir, "config.yaml")
+	if configFile == "" {
+		configFile = os.Getenv(EnvConfig)
+	}
+	if configFile == "" {
+		configFile = filepath.Join(configDir, "config.yaml")
+	}
+
+	overrides, err := overridesFromEnv()
+	if err != nil {
+		return err
+	}
 
 	// Initialize config structure
 	globalConfig = &Config{
@@ -76,6 +139,7 @@ func Init() error {
 		Providers:    make([]*ProviderConfig, 0),
 		ConfigDir:    configDir,
 		ConfigFile:   configFile,
+		overrides:    overrides,
 	}
 
 	// Load local config file if it exists
@@ -89,10 +153,7 @@ func Init() error {
 			// Try to load from Consul
 			cfg, err := LoadFromConsul(globalConfig.Consul)
 			if err == nil {
-				// Successfully loaded from Consul, use those providers
-				globalConfig.Language = cfg.Language
-				globalConfig.DefaultModel = cfg.DefaultModel
-				globalConfig.Providers = cfg.Providers
+				globalConfig.useConsul(cfg)
 				return nil
 			}
 			// Consul load failed, continue using local providers
@@ -106,6 +167,32 @@ func Init() error {
 	return nil
 }
 
+// useConsul replaces the settings shared through the Consul config center with
+// those of remote
+func (c *Config) useConsul(remote *Config) {
+	c.Language = remote.Language
+	c.DefaultModel = remote.DefaultModel
+	c.Providers = remote.Providers
+	if remote.MaxDepth > 0 {
+		c.MaxDepth = remote.MaxDepth
+	}
+	if remote.Prompts != nil {
+		c.Prompts = remote.Prompts
+	}
+	if remote.Redaction != nil {
+		c.Redaction = remote.Redaction
+	}
+	if remote.Sysinfo != nil {
+		c.Sysinfo = remote.Sysinfo
+	}
+	if remote.KB != nil {
+		c.KB = remote.KB
+	}
+	if remote.Execution != nil {
+		c.Execution = remote.Execution
+	}
+}
+
 // Get returns the global configuration instance
 // Note: Init() must be called before Get() (called in main.init())
 func Get() *Config {
@@ -136,7 +223,7 @@ func (c *Config) Save() error {
 }
 
 // save saves configuration to file (internal, caller must hold lock)
-// Note: Consul mode check is handled at cmd layer via PersistentPreRunE
+// Note: Consul mode check is handled at cmd layer (requireLocalConfig)
 func (c *Config) save() error {
 	return c.saveToFile()
 }
@@ -155,6 +242,10 @@ func (c *Config) saveToFile() error {
 }
 
 func (c *Config) AddProvider(providerName string, provider *ProviderConfig) error {
+	if err := ValidateProviderName(providerName); err != nil {
+		return err
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -190,9 +281,19 @@ func (c *Config) GetEnabledProviders() []*ProviderConfig {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	// The provider of a model selected with --model is used even if disabled
+	selected, _, _ := strings.Cut(c.overrides.Model, "/")
+
 	enabled := make([]*ProviderConfig, 0)
 	for _, provider := range c.Providers {
-		if provider.Enabled {
+		// A provider selected with --provider is used even if disabled, and exclusively
+		if c.overrides.Provider != "" {
+			if provider.Name == c.overrides.Provider {
+				enabled = append(enabled, provider)
+			}
+			continue
+		}
+		if provider.Enabled || (selected != "" && provider.Name == selected) {
 			enabled = append(enabled, provider)
 		}
 	}
@@ -203,6 +304,10 @@ func (c *Config) GetEnabledProviders() []*ProviderConfig {
 func (c *Config) GetDefaultModel() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+
+	if c.overrides.Model != "" {
+		return c.overrides.Model
+	}
 	return c.DefaultModel
 }
 
@@ -265,6 +370,9 @@ func (c *Config) GetLanguage() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	if c.overrides.Language != "" {
+		return c.overrides.Language
+	}
 	return c.Language
 }
 
@@ -295,3 +403,115 @@ func (c *Config) GetBlacklist() []string {
 	copy(blacklist, c.Blacklist)
 	return blacklist
 }
+
+// IsConsulMode reports whether configuration is managed by the Consul config center.
+// In Consul mode the local file only holds connection info and must not be written.
+func (c *Config) IsConsulMode() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.Consul != nil && c.Consul.Enabled
+}
+
+// SetDefaultModel sets the default model, which must be a configured provider/model key
+func (c *Config) SetDefaultModel(modelKey string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, _, err := c.findModel(modelKey); err != nil {
+		return err
+	}
+
+	c.DefaultModel = modelKey
+	return c.save()
+}
+
+// SetProviderEnabled enables or disables a provider
+func (c *Config) SetProviderEnabled(providerName string, enabled bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	provider := c.findProvider(providerName)
+	if provider == nil {
+		return fmt.Errorf("provider %s not found", providerName)
+	}
+
+	provider.Enabled = enabled
+	return c.save()
+}
+
+// SetModelEnabled enables or disables a model identified by its provider/model key
+func (c *Config) SetModelEnabled(modelKey string, enabled bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, model, err := c.findModel(modelKey)
+	if err != nil {
+		return err
+	}
+
+	model.Enabled = enabled
+	return c.save()
+}
+
+// findProvider returns the provider with the given name (caller must hold lock)
+func (c *Config) findProvider(name string) *ProviderConfig {
+	for _, provider := range c.Providers {
+		if provider.Name == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// findModel resolves a provider/model key (caller must hold lock)
+func (c *Config) findModel(modelKey string) (*ProviderConfig, *ModelConfig, error) {
+	providerName, modelName, ok := strings.Cut(modelKey, "/")
+	if !ok || providerName == "" || modelName == "" {
+		return nil, nil, fmt.Errorf("invalid model %q, expected provider/model", modelKey)
+	}
+
+	provider := c.findProvider(providerName)
+	if provider == nil {
+		return nil, nil, fmt.Errorf("provider %s not found", providerName)
+	}
+
+	for _, model := range provider.Models {
+		if model.Name == modelName {
+			return provider, model, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("model %s not found in provider %s", modelName, providerName)
+}
+
+// AddModels adds models that are not yet configured to a provider and returns how many were added
+func (c *Config) AddModels(providerName string, modelNames []string, enabled bool) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	provider := c.findProvider(providerName)
+	if provider == nil {
+		return 0, fmt.Errorf("provider %s not found", providerName)
+	}
+
+	existing := make(map[string]bool, len(provider.Models))
+	for _, model := range provider.Models {
+		existing[model.Name] = true
+	}
+
+	added := 0
+	for _, name := range modelNames {
+		if name == "" || existing[name] {
+			continue
+		}
+		provider.Models = append(provider.Models, &ModelConfig{Name: name, Enabled: enabled})
+		existing[name] = true
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	return added, c.save()
+}
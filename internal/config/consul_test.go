@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestUseConsul(t *testing.T) {
+	cfg := newPathTestConfig()
+	cfg.Consul = &ConsulConfig{Enabled: true, Address: "127.0.0.1:8500", Key: "aiassist/config"}
+	cfg.Execution = &ExecutionConfig{PTY: true}
+
+	remote := newPathTestConfig()
+	remote.Language = LanguageChinese
+	remote.Redaction = &RedactionConfig{
+		Exclude:  []string{"ipv4"},
+		Patterns: []*RedactionPattern{{Name: "ticket", Regex: `TICKET-\d+`}},
+	}
+	remote.Sysinfo = &SysinfoConfig{Disabled: true}
+	remote.KB = &KBConfig{TopK: 5}
+	cfg.useConsul(remote)
+
+	if cfg.GetLanguage() != LanguageChinese {
+		t.Errorf("GetLanguage() = %q, want %q", cfg.GetLanguage(), LanguageChinese)
+	}
+	redaction := cfg.GetRedaction()
+	if len(redaction.Patterns) != 1 || redaction.Patterns[0].Name != "ticket" || len(redaction.Exclude) != 1 {
+		t.Errorf("GetRedaction() = %+v, want the patterns and excludes from Consul", redaction)
+	}
+	if cfg.SysinfoEnabled() {
+		t.Error("SysinfoEnabled() = true, want the setting from Consul")
+	}
+	if cfg.GetKBTopK() != 5 {
+		t.Errorf("GetKBTopK() = %d, want 5", cfg.GetKBTopK())
+	}
+	// Sections Consul doesn't set are kept
+	if !cfg.PTYEnabled() {
+		t.Error("PTYEnabled() = false, want the local setting")
+	}
+	if cfg.Consul == nil || cfg.Consul.Address != "127.0.0.1:8500" {
+		t.Errorf("Consul = %+v, want the local connection settings", cfg.Consul)
+	}
+}
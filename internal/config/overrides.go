@@ -0,0 +1,315 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables that override configuration values
+const (
+	EnvConfig      = "AIASSIST_CONFIG"
+	EnvModel       = "AIASSIST_MODEL"
+	EnvLanguage    = "AIASSIST_LANG"
+	EnvProvider    = "AIASSIST_PROVIDER"
+	EnvNoSysinfo   = "AIASSIST_NO_SYSINFO"
+	EnvMaxDepth    = "AIASSIST_MAX_DEPTH"
+	EnvPTY         = "AIASSIST_PTY"
+	EnvVerifyFlags = "AIASSIST_VERIFY_FLAGS"
+)
+
+// DefaultMaxDepth is the default maximum recursion depth for command analysis
+const DefaultMaxDepth = 10
+
+// DefaultTerminalWidth is the default terminal width for PTY execution,
+// wide enough that tables of kubectl, docker or ps aren't wrapped
+const DefaultTerminalWidth = 200
+
+// DefaultKBTopK is the default number of runbook sections sent with each question
+const DefaultKBTopK = 3
+
+// DefaultSysinfoTTL is the default age after which cached system information is refreshed
+const DefaultSysinfoTTL = 24 * time.Hour
+
+// Overrides holds runtime values from CLI flags and AIASSIST_* environment variables.
+//
+// Precedence (highest first): CLI flag > environment variable > Consul > config file > default.
+// Overrides are kept apart from the loaded values so they are never written back by Save.
+// Boolean overrides are nil when unset, so that false can override true.
+type Overrides struct {
+	Model       string // provider/model to use as default model
+	Language    string // en or zh
+	Provider    string // restrict to a single provider
+	NoSysinfo   *bool  // don't send system information to the model
+	MaxDepth    int    // maximum recursion depth for command analysis
+	PTY         *bool  // run commands in a pseudo-terminal
+	VerifyFlags *bool  // check the flags of suggested commands against local help
+}
+
+// overridesFromEnv reads overrides from AIASSIST_* environment variables
+func overridesFromEnv() (Overrides, error) {
+	o := Overrides{
+		Model:    strings.TrimSpace(os.Getenv(EnvModel)),
+		Language: strings.TrimSpace(os.Getenv(EnvLanguage)),
+		Provider: strings.TrimSpace(os.Getenv(EnvProvider)),
+	}
+
+	if v := strings.TrimSpace(os.Getenv(EnvNoSysinfo)); v != "" {
+		noSysinfo, err := strconv.ParseBool(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s value %q: %w", EnvNoSysinfo, v, err)
+		}
+		o.NoSysinfo = &noSysinfo
+	}
+
+	if v := strings.TrimSpace(os.Getenv(EnvPTY)); v != "" {
+		pty, err := strconv.ParseBool(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s value %q: %w", EnvPTY, v, err)
+		}
+		o.PTY = &pty
+	}
+
+	if v := strings.TrimSpace(os.Getenv(EnvVerifyFlags)); v != "" {
+		verifyFlags, err := strconv.ParseBool(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s value %q: %w", EnvVerifyFlags, v, err)
+		}
+		o.VerifyFlags = &verifyFlags
+	}
+
+	if v := strings.TrimSpace(os.Getenv(EnvMaxDepth)); v != "" {
+		maxDepth, err := strconv.Atoi(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s value %q: %w", EnvMaxDepth, v, err)
+		}
+		o.MaxDepth = maxDepth
+	}
+
+	return o, o.validate()
+}
+
+func (o Overrides) validate() error {
+	if o.Language != "" && o.Language != LanguageEnglish && o.Language != LanguageChinese {
+		return fmt.Errorf("unsupported language %q (supported: %s, %s)", o.Language, LanguageEnglish, LanguageChinese)
+	}
+	if o.Model != "" {
+		if providerName, modelName, ok := strings.Cut(o.Model, "/"); !ok || providerName == "" || modelName == "" {
+			return fmt.Errorf("invalid model %q, expected provider/model", o.Model)
+		}
+	}
+	if o.MaxDepth < 0 {
+		return fmt.Errorf("max depth must not be negative")
+	}
+	return nil
+}
+
+// ApplyOverrides layers overrides on top of the current ones; set fields win. A model
+// or provider replaces a current provider or model it conflicts with. The referenced
+// provider and model must exist in the configuration.
+func (c *Config) ApplyOverrides(o Overrides) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := c.overrides
+	if o.Model != "" {
+		merged.Model = o.Model
+		if o.Provider == "" && !modelOfProvider(merged.Model, merged.Provider) {
+			merged.Provider = ""
+		}
+	}
+	if o.Language != "" {
+		merged.Language = o.Language
+	}
+	if o.Provider != "" {
+		merged.Provider = o.Provider
+		if o.Model == "" && !modelOfProvider(merged.Model, merged.Provider) {
+			merged.Model = ""
+		}
+	}
+	if o.NoSysinfo != nil {
+		merged.NoSysinfo = o.NoSysinfo
+	}
+	if o.MaxDepth > 0 {
+		merged.MaxDepth = o.MaxDepth
+	}
+	if o.PTY != nil {
+		merged.PTY = o.PTY
+	}
+	if o.VerifyFlags != nil {
+		merged.VerifyFlags = o.VerifyFlags
+	}
+
+	if merged.Provider != "" && c.findProvider(merged.Provider) == nil {
+		return fmt.Errorf("provider %s not found", merged.Provider)
+	}
+	if merged.Model != "" {
+		if _, _, err := c.findModel(merged.Model); err != nil {
+			return err
+		}
+		if !modelOfProvider(merged.Model, merged.Provider) {
+			return fmt.Errorf("model %s does not belong to provider %s", merged.Model, merged.Provider)
+		}
+	}
+
+	c.overrides = merged
+	return nil
+}
+
+// ClearModelOverrides drops the model and provider overrides, e.g. a stale
+// AIASSIST_MODEL while fixing the configuration
+func (c *Config) ClearModelOverrides() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.overrides.Model = ""
+	c.overrides.Provider = ""
+}
+
+// modelOfProvider reports whether modelKey belongs to provider, or either is unset
+func modelOfProvider(modelKey, provider string) bool {
+	return modelKey == "" || provider == "" || strings.HasPrefix(modelKey, provider+"/")
+}
+
+// GetOverrides returns the active runtime overrides
+func (c *Config) GetOverrides() Overrides {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.overrides
+}
+
+// GetMaxDepth returns the maximum recursion depth for command analysis
+func (c *Config) GetMaxDepth() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.overrides.MaxDepth > 0 {
+		return c.overrides.MaxDepth
+	}
+	if c.MaxDepth > 0 {
+		return c.MaxDepth
+	}
+	return DefaultMaxDepth
+}
+
+// SysinfoEnabled reports whether system information should be sent to the model
+func (c *Config) SysinfoEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.overrides.NoSysinfo != nil {
+		return !*c.overrides.NoSysinfo
+	}
+	return c.Sysinfo == nil || !c.Sysinfo.Disabled
+}
+
+// GetSysinfoTTL returns the age after which cached system information is refreshed
+func (c *Config) GetSysinfoTTL() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Sysinfo != nil && c.Sysinfo.TTL > 0 {
+		return c.Sysinfo.TTL
+	}
+	return DefaultSysinfoTTL
+}
+
+// GetSysinfoCollector returns the settings of the named system information collector
+func (c *Config) GetSysinfoCollector(name string) CollectorConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Sysinfo == nil || c.Sysinfo.Collectors[name] == nil {
+		return CollectorConfig{}
+	}
+	return *c.Sysinfo.Collectors[name]
+}
+
+// GetRedaction returns the sensitive data redaction settings
+func (c *Config) GetRedaction() RedactionConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Redaction == nil {
+		return RedactionConfig{}
+	}
+	return *c.Redaction
+}
+
+// GetPromptTemplate returns the prompt template configured for the named prompt
+func (c *Config) GetPromptTemplate(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tmpl, ok := c.Prompts[name]
+	return tmpl, ok
+}
+
+// KBEnabled reports whether runbook excerpts should be sent to the model
+func (c *Config) KBEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.KB == nil || !c.KB.Disabled
+}
+
+// GetKBTopK returns the number of runbook sections sent with each question
+func (c *Config) GetKBTopK() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.KB != nil && c.KB.TopK > 0 {
+		return c.KB.TopK
+	}
+	return DefaultKBTopK
+}
+
+// PTYEnabled reports whether commands should run in a pseudo-terminal
+func (c *Config) PTYEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.overrides.PTY != nil {
+		return *c.overrides.PTY
+	}
+	return c.Execution != nil && c.Execution.PTY
+}
+
+// VerifyFlagsEnabled reports whether the flags of suggested commands should be
+// checked against the help of their tools before they are shown
+func (c *Config) VerifyFlagsEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.overrides.VerifyFlags != nil {
+		return *c.overrides.VerifyFlags
+	}
+	return c.Execution != nil && c.Execution.VerifyFlags
+}
+
+// GetTerminalWidth returns the terminal width for PTY execution
+func (c *Config) GetTerminalWidth() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Execution != nil && c.Execution.TerminalWidth > 0 {
+		return c.Execution.TerminalWidth
+	}
+	return DefaultTerminalWidth
+}
+
+// IsModelSelected reports whether a model was explicitly chosen with --model or AIASSIST_MODEL.
+// Explicitly chosen models and providers are used even if disabled in the config file.
+func (c *Config) IsModelSelected(modelKey string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.overrides.Model != "" && c.overrides.Model == modelKey
+}
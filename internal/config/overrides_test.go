@@ -0,0 +1,238 @@
+package config
+
+import (
+	"testing"
+)
+
+func newOverridesTestConfig() *Config {
+	return &Config{
+		Language:     LanguageEnglish,
+		DefaultModel: "a/m1",
+		Providers: []*ProviderConfig{
+			{Name: "a", Enabled: true, Models: []*ModelConfig{{Name: "m1", Enabled: true}, {Name: "m2"}}},
+			{Name: "b", Enabled: false, Models: []*ModelConfig{{Name: "x", Enabled: true}}},
+		},
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestOverridePrecedence(t *testing.T) {
+	type want struct {
+		language    string
+		model       string
+		provider    string
+		sysinfo     bool
+		pty         bool
+		verifyFlags bool
+		maxDepth    int
+	}
+	defaults := want{language: LanguageEnglish, model: "a/m1", sysinfo: true, maxDepth: DefaultMaxDepth}
+
+	tests := []struct {
+		name    string
+		file    func(c *Config)
+		env     map[string]string
+		flags   Overrides
+		want    func(w *want)
+		wantErr bool
+	}{
+		{name: "defaults", want: func(w *want) {}},
+		{
+			name: "file",
+			file: func(c *Config) {
+				c.Language = LanguageChinese
+				c.MaxDepth = 4
+				c.Sysinfo = &SysinfoConfig{Disabled: true}
+				c.Execution = &ExecutionConfig{PTY: true, VerifyFlags: true}
+			},
+			want: func(w *want) {
+				w.language, w.maxDepth, w.sysinfo, w.pty, w.verifyFlags = LanguageChinese, 4, false, true, true
+			},
+		},
+		{
+			name: "env over file",
+			file: func(c *Config) {
+				c.Sysinfo = &SysinfoConfig{Disabled: true}
+				c.Execution = &ExecutionConfig{PTY: true}
+			},
+			env: map[string]string{
+				EnvLanguage: "zh", EnvModel: "a/m2", EnvMaxDepth: "5",
+				EnvNoSysinfo: "false", EnvPTY: "false", EnvVerifyFlags: "true",
+			},
+			want: func(w *want) {
+				w.language, w.model, w.maxDepth, w.sysinfo, w.pty, w.verifyFlags = LanguageChinese, "a/m2", 5, true, false, true
+			},
+		},
+		{
+			name:  "flag false over env true",
+			env:   map[string]string{EnvNoSysinfo: "true", EnvPTY: "true", EnvVerifyFlags: "true"},
+			flags: Overrides{NoSysinfo: boolPtr(false), PTY: boolPtr(false), VerifyFlags: boolPtr(false)},
+			want:  func(w *want) {},
+		},
+		{
+			name:  "flag true over env false",
+			env:   map[string]string{EnvNoSysinfo: "false", EnvPTY: "false"},
+			flags: Overrides{NoSysinfo: boolPtr(true), PTY: boolPtr(true)},
+			want:  func(w *want) { w.sysinfo, w.pty = false, true },
+		},
+		{
+			name:  "unset flags keep env",
+			env:   map[string]string{EnvPTY: "true", EnvLanguage: "zh", EnvMaxDepth: "5"},
+			flags: Overrides{},
+			want:  func(w *want) { w.pty, w.language, w.maxDepth = true, LanguageChinese, 5 },
+		},
+		{
+			name:  "flag over env",
+			env:   map[string]string{EnvLanguage: "zh", EnvMaxDepth: "5", EnvModel: "a/m2"},
+			flags: Overrides{Language: LanguageEnglish, MaxDepth: 3, Model: "a/m1"},
+			want:  func(w *want) { w.maxDepth = 3 },
+		},
+		{
+			name:  "provider flag replaces env model",
+			env:   map[string]string{EnvModel: "a/m2"},
+			flags: Overrides{Provider: "b"},
+			want:  func(w *want) { w.provider = "b" },
+		},
+		{
+			name:  "provider flag keeps env model of the provider",
+			env:   map[string]string{EnvModel: "b/x"},
+			flags: Overrides{Provider: "b"},
+			want:  func(w *want) { w.model, w.provider = "b/x", "b" },
+		},
+		{
+			name:  "model flag replaces env provider",
+			env:   map[string]string{EnvProvider: "b"},
+			flags: Overrides{Model: "a/m2"},
+			want:  func(w *want) { w.model = "a/m2" },
+		},
+		{
+			name:    "conflicting flags",
+			flags:   Overrides{Model: "a/m2", Provider: "b"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown model",
+			flags:   Overrides{Model: "a/m3"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{EnvModel, EnvLanguage, EnvProvider, EnvNoSysinfo, EnvMaxDepth, EnvPTY, EnvVerifyFlags} {
+				t.Setenv(name, tt.env[name])
+			}
+			env, err := overridesFromEnv()
+			if err != nil {
+				t.Fatalf("overridesFromEnv() error = %v", err)
+			}
+
+			cfg := newOverridesTestConfig()
+			if tt.file != nil {
+				tt.file(cfg)
+			}
+			if err := cfg.ApplyOverrides(env); err != nil {
+				t.Fatalf("ApplyOverrides(env) error = %v", err)
+			}
+			err = cfg.ApplyOverrides(tt.flags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyOverrides(flags) error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			w := defaults
+			tt.want(&w)
+			got := want{
+				language:    cfg.GetLanguage(),
+				model:       cfg.GetDefaultModel(),
+				provider:    cfg.GetOverrides().Provider,
+				sysinfo:     cfg.SysinfoEnabled(),
+				pty:         cfg.PTYEnabled(),
+				verifyFlags: cfg.VerifyFlagsEnabled(),
+				maxDepth:    cfg.GetMaxDepth(),
+			}
+			if got != w {
+				t.Errorf("got %+v, want %+v", got, w)
+			}
+		})
+	}
+}
+
+func TestOverridesFromEnvInvalid(t *testing.T) {
+	for name, value := range map[string]string{
+		EnvPTY:      "sometimes",
+		EnvMaxDepth: "-1",
+		EnvLanguage: "fr",
+		EnvModel:    "gpt-4o",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := overridesFromEnv(); err == nil {
+				t.Errorf("overridesFromEnv() accepted %s=%q", name, value)
+			}
+		})
+	}
+}
+
+func TestClearModelOverrides(t *testing.T) {
+	t.Setenv(EnvModel, "gone/m1")
+	env, err := overridesFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := newOverridesTestConfig()
+	cfg.overrides = env
+	if err := cfg.ApplyOverrides(Overrides{Language: LanguageChinese}); err == nil {
+		t.Fatal("ApplyOverrides() accepted the model of a missing provider")
+	}
+
+	cfg.ClearModelOverrides()
+	if err := cfg.ApplyOverrides(Overrides{Language: LanguageChinese}); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+	if cfg.GetDefaultModel() != "a/m1" || cfg.GetLanguage() != LanguageChinese {
+		t.Errorf("got model %q, language %q, want a/m1, zh", cfg.GetDefaultModel(), cfg.GetLanguage())
+	}
+}
+
+func TestGetEnabledProviders(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides Overrides
+		want      []string
+	}{
+		{name: "enabled only", want: []string{"a"}},
+		{name: "provider selected", overrides: Overrides{Provider: "b"}, want: []string{"b"}},
+		{name: "model of disabled provider selected", overrides: Overrides{Model: "b/x"}, want: []string{"a", "b"}},
+		{name: "model of enabled provider selected", overrides: Overrides{Model: "a/m2"}, want: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newOverridesTestConfig()
+			if err := cfg.ApplyOverrides(tt.overrides); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, provider := range cfg.GetEnabledProviders() {
+				got = append(got, provider.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetEnabledProviders() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GetEnabledProviders() = %v, want %v", got, tt.want)
+				}
+			}
+			if tt.overrides.Model != "" && !cfg.IsModelSelected(tt.overrides.Model) {
+				t.Errorf("IsModelSelected(%q) = false", tt.overrides.Model)
+			}
+		})
+	}
+}
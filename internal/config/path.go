@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Settable configuration paths:
+//
+//	language
+//	default_model
+//	blacklist                              (comma-separated list)
+//	consul.enabled | consul.address | consul.key | consul.token
+//	providers.<name>.base_url | .api_key | .enabled
+//	providers.<name>.models                (comma-separated list of model names)
+//	providers.<name>.models.<model>.enabled
+//
+// Model names may contain dots (e.g. gpt-3.5-turbo), so model paths are parsed
+// by stripping the "models." prefix and ".enabled" suffix rather than splitting.
+
+// GetValue returns the value at a configuration path as a string
+func (c *Config) GetValue(path string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	switch path {
+	case "language":
+		return c.Language, nil
+	case "default_model":
+		return c.DefaultModel, nil
+	case "blacklist":
+		return strings.Join(c.Blacklist, ","), nil
+	}
+
+	if key, ok := strings.CutPrefix(path, "consul."); ok {
+		if c.Consul == nil {
+			return "", nil
+		}
+		switch key {
+		case "enabled":
+			return strconv.FormatBool(c.Consul.Enabled), nil
+		case "address":
+			return c.Consul.Address, nil
+		case "key":
+			return c.Consul.Key, nil
+		case "token":
+			return c.Consul.Token, nil
+		}
+		return "", fmt.Errorf("unknown config path: %s", path)
+	}
+
+	if rest, ok := strings.CutPrefix(path, "providers."); ok {
+		name, field, _ := strings.Cut(rest, ".")
+		provider := c.findProvider(name)
+		if provider == nil {
+			return "", fmt.Errorf("provider %s not found", name)
+		}
+
+		switch field {
+		case "base_url":
+			return provider.BaseURL, nil
+		case "api_key":
+			return provider.APIKey, nil
+		case "enabled":
+			return strconv.FormatBool(provider.Enabled), nil
+		case "models":
+			names := make([]string, 0, len(provider.Models))
+			for _, model := range provider.Models {
+				names = append(names, model.Name)
+			}
+			return strings.Join(names, ","), nil
+		}
+
+		if modelName, ok := parseModelEnabledField(field); ok {
+			_, model, err := c.findModel(name + "/" + modelName)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatBool(model.Enabled), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown config path: %s", path)
+}
+
+// SetValue sets the value at a configuration path and saves the configuration
+func (c *Config) SetValue(path, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.setValue(path, value); err != nil {
+		return err
+	}
+	return c.save()
+}
+
+// setValue applies a single path assignment (caller must hold lock)
+func (c *Config) setValue(path, value string) error {
+	switch path {
+	case "language":
+		if value != LanguageEnglish && value != LanguageChinese {
+			return fmt.Errorf("unsupported language %q (supported: %s, %s)", value, LanguageEnglish, LanguageChinese)
+		}
+		c.Language = value
+		return nil
+	case "default_model":
+		if value != "" {
+			if _, _, err := c.findModel(value); err != nil {
+				return err
+			}
+		}
+		c.DefaultModel = value
+		return nil
+	case "blacklist":
+		c.Blacklist = splitList(value)
+		return nil
+	}
+
+	if key, ok := strings.CutPrefix(path, "consul."); ok {
+		if c.Consul == nil {
+			c.Consul = &ConsulConfig{}
+		}
+		switch key {
+		case "enabled":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q for %s", value, path)
+			}
+			c.Consul.Enabled = enabled
+		case "address":
+			c.Consul.Address = value
+		case "key":
+			c.Consul.Key = value
+		case "token":
+			c.Consul.Token = value
+		default:
+			return fmt.Errorf("unknown config path: %s", path)
+		}
+		return nil
+	}
+
+	if rest, ok := strings.CutPrefix(path, "providers."); ok {
+		name, field, _ := strings.Cut(rest, ".")
+		provider := c.findProvider(name)
+		if provider == nil {
+			return fmt.Errorf("provider %s not found (add it with: aiassist config provider add %s)", name, name)
+		}
+
+		switch field {
+		case "base_url":
+			provider.BaseURL = strings.TrimRight(value, "/")
+			return nil
+		case "api_key":
+			provider.APIKey = value
+			return nil
+		case "enabled":
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q for %s", value, path)
+			}
+			provider.Enabled = enabled
+			return nil
+		case "models":
+			provider.Models = mergeModels(provider.Models, splitList(value))
+			return nil
+		}
+
+		if modelName, ok := parseModelEnabledField(field); ok {
+			_, model, err := c.findModel(name + "/" + modelName)
+			if err != nil {
+				return err
+			}
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q for %s", value, path)
+			}
+			model.Enabled = enabled
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown config path: %s", path)
+}
+
+// parseModelEnabledField extracts <model> from "models.<model>.enabled"
+func parseModelEnabledField(field string) (string, bool) {
+	rest, ok := strings.CutPrefix(field, "models.")
+	if !ok {
+		return "", false
+	}
+	modelName, ok := strings.CutSuffix(rest, ".enabled")
+	if !ok || modelName == "" {
+		return "", false
+	}
+	return modelName, true
+}
+
+// mergeModels returns model configs for names, keeping the enabled state of existing
+// models and enabling new ones
+func mergeModels(existing []*ModelConfig, names []string) []*ModelConfig {
+	models := make([]*ModelConfig, 0, len(names))
+	for _, name := range names {
+		model := &ModelConfig{Name: name, Enabled: true}
+		for _, m := range existing {
+			if m.Name == name {
+				model.Enabled = m.Enabled
+				break
+			}
+		}
+		models = append(models, model)
+	}
+	return models
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
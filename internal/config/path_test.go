@@ -0,0 +1,161 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newPathTestConfig() *Config {
+	return &Config{
+		Language:     LanguageEnglish,
+		DefaultModel: "openai/gpt-4o",
+		Providers: []*ProviderConfig{
+			{
+				Name:    "openai",
+				BaseURL: "https://api.openai.com/v1",
+				APIKey:  "env:OPENAI_KEY",
+				Enabled: true,
+				Models: []*ModelConfig{
+					{Name: "gpt-4o", Enabled: true},
+					{Name: "gpt-3.5-turbo", Enabled: false},
+				},
+			},
+		},
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	cfg := newPathTestConfig()
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "language", want: "en"},
+		{path: "default_model", want: "openai/gpt-4o"},
+		{path: "consul.enabled", want: ""},
+		{path: "providers.openai.base_url", want: "https://api.openai.com/v1"},
+		{path: "providers.openai.enabled", want: "true"},
+		{path: "providers.openai.models", want: "gpt-4o,gpt-3.5-turbo"},
+		{path: "providers.openai.models.gpt-3.5-turbo.enabled", want: "false"},
+		{path: "providers.openai.models.gpt-4.enabled", wantErr: true},
+		{path: "providers.missing.enabled", wantErr: true},
+		{path: "providers.openai.unknown", wantErr: true},
+		{path: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := cfg.GetValue(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetValue(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GetValue(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		path    string
+		value   string
+		get     string // Path to read back, defaults to path
+		want    string
+		wantErr bool
+	}{
+		{path: "language", value: "zh", want: "zh"},
+		{path: "language", value: "fr", wantErr: true},
+		{path: "default_model", value: "openai/gpt-3.5-turbo", want: "openai/gpt-3.5-turbo"},
+		{path: "default_model", value: "openai/missing", wantErr: true},
+		{path: "default_model", value: "", want: ""},
+		{path: "blacklist", value: " rm -rf *, ,shutdown ", want: "rm -rf *,shutdown"},
+		{path: "consul.enabled", value: "yes", wantErr: true},
+		{path: "consul.address", value: "127.0.0.1:8500", want: "127.0.0.1:8500"},
+		{path: "providers.openai.base_url", value: "https://proxy/v1/", want: "https://proxy/v1"},
+		{path: "providers.openai.enabled", value: "false", want: "false"},
+		{path: "providers.openai.models.gpt-3.5-turbo.enabled", value: "true", want: "true"},
+		{path: "providers.openai.models.gpt-3.5-turbo.enabled", value: "maybe", wantErr: true},
+		{path: "providers.openai.models..enabled", value: "true", wantErr: true},
+		{path: "providers.missing.enabled", value: "true", wantErr: true},
+		{
+			path: "providers.openai.models", value: "gpt-3.5-turbo, o1",
+			get: "providers.openai.models.gpt-3.5-turbo.enabled", want: "false",
+		},
+	}
+
+	for _, tt := range tests {
+		cfg := newPathTestConfig()
+		err := cfg.setValue(tt.path, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("setValue(%q, %q) error = %v, wantErr %v", tt.path, tt.value, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		get := tt.get
+		if get == "" {
+			get = tt.path
+		}
+		if got, err := cfg.GetValue(get); err != nil || got != tt.want {
+			t.Errorf("after setValue(%q, %q): GetValue(%q) = %q, %v, want %q", tt.path, tt.value, get, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseModelEnabledField(t *testing.T) {
+	tests := []struct {
+		field  string
+		want   string
+		wantOK bool
+	}{
+		{"models.gpt-4o.enabled", "gpt-4o", true},
+		{"models.gpt-3.5-turbo.enabled", "gpt-3.5-turbo", true},
+		{"models.qwen/qwen2.5-72b.enabled", "qwen/qwen2.5-72b", true},
+		{"models..enabled", "", false},
+		{"models.gpt-4o", "", false},
+		{"model.gpt-4o.enabled", "", false},
+		{"enabled", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseModelEnabledField(tt.field)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseModelEnabledField(%q) = %q, %v, want %q, %v", tt.field, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMergeModels(t *testing.T) {
+	existing := []*ModelConfig{
+		{Name: "a", Enabled: true},
+		{Name: "b", Enabled: false},
+	}
+	got := mergeModels(existing, []string{"b", "c"})
+	want := []*ModelConfig{
+		{Name: "b", Enabled: false},
+		{Name: "c", Enabled: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeModels() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"a,b", []string{"a", "b"}},
+		{" a , ,b ,", []string{"a", "b"}},
+		{"", []string{}},
+		{" , ", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := splitList(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitList(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
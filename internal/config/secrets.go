@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Secret reference schemes supported in api_key
+const (
+	SecretSchemeEnv   = "env"
+	SecretSchemeFile  = "file"
+	SecretSchemeExec  = "exec"
+	SecretSchemeVault = "vault"
+)
+
+// secretExecTimeout limits how long an exec: secret command may run
+const secretExecTimeout = 10 * time.Second
+
+// IsSecretReference reports whether value is a secret reference
+// (env:, file:, exec: or vault:) rather than a literal secret
+func IsSecretReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+
+	switch scheme {
+	case SecretSchemeEnv, SecretSchemeFile, SecretSchemeExec, SecretSchemeVault:
+		return true
+	}
+	return false
+}
+
+// ResolveSecret resolves a secret reference to its value.
+//
+// Supported forms:
+//
+//	env:OPENAI_KEY              value of environment variable OPENAI_KEY
+//	file:/run/secrets/key       content of the file (surrounding whitespace trimmed)
+//	exec:pass show llm          first line of the command's stdout
+//	vault:secret/data/llm#key   field "key" of a Vault KV secret (uses VAULT_ADDR and VAULT_TOKEN)
+//
+// Any other value is treated as a literal secret and returned unchanged.
+func ResolveSecret(value string) (string, error) {
+	if !IsSecretReference(value) {
+		return value, nil
+	}
+
+	scheme, ref, _ := strings.Cut(value, ":")
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("empty %s secret reference", scheme)
+	}
+
+	switch scheme {
+	case SecretSchemeEnv:
+		return resolveEnvSecret(ref)
+	case SecretSchemeFile:
+		return resolveFileSecret(ref)
+	case SecretSchemeExec:
+		return resolveExecSecret(ref)
+	case SecretSchemeVault:
+		return resolveVaultSecret(ref)
+	}
+
+	return "", fmt.Errorf("unsupported secret scheme: %s", scheme)
+}
+
+// ResolveAPIKey returns the provider API key with any secret reference resolved
+func (p *ProviderConfig) ResolveAPIKey() (string, error) {
+	key, err := ResolveSecret(p.APIKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve api_key for provider %s: %w", p.Name, err)
+	}
+	return key, nil
+}
+
+func resolveEnvSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func resolveFileSecret(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+	return value, nil
+}
+
+func resolveExecSecret(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretExecTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+
+	// Only the first line is used, like `pass show` which prints the password first
+	value, _, _ := strings.Cut(string(output), "\n")
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("secret command returned empty output")
+	}
+	return value, nil
+}
+
+// resolveVaultSecret reads "path#field" from Vault's HTTP API.
+// Both KV v2 (data.data.field) and KV v1 (data.field) responses are supported.
+func resolveVaultSecret(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference must be in the form path#field")
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	if token == "" {
+		return "", fmt.Errorf("VAULT_TOKEN is not set and ~/.vault-token not found")
+	}
+
+	url := strings.TrimRight(addr, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	client := &http.Client{
+		Timeout:   secretExecTimeout,
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned HTTP %d for %s", resp.StatusCode, path)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	data := secret.Data
+	// KV v2 nests the secret under data.data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = nested
+		}
+	}
+
+	value, ok := data[field].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("field %s not found in vault secret %s", field, path)
+	}
+	return value, nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSecretReference(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"sk-xxxxxxxxxxxx", false},
+		{"env:OPENAI_KEY", true},
+		{"file:/run/secrets/key", true},
+		{"exec:pass show llm", true},
+		{"vault:secret/data/llm#key", true},
+		{"https://example.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSecretReference(tt.value); got != tt.want {
+			t.Errorf("IsSecretReference(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("AIASSIST_TEST_KEY", "sk-from-env")
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte("sk-from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "literal", value: "sk-literal", want: "sk-literal"},
+		{name: "env", value: "env:AIASSIST_TEST_KEY", want: "sk-from-env"},
+		{name: "env missing", value: "env:AIASSIST_TEST_MISSING", wantErr: true},
+		{name: "file", value: "file:" + keyFile, want: "sk-from-file"},
+		{name: "file missing", value: "file:" + filepath.Join(dir, "missing"), wantErr: true},
+		{name: "exec first line", value: "exec:printf 'sk-from-exec\\nlogin: me\\n'", want: "sk-from-exec"},
+		{name: "exec failure", value: "exec:false", wantErr: true},
+		{name: "empty reference", value: "env:", wantErr: true},
+		{name: "vault without field", value: "vault:secret/data/llm", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSecret(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSecret(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSecret(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVaultSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/llm":
+			w.Write([]byte(`{"data":{"data":{"key":"sk-from-vault-v2"},"metadata":{"version":1}}}`))
+		case "/v1/kv/llm":
+			w.Write([]byte(`{"data":{"key":"sk-from-vault-v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	if got, err := ResolveSecret("vault:secret/data/llm#key"); err != nil || got != "sk-from-vault-v2" {
+		t.Errorf("kv v2: got %q, %v", got, err)
+	}
+	if got, err := ResolveSecret("vault:kv/llm#key"); err != nil || got != "sk-from-vault-v1" {
+		t.Errorf("kv v1: got %q, %v", got, err)
+	}
+	if _, err := ResolveSecret("vault:secret/data/llm#missing"); err == nil {
+		t.Error("expected error for missing field")
+	}
+	if _, err := ResolveSecret("vault:secret/data/other#key"); err == nil {
+		t.Error("expected error for missing secret")
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateFile checks the config file against the configuration schema.
+// Unknown keys and type mismatches are reported as errors.
+func ValidateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("schema error: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateProviderName checks that name can be used in provider/model keys
+func ValidateProviderName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("provider name is required")
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("provider name %q must not contain '/'", name)
+	}
+	return nil
+}
+
+// Validate checks the loaded configuration for semantic problems such as
+// duplicate names, invalid URLs and a default model that doesn't exist.
+// It returns all problems found rather than stopping at the first one.
+func (c *Config) Validate() []error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var problems []error
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if c.Language != LanguageEnglish && c.Language != LanguageChinese {
+		addf("language: unsupported value %q (supported: %s, %s)", c.Language, LanguageEnglish, LanguageChinese)
+	}
+
+	if c.Consul != nil && c.Consul.Enabled {
+		if c.Consul.Address == "" {
+			addf("consul.address: required when consul is enabled")
+		}
+		if c.Consul.Key == "" {
+			addf("consul.key: required when consul is enabled")
+		}
+	}
+
+	providerNames := make(map[string]bool)
+	for i, provider := range c.Providers {
+		if provider == nil {
+			addf("providers[%d]: empty provider entry", i)
+			continue
+		}
+
+		name := provider.Name
+		if name == "" {
+			addf("providers[%d].name: required", i)
+			name = fmt.Sprintf("[%d]", i)
+		} else if err := ValidateProviderName(name); err != nil {
+			addf("providers.%s.name: %v", name, err)
+		}
+		if provider.Name != "" && providerNames[provider.Name] {
+			addf("providers.%s: duplicate provider name", name)
+		}
+		providerNames[provider.Name] = true
+
+		if provider.BaseURL == "" {
+			addf("providers.%s.base_url: required", name)
+		} else if u, err := url.Parse(provider.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			addf("providers.%s.base_url: invalid URL %q", name, provider.BaseURL)
+		}
+
+		if IsSecretReference(provider.APIKey) {
+			if _, ref, _ := strings.Cut(provider.APIKey, ":"); strings.TrimSpace(ref) == "" {
+				addf("providers.%s.api_key: empty secret reference %q", name, provider.APIKey)
+			}
+		}
+
+		if len(provider.Models) == 0 {
+			addf("providers.%s.models: at least one model is required", name)
+		}
+
+		modelNames := make(map[string]bool)
+		enabledModels := 0
+		for j, model := range provider.Models {
+			if model == nil || model.Name == "" {
+				addf("providers.%s.models[%d].name: required", name, j)
+				continue
+			}
+			if modelNames[model.Name] {
+				addf("providers.%s.models.%s: duplicate model name", name, model.Name)
+			}
+			modelNames[model.Name] = true
+			if model.Enabled {
+				enabledModels++
+			}
+		}
+		if provider.Enabled && len(provider.Models) > 0 && enabledModels == 0 {
+			addf("providers.%s: provider is enabled but has no enabled models", name)
+		}
+	}
+
+	if c.DefaultModel != "" {
+		provider, model, err := c.findModel(c.DefaultModel)
+		if err != nil {
+			addf("default_model: %v", err)
+		} else if !provider.Enabled || !model.Enabled {
+			addf("default_model: %s is disabled", c.DefaultModel)
+		}
+	}
+
+	for i, pattern := range c.Blacklist {
+		if strings.TrimSpace(pattern) == "" {
+			addf("blacklist[%d]: empty pattern", i)
+		}
+	}
+
+	if c.Redaction != nil {
+		for i, pattern := range c.Redaction.Patterns {
+			if pattern == nil || pattern.Name == "" {
+				addf("redaction.patterns[%d].name: required", i)
+				continue
+			}
+			if _, err := regexp.Compile(pattern.Regex); err != nil || pattern.Regex == "" {
+				addf("redaction.patterns.%s.regex: invalid regular expression %q", pattern.Name, pattern.Regex)
+			}
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string // Substrings of the expected problems, in order
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{
+			name:   "unsupported language",
+			modify: func(c *Config) { c.Language = "fr" },
+			want:   []string{"language: unsupported value"},
+		},
+		{
+			name:   "consul without address and key",
+			modify: func(c *Config) { c.Consul = &ConsulConfig{Enabled: true} },
+			want:   []string{"consul.address: required", "consul.key: required"},
+		},
+		{
+			name: "duplicate provider",
+			modify: func(c *Config) {
+				c.Providers = append(c.Providers, &ProviderConfig{
+					Name: "openai", BaseURL: "https://example.com/v1", Models: []*ModelConfig{{Name: "m"}},
+				})
+			},
+			want: []string{"providers.openai: duplicate provider name"},
+		},
+		{
+			name:   "provider name with slash",
+			modify: func(c *Config) { c.Providers[0].Name = "open/ai"; c.DefaultModel = "" },
+			want:   []string{"providers.open/ai.name: provider name \"open/ai\" must not contain '/'"},
+		},
+		{
+			name:   "missing provider name",
+			modify: func(c *Config) { c.Providers[0].Name = ""; c.DefaultModel = "" },
+			want:   []string{"providers[0].name: required"},
+		},
+		{
+			name:   "invalid base URL",
+			modify: func(c *Config) { c.Providers[0].BaseURL = "api.openai.com/v1" },
+			want:   []string{"providers.openai.base_url: invalid URL"},
+		},
+		{
+			name:   "empty secret reference",
+			modify: func(c *Config) { c.Providers[0].APIKey = "env: " },
+			want:   []string{"providers.openai.api_key: empty secret reference"},
+		},
+		{
+			name: "duplicate model with dots",
+			modify: func(c *Config) {
+				c.Providers[0].Models = append(c.Providers[0].Models, &ModelConfig{Name: "gpt-3.5-turbo"})
+			},
+			want: []string{"providers.openai.models.gpt-3.5-turbo: duplicate model name"},
+		},
+		{
+			name: "no enabled models",
+			modify: func(c *Config) {
+				c.Providers[0].Models[0].Enabled = false
+				c.DefaultModel = ""
+			},
+			want: []string{"providers.openai: provider is enabled but has no enabled models"},
+		},
+		{
+			name:   "missing default model",
+			modify: func(c *Config) { c.DefaultModel = "openai/gpt-5" },
+			want:   []string{"default_model: model gpt-5 not found in provider openai"},
+		},
+		{
+			name:   "disabled default model",
+			modify: func(c *Config) { c.DefaultModel = "openai/gpt-3.5-turbo" },
+			want:   []string{"default_model: openai/gpt-3.5-turbo is disabled"},
+		},
+		{
+			name:   "default model without provider",
+			modify: func(c *Config) { c.DefaultModel = "gpt-4o" },
+			want:   []string{"default_model:"},
+		},
+		{
+			name:   "empty blacklist pattern",
+			modify: func(c *Config) { c.Blacklist = []string{"rm -rf *", " "} },
+			want:   []string{"blacklist[1]: empty pattern"},
+		},
+		{
+			name: "invalid redaction pattern",
+			modify: func(c *Config) {
+				c.Redaction = &RedactionConfig{Patterns: []*RedactionPattern{{Name: "ticket", Regex: "("}}}
+			},
+			want: []string{"redaction.patterns.ticket.regex: invalid regular expression"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newPathTestConfig()
+			tt.modify(cfg)
+			problems := cfg.Validate()
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d problems", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i].Error(), want) {
+					t.Errorf("Validate()[%d] = %q, want it to contain %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateProviderName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"openai", false},
+		{"my-proxy.internal", false},
+		{"", true},
+		{" ", true},
+		{"qwen/dashscope", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateProviderName(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateProviderName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAddProviderRejectsSlash(t *testing.T) {
+	cfg := &Config{ConfigFile: filepath.Join(t.TempDir(), "config.yaml")}
+	if err := cfg.AddProvider("qwen/dashscope", &ProviderConfig{}); err == nil {
+		t.Fatal("AddProvider() accepted a name containing '/'")
+	}
+	if _, err := os.Stat(cfg.ConfigFile); !os.IsNotExist(err) {
+		t.Errorf("AddProvider() saved the config: %v", err)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid", content: "language: en\nproviders:\n  - name: openai\n    enabled: true\n"},
+		{name: "empty", content: ""},
+		{name: "unknown key", content: "language: en\nlanguages: zh\n", wantErr: true},
+		{name: "type mismatch", content: "max_depth: deep\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := ValidateFile(path); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,1076 @@
+package executor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Classification is the local static verdict for a command line
+type Classification struct {
+	Type CommandType
+	// Reason names the part of the command that modifies the system, e.g. "rm",
+	// "sed -i" or "> /etc/hosts". Empty for query commands.
+	Reason string
+	// Known reports whether every command in the line was recognized as read-only.
+	// Unknown commands are classified as QueryCommand with Known false, so the
+	// model's tag is kept for them.
+	Known bool
+}
+
+// Classify statically classifies a shell command line. Pipelines, lists (&&, ||, ;),
+// command substitutions and wrappers like sudo, env, xargs and sh -c are inspected,
+// and any part that writes (redirections to files, tee, sed -i, rm, package managers,
+// mutating subcommands, ...) makes the whole line a modify command.
+func Classify(command string) Classification {
+	return classifyLine(command, 0)
+}
+
+// maxClassifyDepth bounds recursion into sh -c strings and command substitutions
+const maxClassifyDepth = 5
+
+func classifyLine(command string, depth int) Classification {
+	if depth > maxClassifyDepth {
+		return Classification{Type: QueryCommand}
+	}
+
+	segments, substitutions := lexShell(command)
+	result := Classification{Type: QueryCommand, Known: true}
+
+	for _, sub := range substitutions {
+		if c := classifyLine(sub, depth+1); c.Type == ModifyCommand {
+			return c
+		} else if !c.Known {
+			result.Known = false
+		}
+	}
+
+	for _, seg := range segments {
+		for _, r := range seg.redirects {
+			if isOutputRedirect(r.op) && !isHarmlessTarget(r.target) {
+				return modify(r.op + " " + r.target)
+			}
+		}
+		if len(seg.words) == 0 {
+			continue
+		}
+
+		c := classifyWords(seg.words, depth)
+		if c.Type == ModifyCommand {
+			return c
+		}
+		if !c.Known {
+			result.Known = false
+		}
+	}
+
+	return result
+}
+
+func modify(reason string) Classification {
+	return Classification{Type: ModifyCommand, Reason: reason, Known: true}
+}
+
+var (
+	readOnly = Classification{Type: QueryCommand, Known: true}
+	unknown  = Classification{Type: QueryCommand}
+)
+
+// envAssignmentRe matches leading VAR=value words
+var envAssignmentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// classifyWords classifies a single simple command
+func classifyWords(words []string, depth int) Classification {
+	stripped := stripWrappers(words)
+	if len(stripped) == 0 {
+		return readOnly
+	}
+	// watch runs a quoted command line through sh -c, like sh -c does
+	if len(stripped) == 1 && len(stripped) < len(words) && strings.ContainsAny(stripped[0], " \t\n") {
+		return classifyLine(stripped[0], depth+1)
+	}
+	words = stripped
+
+	name := filepath.Base(words[0])
+	args := words[1:]
+
+	switch name {
+	case "sh", "bash", "zsh", "dash", "ksh":
+		for i, arg := range args {
+			if arg == "-c" && i+1 < len(args) {
+				return classifyLine(args[i+1], depth+1)
+			}
+		}
+		return unknown
+
+	case "xargs":
+		inner := skipOptions(args, xargsOptionsWithValue)
+		if len(inner) == 0 {
+			return readOnly // xargs defaults to echo
+		}
+		return classifyWords(inner, depth)
+
+	case "find":
+		return classifyFind(args, depth)
+	}
+
+	if rule, ok := commandRules[name]; ok {
+		return rule(name, args)
+	}
+	if alwaysModify[name] {
+		return modify(name)
+	}
+	if readOnlyCommands[name] {
+		return readOnly
+	}
+	for _, prefix := range modifyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return modify(name)
+		}
+	}
+	return unknown
+}
+
+// stripWrappers removes env assignments and wrappers like sudo, env, nohup or timeout
+// that run another command
+func stripWrappers(words []string) []string {
+	for len(words) > 0 {
+		if envAssignmentRe.MatchString(words[0]) {
+			words = words[1:]
+			continue
+		}
+
+		name := filepath.Base(words[0])
+		optionsWithValue, isWrapper := wrappers[name]
+		if !isWrapper {
+			return words
+		}
+		words = skipOptions(words[1:], optionsWithValue)
+
+		// timeout takes a duration before the command; env assignments are
+		// stripped by the next iteration
+		if name == "timeout" && len(words) > 0 {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+// wrappers run the command given after their options; the value lists options taking an argument
+var wrappers = map[string][]string{
+	"sudo":    {"-u", "-g", "-h", "-p", "-C", "-D", "-r", "-t", "-U"},
+	"doas":    {"-u", "-C"},
+	"env":     {"-u", "-C", "-S"},
+	"nohup":   nil,
+	"time":    {"-f", "-o"},
+	"nice":    {"-n"},
+	"ionice":  {"-c", "-n", "-p"},
+	"timeout": {"-k", "-s"},
+	"watch":   {"-n"},
+	"stdbuf":  {"-i", "-o", "-e"},
+	"command": nil,
+	"exec":    {"-a"},
+	"chrt":    nil,
+	"taskset": nil,
+}
+
+var xargsOptionsWithValue = []string{"-I", "-i", "-n", "-P", "-L", "-l", "-d", "-E", "-s", "-a"}
+
+// skipOptions drops leading options (and the values of options in withValue)
+func skipOptions(words, withValue []string) []string {
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+		opt := words[0]
+		words = words[1:]
+		if opt == "--" {
+			break
+		}
+		for _, v := range withValue {
+			if opt == v && len(words) > 0 {
+				words = words[1:]
+				break
+			}
+		}
+	}
+	return words
+}
+
+// alwaysModify lists commands that change the system whatever their arguments
+var alwaysModify = map[string]bool{
+	"rm": true, "rmdir": true, "mv": true, "cp": true, "dd": true, "mkdir": true, "touch": true,
+	"chmod": true, "chown": true, "chgrp": true, "chattr": true, "ln": true, "truncate": true,
+	"shred": true, "install": true, "patch": true, "rsync": true, "scp": true, "unlink": true,
+	"kill": true, "killall": true, "pkill": true, "reboot": true, "shutdown": true, "halt": true,
+	"poweroff": true, "init": true, "telinit": true,
+	"useradd": true, "userdel": true, "usermod": true, "groupadd": true, "groupdel": true,
+	"groupmod": true, "passwd": true, "chpasswd": true, "visudo": true,
+	"umount": true, "swapon": true, "swapoff": true, "mkswap": true, "parted": true,
+	"wipefs": true, "pvcreate": true, "vgcreate": true, "lvcreate": true, "lvextend": true,
+	"lvresize": true, "lvremove": true, "resize2fs": true, "xfs_growfs": true, "fsck": true,
+	"modprobe": true, "insmod": true, "rmmod": true, "update-grub": true, "grub-mkconfig": true,
+	"ufw": true, "firewall-cmd": true, "setenforce": true, "semanage": true,
+	"vim": true, "vi": true, "nano": true, "emacs": true, "ed": true,
+	"npx": true, "make": true, "ansible-playbook": true, "terraform": true, "reset": true,
+}
+
+// modifyPrefixes catches command families such as mkfs.ext4
+var modifyPrefixes = []string{"mkfs", "fsck."}
+
+// readOnlyCommands lists commands that only read, whatever their arguments
+// (commands that become modifying with some flags have a rule instead)
+var readOnlyCommands = map[string]bool{
+	"ls": true, "ll": true, "cat": true, "tac": true, "head": true, "tail": true, "less": true,
+	"more": true, "grep": true, "egrep": true, "fgrep": true, "zgrep": true, "zcat": true,
+	"rg": true, "ag": true, "cut": true, "sort": true, "uniq": true,
+	"wc": true, "tr": true, "column": true, "jq": true, "yq": true, "nl": true, "fold": true,
+	"paste": true, "join": true, "comm": true, "diff": true, "cmp": true, "rev": true,
+	"echo": true, "printf": true, "true": true, "false": true, "test": true, "[": true,
+	"sleep": true, "seq": true, "yes": true, "pwd": true, "cd": true, "basename": true,
+	"dirname": true, "readlink": true, "realpath": true, "stat": true, "file": true,
+	"which": true, "whereis": true, "type": true, "whatis": true, "man": true, "apropos": true,
+	"cal": true, "uptime": true, "uname": true, "whoami": true, "id": true,
+	"groups": true, "who": true, "w": true, "last": true, "lastlog": true, "users": true,
+	"printenv": true, "locale": true, "tty": true, "nproc": true, "getconf": true, "arch": true,
+	"df": true, "du": true, "free": true, "top": true, "htop": true, "atop": true, "ps": true,
+	"pgrep": true, "pidof": true, "pstree": true, "lsof": true, "fuser": true, "ss": true,
+	"netstat": true, "ping": true, "ping6": true, "traceroute": true, "tracepath": true,
+	"mtr": true, "dig": true, "nslookup": true, "host": true, "whois": true, "arp": true,
+	"route": true, "lsblk": true, "blkid": true, "findmnt": true, "lscpu": true, "lsmem": true,
+	"lspci": true, "lsusb": true, "lsmod": true, "lshw": true, "dmidecode": true,
+	"vmstat": true, "iostat": true, "mpstat": true, "sar": true, "pidstat": true, "iotop": true,
+	"iftop": true, "nethogs": true, "dstat": true, "uptimed": true, "tree": true,
+	"md5sum": true, "sha1sum": true, "sha256sum": true, "sha512sum": true, "cksum": true,
+	"base64": true, "xxd": true, "od": true, "hexdump": true, "strings": true, "getent": true,
+	"systemd-analyze": true, "systemd-cgls": true, "systemd-cgtop": true,
+	"ulimit": true, "history": true,
+	"ldd": true, "nm": true, "objdump": true, "readelf": true, "namei": true, "getfacl": true,
+	"ethtool": true, "iw": true, "iwconfig": true,
+}
+
+// commandRules classify commands whose effect depends on their subcommand or flags
+var commandRules = map[string]func(name string, args []string) Classification{
+	"sed":  classifySed,
+	"perl": classifyInPlace("-i", "-pi"),
+	"awk":  classifyAwk,
+	"gawk": classifyAwk,
+	"tee":  classifyTee,
+	"curl": classifyCurl,
+	"wget": classifyWget,
+
+	"systemctl":   subcommandRule(0, systemctlReadOnly, true),
+	"service":     subcommandRule(1, set("status"), false),
+	"hostnamectl": subcommandRule(0, systemdCtlReadOnly, true),
+	"timedatectl": subcommandRule(0, systemdCtlReadOnly, true),
+	"localectl":   subcommandRule(0, systemdCtlReadOnly, true),
+	"loginctl":    subcommandRule(0, systemdCtlReadOnly, true),
+	"networkctl":  subcommandRule(0, systemdCtlReadOnly, true),
+	"resolvectl":  subcommandRule(0, set("status", "query", "statistics", "dns", "domain"), true),
+	"journalctl":  classifyJournalctl,
+
+	"kubectl":   classifyKubectl,
+	"oc":        classifyKubectl,
+	"docker":    classifyDocker,
+	"podman":    classifyDocker,
+	"nerdctl":   classifyDocker,
+	"crictl":    subcommandRule(0, set("ps", "pods", "images", "img", "inspect", "inspectp", "inspecti", "logs", "stats", "statsp", "info", "version"), false),
+	"ctr":       subcommandPathRule(set("version", "namespaces ls", "images ls", "containers ls", "tasks ls", "c ls", "i ls", "t ls", "ns ls", "containers info", "c info")),
+	"helm":      classifyHelm,
+	"git":       classifyGit,
+	"etcdctl":   subcommandRule(0, set("get", "member", "endpoint", "version", "check", "alarm", "watch", "user", "role"), false),
+	"consul":    subcommandRule(0, set("members", "info", "version", "catalog", "monitor", "operator", "kv"), false),
+	"virsh":     subcommandRule(0, set("list", "dominfo", "domstate", "dumpxml", "nodeinfo", "capabilities", "version", "net-list", "pool-list", "vol-list"), false),
+	"ip":        classifyIP,
+	"iptables":  classifyListOnly("-L", "--list", "-S", "--list-rules", "-nL", "-vL", "-nvL", "-vnL"),
+	"ip6tables": classifyListOnly("-L", "--list", "-S", "--list-rules", "-nL", "-vL", "-nvL", "-vnL"),
+	"nft":       classifyNft,
+	"sysctl":    classifySysctl,
+	"mount":     argsModify(),
+	"hostname":  argsModify(),
+	"ifconfig":  classifyIfconfig,
+	"dmesg":     flagsModify("-c", "-C", "--clear", "--read-clear", "-D", "-E", "-n", "--console-level"),
+	"crontab":   classifyCrontab,
+	"date":      classifyDate,
+	"tcpdump":   classifyTcpdump,
+	"openssl":   classifyOpenSSL,
+	"tar":       classifyTar,
+	"fdisk":     classifyListOnly("-l", "--list"),
+	"sfdisk":    classifyListOnly("-l", "--list", "-d", "--dump"),
+	"gzip":      classifyListOnly("-l", "--list", "-t", "--test"),
+	"nginx":     classifyListOnly("-t", "-T", "-v", "-V", "-h", "-?"),
+	"apachectl": classifyListOnly("-t", "-S", "-M", "-v", "-V", "configtest"),
+	"nmcli":     classifyNmcli,
+
+	"apt":       subcommandRule(0, set("list", "search", "show", "policy", "depends", "rdepends", "changelog"), false),
+	"apt-get":   subcommandRule(0, set("check", "changelog"), false),
+	"apt-cache": func(string, []string) Classification { return readOnly },
+	"yum":       subcommandRule(0, packageReadOnly, false),
+	"dnf":       subcommandRule(0, packageReadOnly, false),
+	"zypper":    subcommandRule(0, set("search", "se", "info", "if", "list-updates", "lu", "repos", "lr", "packages", "pa", "patches"), false),
+	"apk":       subcommandRule(0, set("info", "search", "list", "policy", "stats", "version", "dot"), false),
+	"pacman":    classifyListOnly("-Q", "-Qi", "-Ql", "-Qs", "-Ss", "-Si", "-Qe", "-Qq"),
+	"brew":      subcommandRule(0, set("list", "ls", "search", "info", "deps", "uses", "outdated", "config", "doctor", "leaves", "--version"), false),
+	"snap":      subcommandRule(0, set("list", "info", "find", "services", "changes", "version"), false),
+	"pip":       subcommandRule(0, pipReadOnly, false),
+	"pip3":      subcommandRule(0, pipReadOnly, false),
+	"npm":       subcommandRule(0, set("ls", "list", "view", "info", "search", "outdated", "config", "version", "-v", "--version"), false),
+	"gem":       subcommandRule(0, set("list", "search", "info", "query", "environment", "contents"), false),
+	"rpm":       classifyListOnly("-q", "-qa", "-qi", "-ql", "-qf", "-qc", "-qd", "-qR", "-V", "--query", "--verify"),
+	"dpkg":      classifyListOnly("-l", "-L", "-s", "-S", "-p", "--list", "--listfiles", "--status", "--search", "--print-avail", "--get-selections", "--audit"),
+}
+
+var (
+	systemctlReadOnly = set("status", "show", "cat", "list-units", "list-unit-files", "list-timers",
+		"list-sockets", "list-dependencies", "list-jobs", "list-machines", "is-active", "is-enabled",
+		"is-failed", "is-system-running", "get-default", "help", "--version", "--failed", "--all")
+	systemdCtlReadOnly = set("status", "show", "list", "list-sessions", "list-users", "list-seats",
+		"list-timezones", "list-locales", "list-keymaps", "timesync-status", "show-timesync", "show-session", "show-user")
+	packageReadOnly = set("list", "info", "search", "provides", "whatprovides", "repolist", "check-update",
+		"history", "deplist", "repoquery", "updateinfo", "--version")
+	pipReadOnly = set("list", "show", "freeze", "check", "search", "index", "inspect", "debug", "config", "help", "--version", "-V")
+)
+
+func set(values ...string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// positionalArgs returns the arguments that are not options
+func positionalArgs(args []string) []string {
+	var positional []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+		}
+	}
+	return positional
+}
+
+// subcommandRule classifies by the positional argument at index: commands are read-only
+// only if it is in readOnly. With emptyReadOnly, a missing subcommand is read-only.
+func subcommandRule(index int, readOnlySubcommands map[string]bool, emptyReadOnly bool) func(string, []string) Classification {
+	return func(name string, args []string) Classification {
+		positional := positionalArgs(args)
+		if index >= len(positional) {
+			// Flags like --version are also accepted as subcommand
+			for _, arg := range args {
+				if readOnlySubcommands[arg] {
+					return readOnly
+				}
+			}
+			if emptyReadOnly {
+				return readOnly
+			}
+			return modify(name)
+		}
+		sub := positional[index]
+		if readOnlySubcommands[sub] {
+			return readOnly
+		}
+		return modify(name + " " + sub)
+	}
+}
+
+// subcommandPathRule is like subcommandRule for tools with nested subcommands ("images ls")
+func subcommandPathRule(readOnlyPaths map[string]bool) func(string, []string) Classification {
+	return func(name string, args []string) Classification {
+		positional := positionalArgs(args)
+		if len(positional) >= 1 && readOnlyPaths[positional[0]] {
+			return readOnly
+		}
+		if len(positional) >= 2 && readOnlyPaths[positional[0]+" "+positional[1]] {
+			return readOnly
+		}
+		return modify(name + " " + strings.Join(firstN(positional, 2), " "))
+	}
+}
+
+// classifyListOnly treats the command as read-only only with one of the given flags
+func classifyListOnly(flags ...string) func(string, []string) Classification {
+	readOnlyFlags := set(flags...)
+	return func(name string, args []string) Classification {
+		for _, arg := range args {
+			if readOnlyFlags[arg] {
+				return readOnly
+			}
+		}
+		return modify(strings.TrimSpace(name + " " + strings.Join(firstN(args, 1), " ")))
+	}
+}
+
+// flagsModify treats the command as modifying when any of the given flags is present
+func flagsModify(flags ...string) func(string, []string) Classification {
+	modifyFlags := set(flags...)
+	return func(name string, args []string) Classification {
+		for _, arg := range args {
+			if modifyFlags[arg] {
+				return modify(name + " " + arg)
+			}
+		}
+		return readOnly
+	}
+}
+
+// argsModify treats the command as read-only only without positional arguments
+func argsModify() func(string, []string) Classification {
+	return func(name string, args []string) Classification {
+		if len(positionalArgs(args)) > 0 {
+			return modify(name + " " + positionalArgs(args)[0])
+		}
+		return readOnly
+	}
+}
+
+// classifyInPlace treats perl-like commands as modifying with an in-place flag
+func classifyInPlace(flags ...string) func(string, []string) Classification {
+	return func(name string, args []string) Classification {
+		for _, arg := range args {
+			for _, flag := range flags {
+				if strings.HasPrefix(arg, flag) {
+					return modify(name + " " + flag)
+				}
+			}
+		}
+		return unknown
+	}
+}
+
+func classifySed(name string, args []string) Classification {
+	for _, arg := range args {
+		if arg == "--in-place" || strings.HasPrefix(arg, "--in-place=") {
+			return modify(name + " --in-place")
+		}
+		// -i, -i.bak and combined short flags like -ni or -Ei
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "i") {
+			return modify(name + " -i")
+		}
+	}
+	return readOnly
+}
+
+// awkOptionsWithValue are the awk options taking an argument; the program follows them
+var awkOptionsWithValue = []string{"-F", "-v", "-f", "--field-separator", "--assign", "--file", "-i", "--include", "-l", "--load", "-E", "--exec"}
+
+// awkWriteRe matches awk programs running commands or writing files: system(),
+// print and printf redirected to a file or command, and commands piped to getline
+var awkWriteRe = regexp.MustCompile(`\bsystem\s*\(|\bprintf?\b[^;{}\n]*(>|\|)|\|&?\s*getline\b`)
+
+// awkStringRe matches awk string literals, which may contain > and |
+var awkStringRe = regexp.MustCompile(`"(\\.|[^"\\])*"`)
+
+func classifyAwk(name string, args []string) Classification {
+	for i, arg := range args {
+		// gawk -i inplace edits the input files
+		if (arg == "-i" || arg == "--include") && i+1 < len(args) && strings.TrimSuffix(args[i+1], ".awk") == "inplace" ||
+			arg == "--include=inplace" || arg == "-iinplace" {
+			return modify(name + " -i inplace")
+		}
+		// Programs read from files can't be inspected
+		if arg == "-f" || arg == "--file" || strings.HasPrefix(arg, "--file=") {
+			return unknown
+		}
+	}
+
+	program := skipOptions(args, awkOptionsWithValue)
+	if len(program) == 0 {
+		return readOnly
+	}
+	if m := awkWriteRe.FindString(awkStringRe.ReplaceAllString(program[0], `""`)); m != "" {
+		return modify(name + " " + strings.TrimSpace(m))
+	}
+	return readOnly
+}
+
+func classifyTee(name string, args []string) Classification {
+	for _, arg := range positionalArgs(args) {
+		if !isHarmlessTarget(arg) {
+			return modify(name + " " + arg)
+		}
+	}
+	return readOnly
+}
+
+func classifyCurl(name string, args []string) Classification {
+	for i, arg := range args {
+		switch {
+		case arg == "-X" || arg == "--request":
+			if i+1 < len(args) && !isSafeHTTPMethod(args[i+1]) {
+				return modify(name + " " + arg + " " + args[i+1])
+			}
+		case strings.HasPrefix(arg, "-X") && len(arg) > 2:
+			if !isSafeHTTPMethod(arg[2:]) {
+				return modify(name + " " + arg)
+			}
+		case strings.HasPrefix(arg, "--request="):
+			if !isSafeHTTPMethod(strings.TrimPrefix(arg, "--request=")) {
+				return modify(name + " " + arg)
+			}
+		case arg == "-d" || arg == "-F" || arg == "-T" || strings.HasPrefix(arg, "--data") ||
+			arg == "--form" || arg == "--upload-file" || arg == "--json":
+			return modify(name + " " + arg)
+		case arg == "-o" || arg == "--output":
+			if i+1 < len(args) && !isHarmlessTarget(args[i+1]) && args[i+1] != "-" {
+				return modify(name + " " + arg + " " + args[i+1])
+			}
+		case arg == "-O" || arg == "--remote-name" || arg == "--remote-name-all":
+			return modify(name + " " + arg)
+		}
+	}
+	return readOnly
+}
+
+func isSafeHTTPMethod(method string) bool {
+	switch strings.ToUpper(strings.Trim(method, `"'`)) {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+func classifyWget(name string, args []string) Classification {
+	for i, arg := range args {
+		switch {
+		case arg == "--spider":
+			return readOnly
+		case arg == "-O" || arg == "--output-document":
+			if i+1 < len(args) && (args[i+1] == "-" || isHarmlessTarget(args[i+1])) {
+				return readOnly
+			}
+		case arg == "-O-" || arg == "-qO-" || arg == "-qO" && i+1 < len(args) && args[i+1] == "-":
+			return readOnly
+		}
+	}
+	return modify(name)
+}
+
+// classifyCrontab treats editing, removing and installing a crontab from a file or
+// stdin as modifying; only listing is read-only
+func classifyCrontab(name string, args []string) Classification {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-u":
+			i++ // user name
+		case arg == "-e" || arg == "-r" || arg == "-i" || arg == "-":
+			return modify(name + " " + arg)
+		case !strings.HasPrefix(arg, "-"):
+			return modify(name + " " + arg)
+		}
+	}
+	return readOnly
+}
+
+// classifyDate treats setting the clock with -s or an MMDDhhmm operand as modifying;
+// +FORMAT operands only print
+func classifyDate(name string, args []string) Classification {
+	withValue := set("-d", "--date", "-r", "--reference", "-f", "--file", "-z", "-v")
+	setsNothing := false // BSD date -j doesn't set the clock
+	var operands []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-s" || arg == "--set" || strings.HasPrefix(arg, "--set=") ||
+			strings.HasPrefix(arg, "-s") && !strings.HasPrefix(arg, "--"):
+			return modify(name + " -s")
+		case arg == "-j":
+			setsNothing = true
+		case withValue[arg]:
+			i++
+		case !strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "+"):
+			operands = append(operands, arg)
+		}
+	}
+	if len(operands) > 0 && !setsNothing {
+		return modify(name + " " + operands[0])
+	}
+	return readOnly
+}
+
+// tcpdumpOptionsWithValue are the short tcpdump options taking an argument
+const tcpdumpOptionsWithValue = "BcCDEFGiMrsTVwWyzZj"
+
+// classifyTcpdump treats writing packets to a file with -w as modifying
+func classifyTcpdump(name string, args []string) Classification {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") || len(arg) < 2 {
+			continue
+		}
+		// Clusters like -nnw file or -wfile: an option taking a value ends the cluster
+		for j := 1; j < len(arg); j++ {
+			if !strings.ContainsRune(tcpdumpOptionsWithValue, rune(arg[j])) {
+				continue
+			}
+			value := arg[j+1:]
+			if value == "" && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			if arg[j] == 'w' && !isHarmlessTarget(value) {
+				return modify(name + " -w " + value)
+			}
+			break
+		}
+	}
+	return readOnly
+}
+
+// classifyOpenSSL treats writing keys, certificates or other output to files as modifying
+func classifyOpenSSL(name string, args []string) Classification {
+	for i, arg := range args {
+		if (arg == "-out" || arg == "-keyout") && i+1 < len(args) && !isHarmlessTarget(args[i+1]) {
+			return modify(name + " " + arg + " " + args[i+1])
+		}
+	}
+	return readOnly
+}
+
+func classifyJournalctl(name string, args []string) Classification {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--vacuum") || arg == "--rotate" || arg == "--flush" ||
+			arg == "--relinquish-var" || arg == "--setup-keys" || arg == "--sync" {
+			return modify(name + " " + arg)
+		}
+	}
+	return readOnly
+}
+
+var kubectlReadOnly = set("get", "describe", "logs", "top", "explain", "api-resources", "api-versions",
+	"version", "cluster-info", "events", "diff", "wait", "completion", "plugin", "options", "kustomize")
+
+func classifyKubectl(name string, args []string) Classification {
+	positional := kubectlPositional(args)
+	if len(positional) == 0 {
+		return readOnly
+	}
+
+	sub := positional[0]
+	switch {
+	case kubectlReadOnly[sub]:
+		return readOnly
+	case sub == "config":
+		if len(positional) > 1 && set("view", "get-contexts", "current-context", "get-clusters", "get-users")[positional[1]] {
+			return readOnly
+		}
+	case sub == "auth":
+		if len(positional) > 1 && (positional[1] == "can-i" || positional[1] == "whoami") {
+			return readOnly
+		}
+	case sub == "rollout":
+		if len(positional) > 1 && (positional[1] == "status" || positional[1] == "history") {
+			return readOnly
+		}
+	}
+	return modify(name + " " + strings.Join(firstN(positional, 2), " "))
+}
+
+// kubectlPositional drops global flags including the values of -n/--namespace/--context etc.
+func kubectlPositional(args []string) []string {
+	withValue := set("-n", "--namespace", "--context", "--cluster", "--kubeconfig", "-l", "--selector",
+		"-o", "--output", "-c", "--container", "--user", "-s", "--server", "--field-selector", "--since", "--tail")
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			if withValue[arg] {
+				i++
+			}
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	return positional
+}
+
+var dockerReadOnly = set("ps", "images", "inspect", "logs", "stats", "top", "version", "info", "history",
+	"port", "diff", "events", "search", "--version", "-v")
+
+var dockerObjectReadOnly = set("ls", "list", "inspect", "logs", "ps", "history", "df", "info", "top",
+	"port", "stats", "config", "events", "diff", "images", "version")
+
+func classifyDocker(name string, args []string) Classification {
+	positional := kubectlPositional(args)
+	if len(positional) == 0 {
+		return readOnly
+	}
+
+	sub := positional[0]
+	if dockerReadOnly[sub] {
+		return readOnly
+	}
+	switch sub {
+	case "container", "image", "network", "volume", "system", "compose", "node", "service", "stack",
+		"secret", "config", "context", "plugin", "buildx", "builder", "manifest", "pod", "machine":
+		if len(positional) > 1 && dockerObjectReadOnly[positional[1]] {
+			return readOnly
+		}
+	}
+	return modify(name + " " + strings.Join(firstN(positional, 2), " "))
+}
+
+func classifyHelm(name string, args []string) Classification {
+	positional := kubectlPositional(args)
+	if len(positional) == 0 {
+		return readOnly
+	}
+	sub := positional[0]
+	if set("list", "ls", "status", "get", "history", "hist", "show", "inspect", "search", "template",
+		"version", "lint", "env", "verify", "dependency")[sub] {
+		if sub == "dependency" && (len(positional) < 2 || positional[1] != "list") {
+			return modify(name + " " + sub)
+		}
+		return readOnly
+	}
+	if (sub == "repo" || sub == "plugin") && len(positional) > 1 && positional[1] == "list" {
+		return readOnly
+	}
+	return modify(name + " " + strings.Join(firstN(positional, 2), " "))
+}
+
+func classifyGit(name string, args []string) Classification {
+	positional := kubectlPositional(args)
+	if len(positional) == 0 {
+		return readOnly
+	}
+	sub := positional[0]
+	switch {
+	case set("status", "log", "diff", "show", "blame", "grep", "ls-files", "ls-remote", "ls-tree",
+		"rev-parse", "describe", "shortlog", "reflog", "cat-file", "whatchanged", "version", "help")[sub]:
+		return readOnly
+	case sub == "branch" || sub == "tag" || sub == "remote" || sub == "stash":
+		// Listing forms only, e.g. "git branch -a", "git remote -v", "git stash list"
+		if len(positional) == 1 || positional[1] == "list" || positional[1] == "show" {
+			for _, arg := range args {
+				if set("-d", "-D", "-m", "-M", "--delete", "--move")[arg] {
+					return modify(name + " " + sub + " " + arg)
+				}
+			}
+			return readOnly
+		}
+	case sub == "config":
+		for _, arg := range args {
+			if set("--get", "--get-all", "--list", "-l", "--get-regexp")[arg] {
+				return readOnly
+			}
+		}
+	}
+	return modify(name + " " + sub)
+}
+
+func classifyIP(name string, args []string) Classification {
+	positional := positionalArgs(args)
+	if len(positional) == 0 {
+		return readOnly
+	}
+	// "ip addr", "ip route", "ip -s link" etc. default to show
+	if len(positional) == 1 {
+		return readOnly
+	}
+	if set("show", "list", "ls", "lst", "get", "sh", "s")[positional[1]] {
+		return readOnly
+	}
+	if positional[0] == "monitor" || positional[0] == "mon" {
+		return readOnly
+	}
+	return modify(name + " " + positional[0] + " " + positional[1])
+}
+
+func classifyNft(name string, args []string) Classification {
+	positional := positionalArgs(args)
+	if len(positional) > 0 && (positional[0] == "list" || positional[0] == "describe" || positional[0] == "monitor") {
+		return readOnly
+	}
+	return modify(strings.TrimSpace(name + " " + strings.Join(firstN(positional, 1), " ")))
+}
+
+func classifySysctl(name string, args []string) Classification {
+	for _, arg := range args {
+		if arg == "-w" || arg == "--write" || arg == "-p" || arg == "--load" || arg == "--system" ||
+			(!strings.HasPrefix(arg, "-") && strings.Contains(arg, "=")) {
+			return modify(name + " " + arg)
+		}
+	}
+	return readOnly
+}
+
+func classifyIfconfig(name string, args []string) Classification {
+	if len(positionalArgs(args)) <= 1 {
+		return readOnly
+	}
+	return modify(name + " " + strings.Join(args, " "))
+}
+
+func classifyTar(name string, args []string) Classification {
+	for _, arg := range args {
+		if arg == "--list" {
+			return readOnly
+		}
+		// -tvf, tvf, -tzf ...
+		trimmed := strings.TrimPrefix(arg, "-")
+		if !strings.HasPrefix(arg, "--") && strings.Contains(trimmed, "t") && !strings.ContainsAny(trimmed, "xcru") {
+			return readOnly
+		}
+	}
+	return modify(name)
+}
+
+func classifyNmcli(name string, args []string) Classification {
+	positional := positionalArgs(args)
+	if len(positional) == 0 {
+		return readOnly
+	}
+	if len(positional) == 1 || set("show", "status", "list", "monitor")[positional[1]] ||
+		positional[0] == "general" && positional[1] == "status" {
+		return readOnly
+	}
+	return modify(name + " " + positional[0] + " " + positional[1])
+}
+
+// classifyFind handles find actions that delete files or run other commands
+func classifyFind(args []string, depth int) Classification {
+	result := readOnly
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-delete":
+			return modify("find -delete")
+		case "-fprint", "-fprint0", "-fprintf", "-fls":
+			return modify("find " + args[i])
+		case "-exec", "-execdir", "-ok", "-okdir":
+			var inner []string
+			for i++; i < len(args) && args[i] != ";" && args[i] != `\;` && args[i] != "+"; i++ {
+				inner = append(inner, args[i])
+			}
+			c := classifyWords(inner, depth)
+			if c.Type == ModifyCommand {
+				return c
+			}
+			if !c.Known {
+				result = unknown
+			}
+		}
+	}
+	return result
+}
+
+func firstN(values []string, n int) []string {
+	if len(values) > n {
+		return values[:n]
+	}
+	return values
+}
+
+// isOutputRedirect reports whether a redirection operator writes to its target
+func isOutputRedirect(op string) bool {
+	return strings.Contains(op, ">") && op != ">&" && op != "<>" || op == "&>" || op == "&>>"
+}
+
+// isHarmlessTarget reports whether writing to target has no lasting effect
+func isHarmlessTarget(target string) bool {
+	switch target {
+	case "/dev/null", "/dev/stdout", "/dev/stderr", "/dev/tty", "-":
+		return true
+	}
+	// Duplicating file descriptors, e.g. 2>&1
+	return strings.HasPrefix(target, "&")
+}
+
+// shellSegment is a simple command with its redirections
+type shellSegment struct {
+	words     []string
+	redirects []shellRedirect
+}
+
+type shellRedirect struct {
+	op     string
+	target string
+}
+
+// lexShell splits a command line into simple commands at |, ||, &&, ;, &, newlines and
+// parentheses, honoring quotes and escapes. Contents of $(...) and `...` substitutions
+// are returned separately so they can be classified too.
+func lexShell(line string) ([]shellSegment, []string) {
+	var (
+		segments      []shellSegment
+		substitutions []string
+		current       shellSegment
+		word          strings.Builder
+		inWord        bool
+		redirectOp    string // pending redirection waiting for its target
+	)
+
+	endWord := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		inWord = false
+		if redirectOp != "" {
+			current.redirects = append(current.redirects, shellRedirect{op: redirectOp, target: w})
+			redirectOp = ""
+			return
+		}
+		current.words = append(current.words, w)
+	}
+	endSegment := func() {
+		endWord()
+		if redirectOp != "" {
+			current.redirects = append(current.redirects, shellRedirect{op: redirectOp})
+			redirectOp = ""
+		}
+		if len(current.words) > 0 || len(current.redirects) > 0 {
+			segments = append(segments, current)
+		}
+		current = shellSegment{}
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+
+		case r == '\'':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+
+		case r == '"':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				switch {
+				case runes[i] == '\\' && i+1 < len(runes):
+					i++
+					word.WriteRune(runes[i])
+				case runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '(':
+					end := matchParen(runes, i+1)
+					substitutions = append(substitutions, string(runes[i+2:end]))
+					word.WriteString("$(...)")
+					i = end
+				case runes[i] == '`':
+					end := indexRune(runes, i+1, '`')
+					substitutions = append(substitutions, string(runes[i+1:end]))
+					i = end
+				default:
+					word.WriteRune(runes[i])
+				}
+			}
+
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '(':
+			end := matchParen(runes, i+1)
+			substitutions = append(substitutions, string(runes[i+2:end]))
+			word.WriteString("$(...)")
+			inWord = true
+			i = end
+
+		case r == '`':
+			end := indexRune(runes, i+1, '`')
+			substitutions = append(substitutions, string(runes[i+1:end]))
+			inWord = true
+			i = end
+
+		case r == '#' && !inWord:
+			// Comment until end of line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			endSegment()
+
+		case r == '>' || r == '<' || (r == '&' && i+1 < len(runes) && runes[i+1] == '>'):
+			// A word made only of digits before the operator is a file descriptor
+			if inWord && isDigits(word.String()) {
+				word.Reset()
+				inWord = false
+			} else {
+				endWord()
+			}
+			op := string(r)
+			for i+1 < len(runes) && strings.ContainsRune("<>&|", runes[i+1]) && len(op) < 3 {
+				// Stop before the & of 2>&1 style duplication, which is the target
+				if runes[i+1] == '&' && op != "&" {
+					break
+				}
+				i++
+				op += string(runes[i])
+			}
+			// Descriptor duplication like 2>&1 or >&-
+			if i+1 < len(runes) && runes[i+1] == '&' && op != "&" {
+				target := "&"
+				for i++; i+1 < len(runes) && (isDigits(string(runes[i+1])) || runes[i+1] == '-'); i++ {
+					target += string(runes[i+1])
+				}
+				current.redirects = append(current.redirects, shellRedirect{op: op, target: target})
+				continue
+			}
+			if op == "<<" || op == "<<<" || op == "<<-" {
+				redirectOp = "<<"
+			} else {
+				redirectOp = op
+			}
+
+		case r == '|' || r == ';' || r == '&' || r == '\n' || r == '(' || r == ')' || (r == '{' || r == '}') && !inWord && isGroupBrace(runes, i):
+			endSegment()
+
+		case r == ' ' || r == '\t':
+			endWord()
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endSegment()
+
+	return segments, substitutions
+}
+
+// matchParen returns the index of the parenthesis closing the one at open
+func matchParen(runes []rune, open int) int {
+	depth := 0
+	for i := open; i < len(runes); i++ {
+		switch runes[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '\'':
+			i = indexRune(runes, i+1, '\'')
+		}
+	}
+	return len(runes)
+}
+
+// indexRune returns the index of r at or after start, or len(runes)
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// isGroupBrace reports whether the brace at i delimits a { ...; } group rather
+// than starting a word like find's {}
+func isGroupBrace(runes []rune, i int) bool {
+	return i+1 == len(runes) || strings.ContainsRune(" \t\n;|&)", runes[i+1])
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
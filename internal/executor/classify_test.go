@@ -0,0 +1,216 @@
+package executor
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		command string
+		want    CommandType
+		known   bool
+	}{
+		// --- Read-only commands and subcommands ---
+		{"ls -la /var/log", QueryCommand, true},
+		{"kubectl get pods -n kube-system", QueryCommand, true},
+		{"kubectl -n prod describe deploy web", QueryCommand, true},
+		{"kubectl logs -f web-0 --tail 100", QueryCommand, true},
+		{"kubectl rollout status deploy/web", QueryCommand, true},
+		{"kubectl auth can-i --list", QueryCommand, true},
+		{"docker ps -a", QueryCommand, true},
+		{"docker container ls", QueryCommand, true},
+		{"docker logs --tail 50 nginx", QueryCommand, true},
+		{"systemctl status nginx", QueryCommand, true},
+		{"systemctl", QueryCommand, true},
+		{"systemctl is-active --quiet nginx", QueryCommand, true},
+		{"journalctl -u nginx --since today", QueryCommand, true},
+		{"git status", QueryCommand, true},
+		{"git branch -a", QueryCommand, true},
+		{"ip addr show", QueryCommand, true},
+		{"ip route", QueryCommand, true},
+		{"iptables -nL", QueryCommand, true},
+		{"apt list --installed", QueryCommand, true},
+		{"rpm -qa", QueryCommand, true},
+		{"curl -s http://localhost:8080/health", QueryCommand, true},
+		{"curl -X GET http://localhost/api", QueryCommand, true},
+		{"wget -qO- http://localhost/health", QueryCommand, true},
+		{"tar -tzf backup.tar.gz", QueryCommand, true},
+		{"sed -n '1,20p' /etc/nginx/nginx.conf", QueryCommand, true},
+		{"sysctl net.ipv4.ip_forward", QueryCommand, true},
+		{"date", QueryCommand, true},
+		{"date +%s", QueryCommand, true},
+		{"date -d '2 days ago' +%F", QueryCommand, true},
+		{"date -u -r /var/log/syslog", QueryCommand, true},
+		{"date -j -f %Y%m%d 20240501 +%s", QueryCommand, true},
+		{"tcpdump -i eth0 -nn port 80", QueryCommand, true},
+		{"tcpdump -iwlan0 -c 10", QueryCommand, true},
+		{"tcpdump -w - -i eth0", QueryCommand, true},
+		{"openssl x509 -in cert.pem -noout -text", QueryCommand, true},
+		{"openssl s_client -connect example.com:443", QueryCommand, true},
+		{"openssl rsa -in key.pem -out /dev/stdout", QueryCommand, true},
+		{"awk -F: '{print $1}' /etc/passwd", QueryCommand, true},
+		{"awk '$3 > 100 {print $1}' data", QueryCommand, true},
+		{"awk -v OFS='|' '{print $1 \"|\" $2 \" > \" $3}' data", QueryCommand, true},
+		{"gawk 'BEGIN { while ((getline line < \"/etc/hosts\") > 0) n++; print n }'", QueryCommand, true},
+		{"crontab -l", QueryCommand, true},
+		{"crontab -u www -l", QueryCommand, true},
+
+		// --- Pipelines, lists and redirections that stay read-only ---
+		{"ps aux | grep nginx | head -5", QueryCommand, true},
+		{"df -h && free -m; uptime", QueryCommand, true},
+		{"cat /etc/hosts 2>/dev/null || echo missing", QueryCommand, true},
+		{"journalctl -u kubelet 2>&1 | tail -n 50", QueryCommand, true},
+		{"du -sh /var/* > /dev/null", QueryCommand, true},
+		{"ls | tee /dev/stderr", QueryCommand, true},
+		{"echo 'rm -rf / ; > /etc/passwd'", QueryCommand, true},
+		{"grep -c '>' file.txt", QueryCommand, true},
+		{"sudo cat /var/log/secure", QueryCommand, true},
+		{"LANG=C sudo -u www env FOO=1 ls", QueryCommand, true},
+		{"timeout 5 ping -c 3 8.8.8.8", QueryCommand, true},
+		{"watch -d -n 1 kubectl get pods", QueryCommand, true},
+		{"watch 'df -h | grep sda'", QueryCommand, true},
+		{"find /var/log -name '*.log' -mtime +7", QueryCommand, true},
+		{"find / -name core -exec ls -l {} \\;", QueryCommand, true},
+		{"sh -c 'ps aux | wc -l'", QueryCommand, true},
+		{"ls | xargs wc -l", QueryCommand, true},
+		{"echo $(hostname)", QueryCommand, true},
+		{"cat <<EOF", QueryCommand, true},
+
+		// --- Modifying commands ---
+		{"rm -rf /tmp/cache", ModifyCommand, true},
+		{"sudo rm /var/log/old.log", ModifyCommand, true},
+		{"mkfs.ext4 /dev/sdb1", ModifyCommand, true},
+		{"kubectl delete pod web-0", ModifyCommand, true},
+		{"kubectl -n prod scale deploy web --replicas=3", ModifyCommand, true},
+		{"kubectl rollout restart deploy/web", ModifyCommand, true},
+		{"kubectl exec -it web-0 -- sh", ModifyCommand, true},
+		{"docker restart nginx", ModifyCommand, true},
+		{"docker system prune -af", ModifyCommand, true},
+		{"systemctl restart nginx", ModifyCommand, true},
+		{"service nginx reload", ModifyCommand, true},
+		{"journalctl --vacuum-time=2d", ModifyCommand, true},
+		{"git pull", ModifyCommand, true},
+		{"git branch -D feature", ModifyCommand, true},
+		{"ip link set eth0 down", ModifyCommand, true},
+		{"iptables -A INPUT -p tcp --dport 22 -j ACCEPT", ModifyCommand, true},
+		{"sysctl -w net.ipv4.ip_forward=1", ModifyCommand, true},
+		{"apt-get install -y nginx", ModifyCommand, true},
+		{"yum update", ModifyCommand, true},
+		{"pip install requests", ModifyCommand, true},
+		{"curl -X POST http://localhost/api/reload", ModifyCommand, true},
+		{"curl -d '{}' http://localhost/api", ModifyCommand, true},
+		{"curl -o /tmp/x.sh http://example.com/x.sh", ModifyCommand, true},
+		{"wget http://example.com/file.tar.gz", ModifyCommand, true},
+		{"sed -i 's/a/b/' /etc/nginx/nginx.conf", ModifyCommand, true},
+		{"sed -i.bak 's/a/b/' file", ModifyCommand, true},
+		{"sed --in-place=.bak 's/a/b/' file", ModifyCommand, true},
+		{"perl -pi -e 's/a/b/' file", ModifyCommand, true},
+		{"tar -xzf backup.tar.gz", ModifyCommand, true},
+		{"date -s '2024-05-01 12:00'", ModifyCommand, true},
+		{"date --set=12:00", ModifyCommand, true},
+		{"date 050112002024", ModifyCommand, true},
+		{"tcpdump -i eth0 -w /tmp/capture.pcap", ModifyCommand, true},
+		{"tcpdump -nnw capture.pcap port 53", ModifyCommand, true},
+		{"tcpdump -wcapture.pcap", ModifyCommand, true},
+		{"openssl req -new -x509 -keyout key.pem -out cert.pem", ModifyCommand, true},
+		{"openssl genrsa -out key.pem 2048", ModifyCommand, true},
+		{"awk '{system(\"rm \" $1)}' files.txt", ModifyCommand, true},
+		{"awk '{print > \"/tmp/out\"}' data", ModifyCommand, true},
+		{"awk '{print $2 >> $1}' data", ModifyCommand, true},
+		{"awk '{print | \"sh\"}' cmds", ModifyCommand, true},
+		{"awk 'BEGIN { \"id -u\" | getline uid }'", ModifyCommand, true},
+		{"gawk -i inplace '{sub(/a/, \"b\")} 1' file", ModifyCommand, true},
+		{"crontab -e", ModifyCommand, true},
+		{"crontab /tmp/jobs", ModifyCommand, true},
+		{"crontab -u www /tmp/jobs", ModifyCommand, true},
+		{"echo '* * * * * true' | crontab -", ModifyCommand, true},
+
+		// --- Modifications hidden in pipelines, lists and wrappers ---
+		{"echo 1 > /proc/sys/net/ipv4/ip_forward", ModifyCommand, true},
+		{"echo test >> /etc/hosts", ModifyCommand, true},
+		{"ls 2> errors.log", ModifyCommand, true},
+		{"ls &> out.log", ModifyCommand, true},
+		{"echo 'nameserver 8.8.8.8' | sudo tee -a /etc/resolv.conf", ModifyCommand, true},
+		{"df -h && rm -rf /tmp/x", ModifyCommand, true},
+		{"ps aux; kill -9 1234", ModifyCommand, true},
+		{"find /tmp -name '*.tmp' -delete", ModifyCommand, true},
+		{"find /tmp -name '*.tmp' -exec rm {} \\;", ModifyCommand, true},
+		{"ls | xargs rm", ModifyCommand, true},
+		{"bash -c 'systemctl stop nginx'", ModifyCommand, true},
+		{"nohup systemctl restart nginx", ModifyCommand, true},
+		{"watch -d -n 1 kubectl delete pod x", ModifyCommand, true},
+		{"watch 'kubectl delete pod x'", ModifyCommand, true},
+		{"timeout 10 'rm -rf /tmp/x'", ModifyCommand, true},
+		{"echo $(rm -rf /tmp/x)", ModifyCommand, true},
+		{"echo \"$(touch /tmp/x)\"", ModifyCommand, true},
+		{"(cd /tmp && rm x)", ModifyCommand, true},
+
+		// --- Unknown commands keep the model's tag ---
+		{"./check.sh", QueryCommand, false},
+		{"python3 script.py", QueryCommand, false},
+		{"ls | custom-tool", QueryCommand, false},
+		{"awk -f report.awk data", QueryCommand, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := Classify(tt.command)
+			if got.Type != tt.want {
+				t.Errorf("Classify(%q).Type = %v, want %v (reason %q)", tt.command, got.Type, tt.want, got.Reason)
+			}
+			if got.Known != tt.known {
+				t.Errorf("Classify(%q).Known = %v, want %v", tt.command, got.Known, tt.known)
+			}
+			if got.Type == ModifyCommand && got.Reason == "" {
+				t.Errorf("Classify(%q) returned modify without a reason", tt.command)
+			}
+		})
+	}
+}
+
+func TestClassifyReason(t *testing.T) {
+	tests := []struct {
+		command string
+		reason  string
+	}{
+		{"echo 1 > /proc/sys/vm/drop_caches", "> /proc/sys/vm/drop_caches"},
+		{"sudo sed -i 's/a/b/' f", "sed -i"},
+		{"kubectl -n prod delete pod web-0", "kubectl delete pod"},
+		{"systemctl restart nginx", "systemctl restart"},
+		{"cat x | tee out.txt", "tee out.txt"},
+		{"sudo date -s 12:00", "date -s"},
+		{"tcpdump -nnw capture.pcap", "tcpdump -w capture.pcap"},
+		{"openssl genrsa -out key.pem", "openssl -out key.pem"},
+		{"crontab -u www /tmp/jobs", "crontab /tmp/jobs"},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.command).Reason; got != tt.reason {
+			t.Errorf("Classify(%q).Reason = %q, want %q", tt.command, got, tt.reason)
+		}
+	}
+}
+
+func TestNewCommand(t *testing.T) {
+	// A query command that modifies is upgraded
+	cmd := NewCommand("systemctl restart nginx", QueryCommand)
+	if cmd.Type != ModifyCommand || !cmd.Upgraded() || cmd.LocallyReadOnly() {
+		t.Errorf("expected upgrade to modify, got %+v", cmd)
+	}
+
+	// A modify command is never downgraded, but the disagreement is reported
+	cmd = NewCommand("systemctl status nginx", ModifyCommand)
+	if cmd.Type != ModifyCommand || cmd.Upgraded() || !cmd.LocallyReadOnly() {
+		t.Errorf("expected modify kept with read-only note, got %+v", cmd)
+	}
+
+	// Unknown commands keep the model's tag without a disagreement
+	cmd = NewCommand("./deploy.sh", ModifyCommand)
+	if cmd.Type != ModifyCommand || cmd.Upgraded() || cmd.LocallyReadOnly() {
+		t.Errorf("expected model tag kept, got %+v", cmd)
+	}
+	cmd = NewCommand("./status.sh", QueryCommand)
+	if cmd.Type != QueryCommand || cmd.Upgraded() {
+		t.Errorf("expected model tag kept, got %+v", cmd)
+	}
+}
@@ -1,12 +1,16 @@
 package executor
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/llaoj/aiassist/internal/blacklist"
+	"github.com/llaoj/aiassist/internal/config"
 	"github.com/llaoj/aiassist/internal/i18n"
 )
 
@@ -18,20 +22,71 @@ const (
 	ModifyCommand                    // Modify command (write operations, high risk)
 )
 
+// String returns the marker name of the command type: query or modify
+func (t CommandType) String() string {
+	if t == ModifyCommand {
+		return "modify"
+	}
+	return "query"
+}
+
 // Command represents a command with its type
 type Command struct {
 	Text string
 	Type CommandType
+	// ModelType is the type tagged by the model. Type differs from it when the
+	// local classifier upgraded a query command to modify.
+	ModelType CommandType
+	// Local is the local static classification of Text
+	Local Classification
+}
+
+// Upgraded reports whether the model tagged the command as query but it was
+// classified locally as modify
+func (c Command) Upgraded() bool {
+	return c.ModelType == QueryCommand && c.Type == ModifyCommand
 }
 
+// LocallyReadOnly reports whether the model tagged the command as modify although
+// it was classified locally as read-only. The command stays a modify command.
+func (c Command) LocallyReadOnly() bool {
+	return c.ModelType == ModifyCommand && c.Local.Known && c.Local.Type == QueryCommand
+}
+
+// NewCommand creates a command tagged by the model, upgrading it to modify when the
+// local classifier considers it modifying. Modify commands are never downgraded.
+func NewCommand(text string, modelType CommandType) Command {
+	local := Classify(text)
+	cmdType := modelType
+	if local.Type == ModifyCommand {
+		cmdType = ModifyCommand
+	}
+	return Command{Text: text, Type: cmdType, ModelType: modelType, Local: local}
+}
+
+// probeTimeout bounds local probe scripts, see probe
+const probeTimeout = 5 * time.Second
+
 // CommandExecutor handles command extraction and execution
 type CommandExecutor struct {
 	blacklistChecker *blacklist.Checker
+	initialState     *ShellState       // State when the session started
+	state            *ShellState       // State after the last executed command
+	pty              bool              // Run commands in a pseudo-terminal
+	terminalWidth    int               // Terminal columns in PTY mode
+	runner           Runner            // Runs commands on a remote host, nil to run locally
+	sentinel         string            // Marks the shell state in remote output
+	helpCache        map[string]string // Help of tools by target and name, see CheckFlags
 }
 
 func NewCommandExecutor() *CommandExecutor {
+	state := newShellState()
 	return &CommandExecutor{
 		blacklistChecker: blacklist.NewChecker(),
+		initialState:     state,
+		state:            state,
+		pty:              config.Get().PTYEnabled(),
+		terminalWidth:    config.Get().GetTerminalWidth(),
 	}
 }
 
@@ -56,20 +111,97 @@ func (ce *CommandExecutor) DisplayCommand(cmdText string, cmdType CommandType, t
 	fmt.Println()
 }
 
+// DisplayClassificationWarning warns when the model's tag and the local classification disagree
+func (ce *CommandExecutor) DisplayClassificationWarning(cmd Command, translator *i18n.I18n) {
+	switch {
+	case cmd.Upgraded():
+		color.Yellow(translator.T("executor.classified_modify", cmd.Local.Reason) + "\n")
+	case cmd.LocallyReadOnly():
+		color.HiBlack(translator.T("executor.classified_readonly") + "\n")
+	}
+}
+
 // IsBlacklisted checks if a command is in the blacklist
 func (ce *CommandExecutor) IsBlacklisted(cmdText string) (bool, string) {
 	return ce.blacklistChecker.IsBlacklisted(cmdText)
 }
 
 // ExecuteCommand executes the command and returns the output
-// Note: Output is not printed here, caller should print it after spinner stops
+// Note: Output is not printed here, caller should print it after spinner stops.
+// In PTY mode the output may contain escape sequences, see CleanTerminalOutput.
+//
+// The command runs in the working directory and environment left by the previous
+// command, and the resulting state is kept for the next one.
 func (ce *CommandExecutor) ExecuteCommand(command string) (string, error) {
-	cmd := exec.Command("sh", "-c", command)
-	output, err := cmd.CombinedOutput()
+	if ce.runner != nil {
+		return ce.executeRemote(command)
+	}
+
+	stateDir, err := os.MkdirTemp("", "aiassist-state-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create shell state directory: %w", err)
+	}
+	defer os.RemoveAll(stateDir)
+
+	cmd := exec.Command("sh", "-c", stateTrap(stateDir)+"\n"+command)
+	cmd.Env = ce.state.environ()
+	if info, err := os.Stat(ce.state.Dir); err == nil && info.IsDir() {
+		cmd.Dir = ce.state.Dir
+	}
+
+	var output string
+	var ptyVars []string
+	if ce.pty {
+		ptyVars = ptyEnviron(cmd.Env, ce.terminalWidth)
+		cmd.Env = append(cmd.Env, ptyVars...)
+		output, err = executePTY(cmd, ce.terminalWidth)
+	} else {
+		var out []byte
+		out, err = cmd.CombinedOutput()
+		output = string(out)
+	}
+
+	if state := loadShellState(stateDir); state != nil {
+		state.restoreEnv(ce.state, ptyVars)
+		ce.state = state
+	}
 	// Caller can decide whether to treat non-zero exit as error
+	return output, err
+}
+
+// probe runs a read-only script where commands run, in the current shell state,
+// and returns its standard output. The shell state is left unchanged.
+func (ce *CommandExecutor) probe(script string) (string, error) {
+	if ce.runner != nil {
+		return ce.runner.Run(ce.replayState()+script, 0)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Env = ce.state.environ()
+	if info, err := os.Stat(ce.state.Dir); err == nil && info.IsDir() {
+		cmd.Dir = ce.state.Dir
+	}
+	output, err := cmd.Output()
 	return string(output), err
 }
 
+// WorkingDir returns the working directory commands currently run in
+func (ce *CommandExecutor) WorkingDir() string {
+	return ce.state.Dir
+}
+
+// ShellContext formats the current shell state as context string for LLM
+func (ce *CommandExecutor) ShellContext() string {
+	context := ce.state.FormatAsContext(ce.initialState)
+	if target := ce.Target(); target != "" {
+		context = strings.Replace(context, "\n", fmt.Sprintf("\nHost: %s\n", target), 1)
+	}
+	return context
+}
+
 // ExtractCommands extracts executable commands from AI response text
 // Looks for [cmd:query] and [cmd:modify] markers and returns commands with their types
 func (ce *CommandExecutor) ExtractCommands(response string) []Command {
@@ -104,10 +236,7 @@ func (ce *CommandExecutor) ExtractCommands(response string) []Command {
 		cmdText = strings.TrimSpace(cmdText)
 
 		if cmdText != "" {
-			commands = append(commands, Command{
-				Text: cmdText,
-				Type: cmdType,
-			})
+			commands = append(commands, NewCommand(cmdText, cmdType))
 		}
 	}
 
@@ -0,0 +1,217 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxHelpExcerpt limits the help of a tool given back to the model
+const maxHelpExcerpt = 3000
+
+// FlagIssue lists the flags of a command that the help of its tool doesn't document
+type FlagIssue struct {
+	Tool  string
+	Flags []string
+	Help  string // Usage and option lines of the help, for the model to correct the command
+}
+
+// skipFlagCheck lists commands whose flags aren't checked: shell builtins, tools
+// whose options depend on their subcommand, and commands running other commands
+var skipFlagCheck = set(
+	"cd", "echo", "printf", "export", "source", ".", "set", "unset", "alias", "test", "[", "[[",
+	"read", "exit", "true", "false", "eval", "exec", "trap", "ulimit", "umask", "type", "hash",
+	"wait", "kill", "history", "jobs", "fg", "bg", "let", "local", "declare", "shift", "return",
+	"for", "while", "until", "if", "case", "do", "done", "then", "else", "fi", "esac", "function",
+	"git", "kubectl", "docker", "podman", "nerdctl", "crictl", "ctr", "helm", "systemctl",
+	"apt", "apt-get", "apt-cache", "yum", "dnf", "zypper", "brew", "snap", "ip", "go", "npm",
+	"pip", "pip3", "cargo", "aws", "gcloud", "az", "terraform", "openssl", "launchctl", "service",
+	"virsh", "consul", "vault", "etcdctl", "nmcli", "redis-cli",
+	"sh", "bash", "zsh", "dash", "ksh", "python", "python3", "perl", "ruby", "node", "java",
+	"xargs", "find",
+)
+
+// noHelpRun lists read-only commands that must not be run with --help to read
+// their options, as they may be interactive, loop or wait
+var noHelpRun = set("yes", "top", "htop", "atop", "iotop", "iftop", "nethogs", "less", "more",
+	"man", "mtr", "tcpdump", "ping", "ping6", "sleep", "cat", "tac")
+
+// helpArgs are the arguments printing the full help of tools whose --help is a summary
+var helpArgs = map[string]string{
+	"ps": "--help all", // procps
+}
+
+// minOptionLines is the number of option lines help needs to be trusted
+const minOptionLines = 2
+
+// helpFlags are accepted for every tool
+var helpFlags = set("-h", "--help", "--version")
+
+// CheckFlags checks the flags of each command in command against the man page,
+// or the --help output, of its tool where commands run. Tools without help and
+// tools whose options depend on a subcommand are not checked. Help is cached for
+// the session.
+func (ce *CommandExecutor) CheckFlags(command string) []FlagIssue {
+	var issues []FlagIssue
+	segments, _ := lexShell(command)
+	for _, seg := range segments {
+		words := stripWrappers(seg.words)
+		if len(words) < 2 {
+			continue
+		}
+		tool := filepath.Base(words[0])
+		if skipFlagCheck[tool] || !toolNameRe.MatchString(tool) {
+			continue
+		}
+		flags := commandFlags(words[1:])
+		if len(flags) == 0 {
+			continue
+		}
+
+		help := ce.toolHelp(tool)
+		if help == "" {
+			continue
+		}
+		var unknown []string
+		for _, flag := range flags {
+			if !flagDocumented(help, flag) && !slices.Contains(unknown, flag) {
+				unknown = append(unknown, flag)
+			}
+		}
+		if len(unknown) > 0 {
+			issues = append(issues, FlagIssue{Tool: tool, Flags: unknown, Help: helpExcerpt(help)})
+		}
+	}
+	return issues
+}
+
+// toolNameRe matches tool names that are safe to put in a script unquoted
+var toolNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// commandFlags returns the options among args, without their values
+func commandFlags(args []string) []string {
+	var flags []string
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' || unicode.IsDigit(rune(arg[1])) {
+			continue
+		}
+		if strings.HasPrefix(arg, "--") {
+			name, _, _ := strings.Cut(arg, "=")
+			if len(name) > 2 {
+				flags = append(flags, name)
+			}
+			continue
+		}
+		flags = append(flags, arg)
+	}
+	return flags
+}
+
+// flagDocumented reports whether help documents flag. A cluster of short options
+// like -tlnp is documented if the whole word or each of its letters is; letters
+// may also appear in the option lists of BSD synopses, like [-AaCcdFf].
+func flagDocumented(help, flag string) bool {
+	if helpFlags[flag] || containsFlag(help, flag) {
+		return true
+	}
+	if strings.HasPrefix(flag, "--") {
+		// Negated forms like --[no-]color
+		return strings.Contains(help, "--[no-]"+strings.TrimPrefix(flag[2:], "no-"))
+	}
+
+	var options strings.Builder
+	for _, m := range shortOptionsRe.FindAllStringSubmatch(help, -1) {
+		options.WriteString(m[1])
+	}
+	for _, r := range flag[1:] {
+		if !unicode.IsLetter(r) {
+			break // The value of the last option, as in -n5 or -t:
+		}
+		if !strings.ContainsRune(options.String(), r) {
+			return false
+		}
+	}
+	return true
+}
+
+// shortOptionsRe matches the letters of short options and option lists in help
+var shortOptionsRe = regexp.MustCompile(`(?:^|[^\w-])-([\w@%]+)`)
+
+// containsFlag reports whether help contains flag as a whole word
+func containsFlag(help, flag string) bool {
+	for i := 0; ; {
+		j := strings.Index(help[i:], flag)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(flag)
+		if (start == 0 || !isWordByte(help[start-1])) && (end == len(help) || !isWordByte(help[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// isWordByte reports whether b continues a flag, like \w and - in a regexp
+func isWordByte(b byte) bool {
+	return b == '-' || b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// toolHelp returns the man page of tool where commands run, else its --help
+// output for tools that are read-only with --help, or "" if there is neither or
+// it lists no options
+func (ce *CommandExecutor) toolHelp(tool string) string {
+	key := ce.Target() + "\x00" + tool
+	if help, ok := ce.helpCache[key]; ok {
+		return help
+	}
+
+	script := fmt.Sprintf(`command -v %[1]s >/dev/null 2>&1 || exit 0
+if man -w %[1]s >/dev/null 2>&1; then MANPAGER=cat MANWIDTH=100 man %[1]s 2>/dev/null | col -b 2>/dev/null; exit 0; fi`, tool)
+	// --help is only run where it is classified as read-only
+	if c := Classify(tool + " --help"); c.Known && c.Type == QueryCommand && !noHelpRun[tool] {
+		args, ok := helpArgs[tool]
+		if !ok {
+			args = "--help"
+		}
+		script += fmt.Sprintf("\n%s %s 2>&1 </dev/null | head -c 200000", tool, args)
+	}
+	help, _ := ce.probe(script)
+	if len(optionLineRe.FindAllString(help, minOptionLines)) < minOptionLines {
+		help = ""
+	}
+
+	if ce.helpCache == nil {
+		ce.helpCache = make(map[string]string)
+	}
+	ce.helpCache[key] = help
+	return help
+}
+
+// optionLineRe matches lines of help documenting an option
+var optionLineRe = regexp.MustCompile(`(?m)^\s*-[\w-]`)
+
+// helpExcerpt keeps the usage and option lines of help
+func helpExcerpt(help string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(help, "\n") {
+		trimmed := strings.TrimSpace(line)
+		lower := strings.ToLower(trimmed)
+		if !strings.HasPrefix(trimmed, "-") && !strings.HasPrefix(lower, "usage") && !strings.Contains(lower, " [-") {
+			continue
+		}
+		if sb.Len()+len(trimmed) > maxHelpExcerpt {
+			sb.WriteString("...\n")
+			break
+		}
+		sb.WriteString(trimmed)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const gnuPsHelp = `Usage:
+ ps [options]
+
+ Basic options:
+ -A, -e               all processes
+ -a                   all with tty, except session leaders
+  a                   all with tty, including other users
+ -o, o, --format <format>
+                      user-defined format
+     --sort <spec>    specify sort order
+
+ --help <simple|list|output|threads|misc|all>
+`
+
+// bsdPsHelp is the synopsis of the macOS man page, without long options
+const bsdPsHelp = `SYNOPSIS
+     ps [-AaCcEefhjlMmrSTvwXx] [-O fmt | -o fmt] [-G gid[,gid...]]
+        [-g grp[,grp...]] [-u uid[,uid...]] [-p pid[,pid...]] [-t tty[,tty...]]
+
+     -A      Display information about other users' processes.
+`
+
+func TestCommandFlags(t *testing.T) {
+	got := commandFlags([]string{"-eo", "pid,comm", "--sort=-%mem", "-", "-20", "--", "--not-a-flag"})
+	want := []string{"-eo", "--sort"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commandFlags() = %q, want %q", got, want)
+	}
+}
+
+func TestFlagDocumented(t *testing.T) {
+	tests := []struct {
+		help string
+		flag string
+		want bool
+	}{
+		{gnuPsHelp, "--sort", true},
+		{gnuPsHelp, "-eo", true},
+		{gnuPsHelp, "--format", true},
+		{gnuPsHelp, "--forest", false},
+		{gnuPsHelp, "-Z", false},
+		{gnuPsHelp, "--help", true},
+		{bsdPsHelp, "-eo", true},
+		{bsdPsHelp, "-axo", true},
+		{bsdPsHelp, "--sort", false},
+		{bsdPsHelp, "-K", false},
+		{"  -n, --lines=NUM   output the last NUM lines", "-n5", true},
+		{"  -t SEP   use SEP as field separator", "-t:", true},
+		{"  --[no-]color   colorize", "--no-color", true},
+		{"  -name pattern", "-name", true},
+		{"  --sort-by KEY, --sorted", "--sort", false},
+		{"  --sorted, --sort KEY", "--sort", true},
+	}
+	for _, tt := range tests {
+		if got := flagDocumented(tt.help, tt.flag); got != tt.want {
+			t.Errorf("flagDocumented(%q) = %v, want %v", tt.flag, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFlags(t *testing.T) {
+	// Help as found on macOS; tools without help aren't checked
+	ce := &CommandExecutor{helpCache: map[string]string{
+		"\x00ps":   bsdPsHelp,
+		"\x00head": "",
+		"\x00sort": "  -n  numeric sort\n  -r  reverse\n",
+	}}
+
+	issues := ce.CheckFlags("sudo ps -eo pid,comm --sort=-%cpu | head -5 | sort -rn --stable && git log --oneline")
+	if len(issues) != 2 {
+		t.Fatalf("CheckFlags() = %+v, want issues for ps and sort", issues)
+	}
+	if issues[0].Tool != "ps" || !reflect.DeepEqual(issues[0].Flags, []string{"--sort"}) {
+		t.Errorf("CheckFlags() = %+v, want --sort of ps", issues[0])
+	}
+	if !strings.HasPrefix(issues[0].Help, "ps [-AaCcEefhjlMmrSTvwXx]") || !strings.Contains(issues[0].Help, "-A      Display") {
+		t.Errorf("help excerpt = %q", issues[0].Help)
+	}
+	if issues[1].Tool != "sort" || !reflect.DeepEqual(issues[1].Flags, []string{"--stable"}) {
+		t.Errorf("CheckFlags() = %+v, want --stable of sort", issues[1])
+	}
+
+	if issues := ce.CheckFlags("ps -axo pid,ppid,comm"); len(issues) != 0 {
+		t.Errorf("CheckFlags() of valid flags = %+v", issues)
+	}
+}
@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFleetParallel is the default number of hosts a fleet runs a command on at a time
+const DefaultFleetParallel = 10
+
+// HostResult is the outcome of a command on one host of a fleet
+type HostResult struct {
+	Target   string
+	Output   string
+	ExitCode int   // -1 if the command didn't run to completion
+	Err      error // Non-nil when the exit code isn't 0
+	Duration time.Duration
+}
+
+// Fleet runs commands on many hosts in parallel. Each host keeps its own shell
+// state, like a single remote executor.
+type Fleet struct {
+	members  []*CommandExecutor
+	parallel int
+}
+
+// NewFleet creates a fleet of the hosts of runners, executing commands like ce (e.g.
+// in a pseudo-terminal). The shell state of the hosts is captured at most parallel
+// at a time. Hosts whose state can't be read are left out and reported in the
+// returned error; the fleet is still usable if it isn't empty.
+func (ce *CommandExecutor) NewFleet(runners []Runner, parallel int) (*Fleet, error) {
+	fleet := &Fleet{parallel: max(parallel, 1)}
+
+	members := make([]*CommandExecutor, len(runners))
+	errs := make([]error, len(runners))
+	fleet.each(len(runners), func(i int) {
+		member := &CommandExecutor{pty: ce.pty, terminalWidth: ce.terminalWidth}
+		if errs[i] = member.SetRunner(runners[i]); errs[i] == nil {
+			members[i] = member
+		}
+	})
+
+	for _, member := range members {
+		if member != nil {
+			fleet.members = append(fleet.members, member)
+		}
+	}
+	return fleet, errors.Join(errs...)
+}
+
+// Size returns the number of hosts
+func (f *Fleet) Size() int {
+	return len(f.members)
+}
+
+// Targets returns the hosts commands run on
+func (f *Fleet) Targets() []string {
+	targets := make([]string, len(f.members))
+	for i, member := range f.members {
+		targets[i] = member.Target()
+	}
+	return targets
+}
+
+// Execute runs command on every host and returns the results in host order
+func (f *Fleet) Execute(command string) []HostResult {
+	results := make([]HostResult, len(f.members))
+	f.each(len(f.members), func(i int) {
+		start := time.Now()
+		output, err := f.members[i].ExecuteCommand(command)
+		results[i] = HostResult{
+			Target:   f.members[i].Target(),
+			Output:   output,
+			ExitCode: exitCode(err),
+			Err:      err,
+			Duration: time.Since(start),
+		}
+	})
+	return results
+}
+
+// each calls fn for 0..n-1, at most f.parallel at a time
+func (f *Fleet) each(n int, fn func(i int)) {
+	sem := make(chan struct{}, f.parallel)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// exitCode returns the exit status carried by err: 0 for nil, -1 if unknown
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	// ssh.ExitError and exec.ExitError respectively
+	var sshStatus interface{ ExitStatus() int }
+	if errors.As(err, &sshStatus) {
+		return sshStatus.ExitStatus()
+	}
+	var execStatus interface{ ExitCode() int }
+	if errors.As(err, &execStatus) {
+		return execStatus.ExitCode()
+	}
+	return -1
+}
+
+// FormatExitCode formats an exit code for display, "-" when it's unknown
+func FormatExitCode(code int) string {
+	if code < 0 {
+		return "-"
+	}
+	return fmt.Sprint(code)
+}
+
+// FormatAsContext formats the fleet and the shell state of its hosts as context string for LLM
+func (f *Fleet) FormatAsContext() string {
+	var sb strings.Builder
+	sb.WriteString("[Fleet]\n")
+	sb.WriteString(fmt.Sprintf("Hosts (%d): %s\n", len(f.members), strings.Join(f.Targets(), ", ")))
+	sb.WriteString("Commands run on every host in parallel and their outputs are reported per host. Only query commands are run.\n")
+	if len(f.members) == 0 {
+		return sb.String()
+	}
+
+	// Hosts usually share the state, as they run the same commands
+	first := f.members[0]
+	dirs := make([]string, len(f.members))
+	sameDir := true
+	for i, member := range f.members {
+		dirs[i] = member.Target() + "=" + member.WorkingDir()
+		sameDir = sameDir && member.WorkingDir() == first.WorkingDir()
+	}
+	if sameDir {
+		sb.WriteString(fmt.Sprintf("Working Directory: %s\n", first.WorkingDir()))
+	} else {
+		sb.WriteString(fmt.Sprintf("Working Directories: %s\n", strings.Join(dirs, ", ")))
+	}
+	if changes := first.state.EnvChanges(first.initialState); len(changes) > 0 {
+		sb.WriteString(fmt.Sprintf("Environment Changes: %s\n", strings.Join(changes, ", ")))
+	}
+	return sb.String()
+}
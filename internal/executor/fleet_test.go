@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hostRunner is a local runner posing as a host, running scripts with HOST set
+type hostRunner struct {
+	name    string
+	down    bool
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (r hostRunner) Run(script string, terminalWidth int) (string, error) {
+	if r.down {
+		return "", errors.New("connection refused")
+	}
+	if r.running != nil {
+		n := r.running.Add(1)
+		defer r.running.Add(-1)
+		for {
+			peak := r.peak.Load()
+			if n <= peak || r.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	out, err := exec.Command("sh", "-c", "HOST="+r.name+"\n"+script).CombinedOutput()
+	return string(out), err
+}
+
+func (r hostRunner) Target() string {
+	return "tester@" + r.name
+}
+
+func TestFleet(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	var running, peak atomic.Int32
+	var runners []Runner
+	for _, name := range []string{"web1", "web2", "web3", "web4"} {
+		runners = append(runners, hostRunner{name: name, running: &running, peak: &peak})
+	}
+	runners = append(runners, hostRunner{name: "web5", down: true})
+
+	fleet, err := (&CommandExecutor{}).NewFleet(runners, 2)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("NewFleet() error = %v, want the unreachable host", err)
+	}
+	if fleet.Size() != 4 {
+		t.Fatalf("Size() = %d, want 4", fleet.Size())
+	}
+
+	peak.Store(0)
+	results := fleet.Execute(`echo "$HOST"; [ "$HOST" != web3 ] || exit 3`)
+	if got := peak.Load(); got > 2 {
+		t.Errorf("ran on %d hosts at a time, want at most 2", got)
+	}
+
+	for i, r := range results {
+		name := runners[i].(hostRunner).name
+		wantCode := 0
+		if name == "web3" {
+			wantCode = 3
+		}
+		if r.Target != "tester@"+name || r.Output != name+"\n" || r.ExitCode != wantCode || (r.Err != nil) != (wantCode != 0) {
+			t.Errorf("result %d = %+v, want output %q and exit code %d", i, r, name+"\n", wantCode)
+		}
+	}
+
+	// Each host keeps its shell state
+	fleet.Execute(`cd "/$([ "$HOST" = web1 ] && echo tmp)"`)
+	context := fleet.FormatAsContext()
+	for _, want := range []string{"Hosts (4): tester@web1, tester@web2, tester@web3, tester@web4", "Working Directories: tester@web1=/tmp, tester@web2=/,"} {
+		if !strings.Contains(context, want) {
+			t.Errorf("FormatAsContext() = %q, want %q", context, want)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{exec.Command("sh", "-c", "exit 4").Run(), 4},
+		{errors.New("connection lost"), -1},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// kubectlTarget holds the cluster selection flags of a kubectl invocation
+type kubectlTarget struct {
+	context    string
+	namespace  string
+	kubeconfig string
+}
+
+// findKubectl returns the cluster selection flags of the kubectl invocation in
+// command that modifies the cluster, or of the first one if none does. It looks
+// through pipelines, lists, wrappers like sudo and sh -c. found is false when
+// command doesn't run kubectl.
+func findKubectl(command string, depth int) (kubectlTarget, bool) {
+	invocations := kubectlInvocations(command, depth)
+	if len(invocations) == 0 {
+		return kubectlTarget{}, false
+	}
+	for _, args := range invocations {
+		if classifyKubectl("kubectl", args).Type == ModifyCommand {
+			return kubectlFlags(args), true
+		}
+	}
+	return kubectlFlags(invocations[0]), true
+}
+
+// kubectlInvocations returns the arguments of each kubectl invocation in command
+func kubectlInvocations(command string, depth int) [][]string {
+	if depth > maxClassifyDepth {
+		return nil
+	}
+
+	var invocations [][]string
+	segments, substitutions := lexShell(command)
+	for _, seg := range segments {
+		words := stripWrappers(seg.words)
+		if len(words) == 0 {
+			continue
+		}
+
+		switch filepath.Base(words[0]) {
+		case "kubectl":
+			invocations = append(invocations, words[1:])
+		case "sh", "bash", "zsh", "dash", "ksh":
+			for i, arg := range words[1:] {
+				if arg == "-c" && i+2 < len(words) {
+					invocations = append(invocations, kubectlInvocations(words[i+2], depth+1)...)
+				}
+			}
+		}
+	}
+	for _, sub := range substitutions {
+		invocations = append(invocations, kubectlInvocations(sub, depth+1)...)
+	}
+	return invocations
+}
+
+// kubectlFlags extracts --context, -n/--namespace and --kubeconfig from kubectl arguments
+func kubectlFlags(args []string) kubectlTarget {
+	var target kubectlTarget
+	fields := map[string]*string{
+		"--context":    &target.context,
+		"-n":           &target.namespace,
+		"--namespace":  &target.namespace,
+		"--kubeconfig": &target.kubeconfig,
+	}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				break
+			}
+			i++
+			value = args[i]
+		}
+		*field = value
+	}
+	return target
+}
+
+// KubeContext returns the Kubernetes context and namespace command acts on: the
+// ones given with its flags, else the current ones where commands run, taking
+// KUBECONFIG changes of the session into account. ok is false when command doesn't
+// run kubectl. Context and namespace are empty when they can't be determined.
+func (ce *CommandExecutor) KubeContext(command string) (kubeContext, namespace string, ok bool) {
+	target, found := findKubectl(command, 0)
+	if !found {
+		return "", "", false
+	}
+
+	var flags string
+	if target.kubeconfig != "" {
+		flags += " --kubeconfig " + shellQuote(target.kubeconfig)
+	}
+	if target.context != "" {
+		flags += " --context " + shellQuote(target.context)
+	}
+	script := fmt.Sprintf(`kubectl%s config view --minify -o 'jsonpath={.current-context}{"\n"}{..namespace}' 2>/dev/null`, flags)
+
+	output, _ := ce.probe(script)
+	current, contextNamespace, _ := strings.Cut(strings.TrimSpace(output), "\n")
+
+	kubeContext, namespace = target.context, target.namespace
+	if kubeContext == "" {
+		kubeContext = strings.TrimSpace(current)
+	}
+	if namespace == "" && kubeContext != "" {
+		namespace = strings.TrimSpace(contextNamespace)
+		if namespace == "" {
+			namespace = "default"
+		}
+	}
+	return kubeContext, namespace, true
+}
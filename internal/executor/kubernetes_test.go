@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFindKubectl(t *testing.T) {
+	tests := []struct {
+		command string
+		want    kubectlTarget
+		found   bool
+	}{
+		{"kubectl delete pod web-0", kubectlTarget{}, true},
+		{"kubectl -n prod --context=eu-1 rollout restart deploy/web", kubectlTarget{context: "eu-1", namespace: "prod"}, true},
+		{"sudo KUBECONFIG=/etc/k.conf kubectl --kubeconfig /tmp/k --namespace=kube-system delete pod x", kubectlTarget{namespace: "kube-system", kubeconfig: "/tmp/k"}, true},
+		{"kubectl get pods | grep Error && kubectl --context dev delete pod x", kubectlTarget{context: "dev"}, true},
+		{`sh -c "kubectl --context stage scale deploy/web --replicas=0"`, kubectlTarget{context: "stage"}, true},
+		{"echo $(/usr/local/bin/kubectl -n ops get pods -o name)", kubectlTarget{namespace: "ops"}, true},
+		{"systemctl restart kubelet", kubectlTarget{}, false},
+		{"echo kubectl", kubectlTarget{}, false},
+	}
+
+	for _, tt := range tests {
+		got, found := findKubectl(tt.command, 0)
+		if got != tt.want || found != tt.found {
+			t.Errorf("findKubectl(%q) = %+v, %v, want %+v, %v", tt.command, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestKubeContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	// A fake kubectl: context "prod" with namespace "payments" unless --context is given
+	dir := t.TempDir()
+	script := `#!/bin/sh
+ctx=prod
+ns=payments
+prev=
+for arg; do
+	[ "$prev" = --context ] && ctx=$arg && ns=
+	prev=$arg
+done
+printf '%s\n%s' "$ctx" "$ns"
+`
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	state := newShellState()
+	state.Env["PATH"] = dir + string(os.PathListSeparator) + state.Env["PATH"]
+	ce := &CommandExecutor{initialState: state, state: state}
+
+	tests := []struct {
+		command       string
+		wantContext   string
+		wantNamespace string
+		wantOK        bool
+	}{
+		{"kubectl delete pod web-0", "prod", "payments", true},
+		{"kubectl -n web delete pod web-0", "prod", "web", true},
+		{"kubectl --context stage delete pod web-0", "stage", "default", true},
+		{"rm -rf /tmp/x", "", "", false},
+	}
+	for _, tt := range tests {
+		kubeContext, namespace, ok := ce.KubeContext(tt.command)
+		if kubeContext != tt.wantContext || namespace != tt.wantNamespace || ok != tt.wantOK {
+			t.Errorf("KubeContext(%q) = %q, %q, %v, want %q, %q, %v", tt.command,
+				kubeContext, namespace, ok, tt.wantContext, tt.wantNamespace, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/creack/pty"
+)
+
+// ptyRows is the terminal height for PTY execution
+const ptyRows = 50
+
+// ptyEnv disables pagers, which would wait for input in a terminal
+var ptyEnv = []string{"PAGER=cat", "SYSTEMD_PAGER=", "GIT_PAGER=cat", "MANPAGER=cat", "LESS=FRX"}
+
+// ptyEnviron returns the variables set for commands running in a pseudo-terminal
+// of the given width, in addition to env
+func ptyEnviron(env []string, width int) []string {
+	vars := append([]string{}, ptyEnv...)
+	vars = append(vars, fmt.Sprintf("COLUMNS=%d", width), fmt.Sprintf("LINES=%d", ptyRows))
+	if !hasEnv(env, "TERM") {
+		vars = append(vars, "TERM=xterm-256color")
+	}
+	return vars
+}
+
+// executePTY runs cmd attached to a pseudo-terminal of the given width and returns
+// everything it printed. Commands see an interactive terminal, so they produce the
+// same output as when run by hand (colors, full tables, status details). The
+// environment of cmd should include ptyEnviron.
+func executePTY(cmd *exec.Cmd, width int) (string, error) {
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(width), Rows: ptyRows})
+	if err != nil {
+		return "", fmt.Errorf("failed to start command in a pseudo-terminal: %w", err)
+	}
+	defer ptmx.Close()
+
+	// Send end-of-file, so commands reading from the terminal don't wait forever
+	_, _ = ptmx.Write([]byte{4})
+
+	var output bytes.Buffer
+	// Reading fails with EIO once the command exits and the terminal is closed
+	_, _ = io.Copy(&output, ptmx)
+	err = cmd.Wait()
+
+	// The terminal echoes the end-of-file character when nothing read it
+	return strings.TrimPrefix(output.String(), "^D\b\b"), err
+}
+
+func hasEnv(env []string, name string) bool {
+	for _, kv := range env {
+		if strings.HasPrefix(kv, name+"=") && len(kv) > len(name)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// CSI (colors, cursor movement), OSC (titles, hyperlinks) and other escape sequences
+	ansiRe = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+	// Overstrike used by man and similar tools for bold and underline
+	overstrikeRe = regexp.MustCompile(`.\x08`)
+)
+
+// CleanTerminalOutput strips escape sequences, overstrikes and carriage returns from
+// terminal output, leaving plain text for the model. Lines rewritten with a carriage
+// return, like progress bars, keep their last content.
+func CleanTerminalOutput(output string) string {
+	output = ansiRe.ReplaceAllString(output, "")
+	output = overstrikeRe.ReplaceAllString(output, "")
+
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCleanTerminalOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"colors", "\x1b[0;1;32m●\x1b[0m nginx.service\r\n", "● nginx.service\n"},
+		{"cursor movement", "\x1b[?25lline\x1b[K\x1b[?25h", "line"},
+		{"hyperlink", "\x1b]8;;file:///etc\x1b\\etc\x1b]8;;\x1b\\", "etc"},
+		{"window title", "\x1b]0;title\x07text", "text"},
+		{"progress bar", "  0%\r 50%\r100%\r\ndone\r\n", "100%\ndone\n"},
+		{"overstrike", "N\bNA\bAM\bME\bE", "NAME"},
+		{"plain", "a\tb\nc", "a\tb\nc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanTerminalOutput(tt.output); got != tt.want {
+				t.Errorf("CleanTerminalOutput(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePTY(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals are not supported on Windows")
+	}
+
+	state := newShellState()
+	ce := &CommandExecutor{initialState: state, state: state, pty: true, terminalWidth: 123}
+
+	output, err := ce.ExecuteCommand(`test -t 1 && echo tty; stty size; echo "pager=$PAGER"; cat; echo done`)
+	if err != nil {
+		t.Fatalf("ExecuteCommand() error = %v, output %q", err, output)
+	}
+
+	want := "tty\n50 123\npager=cat\ndone\n"
+	if got := CleanTerminalOutput(output); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	// Shell state is kept in PTY mode too
+	if _, err := ce.ExecuteCommand("cd / && export AIASSIST_PTY_TEST=1"); err != nil {
+		t.Fatal(err)
+	}
+	output, _ = ce.ExecuteCommand(`echo "$(pwd) $AIASSIST_PTY_TEST"`)
+	if got := strings.TrimSpace(CleanTerminalOutput(output)); got != "/ 1" {
+		t.Errorf("output = %q, want %q", got, "/ 1")
+	}
+}
+
+func TestExecutePTYKeepsEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals are not supported on Windows")
+	}
+
+	// The user's own pager settings are not changes
+	state := newShellState()
+	state.Env["LESS"] = "-R"
+	delete(state.Env, "PAGER")
+	ce := &CommandExecutor{initialState: state, state: state, pty: true, terminalWidth: 123}
+
+	output, err := ce.ExecuteCommand(`echo "$LESS $PAGER"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(CleanTerminalOutput(output)); got != "FRX cat" {
+		t.Errorf("output = %q, want the pager settings of PTY mode", got)
+	}
+	if changes := ce.state.EnvChanges(ce.initialState); len(changes) != 0 {
+		t.Errorf("EnvChanges() after a PTY run = %v, want none", changes)
+	}
+
+	// Pager settings changed by the command itself are kept
+	if _, err := ce.ExecuteCommand("export LESS=-X PAGER=more"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ce.state.EnvChanges(ce.initialState), []string{"~LESS", "+PAGER"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvChanges() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Runner runs shell scripts on another host, see remote.Client
+type Runner interface {
+	// Run executes script with sh and returns its combined output. With a terminal
+	// width above zero the script runs in a pseudo-terminal of that width.
+	Run(script string, terminalWidth int) (string, error)
+	// Target returns where scripts run, as user@host
+	Target() string
+}
+
+// SetRunner makes commands run through runner instead of locally. The shell state
+// of the remote host is captured as the initial state.
+func (ce *CommandExecutor) SetRunner(runner Runner) error {
+	ce.runner = runner
+	ce.sentinel = newSentinel()
+
+	output, err := runner.Run(ce.remoteStateTrap()+"\n:", 0)
+	if err != nil {
+		return fmt.Errorf("failed to read shell state on %s: %w", runner.Target(), err)
+	}
+	_, state := ce.splitRemoteOutput(output)
+	if state == nil {
+		return fmt.Errorf("failed to read shell state on %s", runner.Target())
+	}
+	ce.initialState = state
+	ce.state = state
+	return nil
+}
+
+// Target returns the host commands run on, or "" when they run locally
+func (ce *CommandExecutor) Target() string {
+	if ce.runner == nil {
+		return ""
+	}
+	return ce.runner.Target()
+}
+
+// executeRemote runs command through the runner. The shell state can't be written to
+// files there, so it's replayed at the start of the script and printed after a
+// sentinel line at the end.
+func (ce *CommandExecutor) executeRemote(command string) (string, error) {
+	var script strings.Builder
+	// One output stream, so the state is always printed after the command output
+	script.WriteString("exec 2>&1\n")
+	script.WriteString(ce.replayState())
+
+	width := 0
+	if ce.pty {
+		width = ce.terminalWidth
+		for _, kv := range ptyEnv {
+			name, value, _ := strings.Cut(kv, "=")
+			fmt.Fprintf(&script, "export %s=%s\n", name, shellQuote(value))
+		}
+	}
+	script.WriteString(ce.remoteStateTrap() + "\n")
+	script.WriteString(command)
+
+	output, err := ce.runner.Run(script.String(), width)
+	output, state := ce.splitRemoteOutput(output)
+	if state != nil {
+		if ce.pty {
+			state.restoreEnv(ce.state, ptyEnv)
+		}
+		ce.state = state
+	}
+	return output, err
+}
+
+// replayState returns script lines restoring the working directory and the
+// environment changes since the session started
+func (ce *CommandExecutor) replayState() string {
+	var sb strings.Builder
+	if ce.state.Dir != "" && ce.state.Dir != ce.initialState.Dir {
+		fmt.Fprintf(&sb, "cd %s 2>/dev/null\n", shellQuote(ce.state.Dir))
+	}
+
+	names := make([]string, 0, len(ce.state.Env))
+	for name := range ce.state.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if old, ok := ce.initialState.Env[name]; !ok || old != ce.state.Env[name] {
+			fmt.Fprintf(&sb, "export %s=%s\n", name, shellQuote(ce.state.Env[name]))
+		}
+	}
+	for _, change := range ce.state.EnvChanges(ce.initialState) {
+		if strings.HasPrefix(change, "-") {
+			fmt.Fprintf(&sb, "unset %s\n", change[1:])
+		}
+	}
+	return sb.String()
+}
+
+// remoteStateTrap returns a script line printing the sentinel and the shell state
+// when the command exits, preserving its exit status
+func (ce *CommandExecutor) remoteStateTrap() string {
+	return fmt.Sprintf(`trap '__aiassist_status=$?; printf "\n%%s\n" %s; pwd; export -p; exit $__aiassist_status' EXIT`,
+		ce.sentinel)
+}
+
+// splitRemoteOutput separates the command output from the state printed by
+// remoteStateTrap. The state is nil if it wasn't printed.
+func (ce *CommandExecutor) splitRemoteOutput(output string) (string, *ShellState) {
+	i := strings.LastIndex(output, ce.sentinel)
+	if i < 0 {
+		return output, nil
+	}
+
+	// Pseudo-terminals translate newlines to \r\n
+	before := strings.TrimSuffix(strings.TrimSuffix(output[:i], "\n"), "\r")
+	after := strings.ReplaceAll(output[i+len(ce.sentinel):], "\r\n", "\n")
+
+	dir, exports, _ := strings.Cut(strings.TrimPrefix(after, "\n"), "\n")
+	state := &ShellState{Dir: strings.TrimSpace(dir), Env: parseExports(exports)}
+	if state.Dir == "" || len(state.Env) == 0 {
+		return before, nil
+	}
+	return before, state
+}
+
+// newSentinel returns a random marker that can't appear in command output by chance
+func newSentinel() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "__aiassist_state_" + hex.EncodeToString(b)
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
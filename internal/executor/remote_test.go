@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+// localRunner runs scripts in a new local shell each time, like a remote host would
+type localRunner struct{}
+
+func (localRunner) Run(script string, terminalWidth int) (string, error) {
+	out, err := exec.Command("sh", "-c", script).CombinedOutput()
+	return string(out), err
+}
+
+func (localRunner) Target() string {
+	return "tester@example"
+}
+
+func TestExecuteRemote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	ce := &CommandExecutor{}
+	if err := ce.SetRunner(localRunner{}); err != nil {
+		t.Fatal(err)
+	}
+	if ce.Target() != "tester@example" {
+		t.Errorf("Target() = %q", ce.Target())
+	}
+
+	tests := []struct {
+		command string
+		want    string
+		wantErr bool
+	}{
+		{"printf 'no newline'", "no newline", false},
+		{"echo out; echo err >&2", "out\nerr\n", false},
+		{"cd / && export AIASSIST_REMOTE_TEST=\"it's set\"", "", false},
+		{`echo "$(pwd) $AIASSIST_REMOTE_TEST"`, "/ it's set\n", false},
+		{"unset AIASSIST_REMOTE_TEST; echo __aiassist_state_; exit 3", "__aiassist_state_\n", true},
+		{`echo "${AIASSIST_REMOTE_TEST-unset}"`, "unset\n", false},
+	}
+
+	for _, tt := range tests {
+		output, err := ce.ExecuteCommand(tt.command)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ExecuteCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+		}
+		if output != tt.want {
+			t.Errorf("ExecuteCommand(%q) = %q, want %q", tt.command, output, tt.want)
+		}
+	}
+
+	if ce.WorkingDir() != "/" {
+		t.Errorf("WorkingDir() = %q, want /", ce.WorkingDir())
+	}
+}
+
+func TestExecuteRemotePTYKeepsEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	ce := &CommandExecutor{pty: true, terminalWidth: 120}
+	if err := ce.SetRunner(localRunner{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		output, err := ce.ExecuteCommand(`echo "$PAGER"`)
+		if err != nil || output != "cat\n" {
+			t.Fatalf("ExecuteCommand() = %q, %v, want the pager of PTY mode", output, err)
+		}
+		if changes := ce.state.EnvChanges(ce.initialState); len(changes) != 0 {
+			t.Fatalf("EnvChanges() after a PTY run = %v, want none", changes)
+		}
+	}
+	if script := ce.replayState(); script != "" {
+		t.Errorf("replayState() = %q, want nothing to replay", script)
+	}
+}
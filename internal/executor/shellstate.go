@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ShellState is the working directory and exported environment carried from one
+// executed command to the next, so "cd /var/log" or "export KUBECONFIG=..." affect
+// the following commands like in an interactive shell
+type ShellState struct {
+	Dir string
+	Env map[string]string
+}
+
+// shellInternalVars are managed by the shell itself and not carried over
+var shellInternalVars = map[string]bool{"PWD": true, "SHLVL": true, "_": true}
+
+// newShellState captures the state of the current process
+func newShellState() *ShellState {
+	state := &ShellState{Env: make(map[string]string)}
+	state.Dir, _ = os.Getwd()
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok && !shellInternalVars[name] {
+			state.Env[name] = value
+		}
+	}
+	return state
+}
+
+// environ returns the environment in os/exec format
+func (s *ShellState) environ() []string {
+	env := make([]string, 0, len(s.Env))
+	for name, value := range s.Env {
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// stateTrap returns a script line saving the shell state into dir when the
+// command exits, preserving its exit status
+func stateTrap(dir string) string {
+	pwdFile := filepath.Join(dir, "pwd")
+	envFile := filepath.Join(dir, "env")
+	return fmt.Sprintf(`trap '__aiassist_status=$?; pwd > "%s" 2>/dev/null; export -p > "%s" 2>/dev/null; exit $__aiassist_status' EXIT`,
+		pwdFile, envFile)
+}
+
+// loadShellState reads the state saved by stateTrap. It returns nil if the command
+// didn't save it, e.g. because it was killed or replaced the shell with exec.
+func loadShellState(dir string) *ShellState {
+	pwd, err := os.ReadFile(filepath.Join(dir, "pwd"))
+	if err != nil {
+		return nil
+	}
+	exports, err := os.ReadFile(filepath.Join(dir, "env"))
+	if err != nil {
+		return nil
+	}
+
+	state := &ShellState{
+		Dir: strings.TrimSpace(string(pwd)),
+		Env: parseExports(string(exports)),
+	}
+	if state.Dir == "" || len(state.Env) == 0 {
+		return nil
+	}
+	return state
+}
+
+// restoreEnv undoes the variables in vars, which were set for running a command
+// such as the pager settings of PTY mode, so they aren't carried over as changes
+// of the shell state. Variables the command set to other values are kept.
+func (s *ShellState) restoreEnv(prev *ShellState, vars []string) {
+	for _, kv := range vars {
+		name, value, _ := strings.Cut(kv, "=")
+		if current, ok := s.Env[name]; !ok || current != value {
+			continue
+		}
+		if old, ok := prev.Env[name]; ok {
+			s.Env[name] = old
+		} else {
+			delete(s.Env, name)
+		}
+	}
+}
+
+// parseExports parses "export -p" output of POSIX shells, e.g. dash's
+// export HOME='/root' or bash's export HOME="/root". Variables that are
+// exported without a value are skipped.
+func parseExports(exports string) map[string]string {
+	env := make(map[string]string)
+	segments, _ := lexShell(exports)
+	for _, seg := range segments {
+		words := seg.words
+		if len(words) < 2 || (words[0] != "export" && words[0] != "declare") {
+			continue
+		}
+		for _, word := range words[1:] {
+			name, value, ok := strings.Cut(word, "=")
+			if !ok || shellInternalVars[name] || !envAssignmentRe.MatchString(name+"=") {
+				continue
+			}
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// EnvChanges lists the names of variables added (+NAME), changed (~NAME) or
+// removed (-NAME) compared to base. Values are left out as they may hold secrets,
+// and OLDPWD, which every cd changes, is left out as well.
+func (s *ShellState) EnvChanges(base *ShellState) []string {
+	var changes []string
+	for name, value := range s.Env {
+		if name == "OLDPWD" {
+			continue
+		}
+		if old, ok := base.Env[name]; !ok {
+			changes = append(changes, "+"+name)
+		} else if old != value {
+			changes = append(changes, "~"+name)
+		}
+	}
+	for name := range base.Env {
+		if _, ok := s.Env[name]; !ok && name != "OLDPWD" {
+			changes = append(changes, "-"+name)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i][1:] < changes[j][1:] })
+	return changes
+}
+
+// FormatAsContext formats the shell state as context string for LLM
+func (s *ShellState) FormatAsContext(base *ShellState) string {
+	var sb strings.Builder
+	sb.WriteString("[Shell State]\n")
+	sb.WriteString(fmt.Sprintf("Working Directory: %s\n", s.Dir))
+	if changes := s.EnvChanges(base); len(changes) > 0 {
+		sb.WriteString(fmt.Sprintf("Environment Changes: %s\n", strings.Join(changes, ", ")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExports(t *testing.T) {
+	tests := []struct {
+		name    string
+		exports string
+		want    map[string]string
+	}{
+		{
+			name:    "dash",
+			exports: "export HOME='/root'\nexport MSG='it'\\''s'\nexport PWD='/tmp'\n",
+			want:    map[string]string{"HOME": "/root", "MSG": "it's"},
+		},
+		{
+			name:    "bash posix mode",
+			exports: "export HOME=\"/root\"\nexport MSG=\"say \\\"hi\\\" \\$x\"\nexport UNSET\n",
+			want:    map[string]string{"HOME": "/root", "MSG": `say "hi" $x`},
+		},
+		{
+			name:    "bash declare",
+			exports: "declare -x KUBECONFIG=\"/etc/kube/config\"\n",
+			want:    map[string]string{"KUBECONFIG": "/etc/kube/config"},
+		},
+		{
+			name:    "multi-line value",
+			exports: "export A='line1\nline2'\nexport B='b'\n",
+			want:    map[string]string{"A": "line1\nline2", "B": "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseExports(tt.exports); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseExports() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteCommandKeepsShellState(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Resolve symlinks like /tmp on macOS so the paths compare equal
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	state := newShellState()
+	ce := &CommandExecutor{initialState: state, state: state}
+	if _, err := ce.ExecuteCommand("cd " + dir + " && export AIASSIST_TEST_VAR=hello && unset HOME"); err != nil {
+		t.Fatal(err)
+	}
+	if ce.WorkingDir() != dir {
+		t.Errorf("WorkingDir() = %q, want %q", ce.WorkingDir(), dir)
+	}
+
+	output, err := ce.ExecuteCommand("cd logs; pwd; echo \"$AIASSIST_TEST_VAR\"; echo \"home=$HOME\"")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "logs") + "\nhello\nhome=\n"
+	if output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	// A failing command keeps its exit status and still updates the state
+	if _, err := ce.ExecuteCommand("cd ..; exit 3"); err == nil {
+		t.Error("expected exit status 3 to be reported")
+	}
+	if ce.WorkingDir() != dir {
+		t.Errorf("WorkingDir() = %q, want %q", ce.WorkingDir(), dir)
+	}
+
+	context := ce.ShellContext()
+	for _, want := range []string{"Working Directory: " + dir, "+AIASSIST_TEST_VAR", "-HOME"} {
+		if !strings.Contains(context, want) {
+			t.Errorf("ShellContext() = %q, missing %q", context, want)
+		}
+	}
+}
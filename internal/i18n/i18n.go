@@ -36,6 +36,11 @@ func (i *I18n) T(key string, args ...interface{}) string {
 	return msg
 }
 
+// SetLanguage switches the language of all further translations
+func (i *I18n) SetLanguage(language string) {
+	i.language = language
+}
+
 func (i *I18n) GetLanguage() string {
 	return i.language
 }
@@ -4,16 +4,17 @@ package i18n
 var EnglishMessages = map[string]string{
 	// Config messages
 	"config.not_found":      "✗ Configuration file not found",
-	"config.hint_run_setup": "Please edit config file: ~/.aiassist/config.yaml",
+	"config.hint_run_setup": "Run 'aiassist config init' to create it, or edit ~/.aiassist/config.yaml",
 
 	// Interactive mode messages
 	"interactive.welcome":            "Welcome to AI Shell Assistant",
-	"interactive.exit_hint":          "Tip: Press Ctrl+C anytime to exit",
-	"interactive.input_prompt":       "Please enter your question: ",
+	"interactive.exit_hint":          "Tip: Type /help for commands, /exit or Ctrl+C to exit",
+	"interactive.input_prompt":       "[%s] Please enter your question: ",
 	"interactive.goodbye":            "Goodbye!",
 	"interactive.thinking":           "Thinking",
 	"interactive.continue_analysis":  "Based on the complete conversation history and the executed command output above, please continue with the next steps of analysis and diagnosis, listing the remaining steps and commands.",
 	"interactive.executed_command":   "Executed Command",
+	"interactive.edited_command":     "Suggested Command (edited by the user before execution)",
 	"interactive.execution_output":   "Execution Output",
 	"interactive.execution_error":    "Execution Error",
 	"interactive.user_label":         "User",
@@ -21,18 +22,108 @@ var EnglishMessages = map[string]string{
 	"interactive.analysis_complete":  "✓ Analysis complete, please continue with questions",
 	"interactive.pipe_user_question": "User question: ",
 	"interactive.pipe_data":          "Pipe output data:",
+	"interactive.pipe_data_summary":  "Pipe output data (pre-processed locally into a summary of the complete input with representative samples):",
+	"interactive.preprocess_done":    "Pre-processed %d lines into %d templates",
 	"interactive.pipe_source":        "Data source: piped input",
 
+	// Follow mode messages
+	"interactive.follow_started":           "Following piped input: analyzing every %d lines or %v, press Ctrl+C to stop",
+	"interactive.follow_window":            "Window %d (%d lines): new findings",
+	"interactive.follow_no_new":            "Window %d (%d lines): no new findings",
+	"interactive.follow_ended":             "✓ Input ended, follow mode finished",
+	"interactive.follow_previous_findings": "Previously reported findings:",
+	"interactive.follow_no_findings_yet":   "(none yet, this is the first window)",
+	"interactive.follow_window_data":       "New lines (window %d, %d lines):",
+
 	// Executor messages
-	"executor.query_command":     "Query command:",
-	"executor.modify_command":    "Modify command (requires confirmation):",
-	"executor.execute_prompt":    "Execute this command?",
-	"executor.modify_warning":    "Warning: This command will modify server configuration, are you sure?",
-	"executor.executing":         "Executing",
-	"executor.execute_success":   "✓ Execution successful",
-	"executor.execute_failed":    "✗ Execution failed: %v",
-	"executor.no_output":         "(Command executed successfully, but no output)",
-	"executor.max_depth_reached": "Warning: Maximum command analysis depth reached. Stopping to prevent infinite recursion.",
+	"executor.query_command":         "Query command:",
+	"executor.modify_command":        "Modify command (requires confirmation):",
+	"executor.execute_prompt":        "Execute this command?",
+	"executor.execute_prompt_remote": "Execute this command on %s?",
+	"executor.edit_prompt":           "Edit the command (Enter to confirm):",
+	"executor.edit_rejected":         "The edited command was discarded, keeping: %s",
+	"executor.modify_warning":        "Warning: This command will modify server configuration, are you sure?",
+	"executor.modify_warning_remote": "Warning: This command will modify the configuration of %s, are you sure?",
+	"executor.executing":             "Executing",
+	"executor.execute_success":       "✓ Execution successful",
+	"executor.execute_failed":        "✗ Execution failed: %v",
+	"executor.no_output":             "(Command executed successfully, but no output)",
+	"executor.max_depth_reached":     "Warning: Maximum command analysis depth reached. Stopping to prevent infinite recursion.",
+
+	// Command plan
+	"executor.plan_title":         "Proposed commands (%d):",
+	"executor.plan_prompt":        "How do you want to run these commands?",
+	"executor.plan_prompt_remote": "How do you want to run these commands on %s?",
+	"executor.plan_run_queries":   "Run all %d query commands",
+	"executor.plan_choose":        "Choose commands to run",
+	"executor.plan_step":          "Step through one by one",
+	"executor.plan_skip":          "Skip all",
+	"executor.plan_choose_prompt": "Select commands (↑/↓ move, Space toggle, a toggle all, Enter confirm). Modify commands still require confirmation:",
+	"executor.plan_blacklisted":   "(blacklisted)",
+	"executor.plan_upgraded":      "(treated as modify: %s)",
+
+	// Kubernetes context guard
+	"executor.kube_context":         "Kubernetes context: %s, namespace: %s",
+	"executor.kube_context_unknown": "Kubernetes context: unknown (no current context)",
+
+	// Fleet mode
+	"interactive.fleet_target":      "%d hosts",
+	"interactive.fleet_host_output": "%s, exit code %s",
+	"interactive.fleet_summary":     "Exit codes per host:",
+	"executor.executing_fleet":      "Executing on %d hosts",
+	"executor.fleet_modify_refused": "Modify commands are not run on a fleet of hosts: apply changes host by host with --host",
+
+	// Sensitive data redaction
+	"interactive.redactions":    "🔒 Redacted before sending to the model:",
+	"interactive.no_redactions": "🔒 Nothing redacted before sending to the model",
+
+	// Runbook knowledge base
+	"interactive.kb_sources": "📖 Runbooks: %s",
+
+	// Flag verification
+	"interactive.flags_unknown":    "⚠ %s: %s not found in its help on the target host",
+	"interactive.flags_correcting": "Asking the model to correct the command",
+	"interactive.flags_unverified": "⚠ The flags above are still not documented, check the commands before running them",
+
+	// Slash commands
+	"slash.help_title":       "Commands (Tab completes):",
+	"slash.help_help":        "Show this help",
+	"slash.help_exit":        "End the session",
+	"slash.help_clear":       "Start a new conversation, keeping the system information",
+	"slash.help_model":       "Use another model first, the others remain fallbacks",
+	"slash.help_models":      "List the models in the order they are tried",
+	"slash.help_history":     "List the messages of the conversation",
+	"slash.help_save":        "Save the conversation as JSON (default ~/.aiassist/sessions/)",
+	"slash.help_export":      "Export the conversation as Markdown (default ./aiassist-<time>.md)",
+	"slash.help_sysinfo":     "Show the system information sent to the AI",
+	"slash.help_run":         "Run a command yourself; it is checked and analyzed like suggested ones",
+	"slash.help_retry":       "Ask the last question again",
+	"slash.help_lang":        "Switch the language",
+	"slash.cleared":          "✓ Conversation cleared",
+	"slash.model_switched":   "✓ Using %s",
+	"slash.models_title":     "Models (* used first):",
+	"slash.history_empty":    "No messages yet",
+	"slash.saved":            "✓ Session saved to %s",
+	"slash.exported":         "✓ Conversation exported to %s",
+	"slash.no_sysinfo":       "No system information (disabled or not collected)",
+	"slash.run_usage":        "Usage: /run <command>",
+	"slash.nothing_to_retry": "No question to retry",
+	"slash.lang_current":     "Language: %s",
+	"slash.lang_switched":    "✓ Language: %s",
+
+	// Local command classification
+	"executor.classified_modify":   "⚠ Marked as a query command by the model, but it looks like a modify command (%s); treating it as modify",
+	"executor.classified_readonly": "Note: Marked as a modify command by the model, but it looks read-only; still treating it as modify",
+
+	// Auto-approve mode
+	"executor.auto_approved":          "Auto-approved (policy: %s)",
+	"executor.auto_skipped":           "Skipped: %s",
+	"executor.auto_budget_exhausted":  "step budget exhausted (--max-steps %d)",
+	"executor.auto_modify_refused":    "modify commands are not auto-approved (requires --auto-approve=all and a matching --allow pattern)",
+	"executor.auto_not_allowed":       "modify command does not match any --allow pattern",
+	"interactive.auto_report_title":   "Auto-approve report (policy: %s, %d/%d steps used)",
+	"interactive.auto_report_empty":   "No commands were suggested",
+	"interactive.auto_report_summary": "%d executed, %d failed, %d skipped, %d blacklisted",
 
 	// Blacklist messages
 	"executor.blacklisted":        "✗ Command rejected: This command matches blacklist rule '%s', execution forbidden",
@@ -44,7 +135,7 @@ var EnglishMessages = map[string]string{
 
 	// Error messages
 	"error.no_models":      "✗ Error: No models configured",
-	"error.hint_no_models": "Please edit config file first: ~/.aiassist/config.yaml",
+	"error.hint_no_models": "Add a provider with 'aiassist config provider add' or edit ~/.aiassist/config.yaml",
 	"error.general":        "✗ Error: %v",
 
 	// Version messages
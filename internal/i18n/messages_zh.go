@@ -4,16 +4,17 @@ package i18n
 var ChineseMessages = map[string]string{
 	// Config messages
 	"config.not_found":      "✗ 配置文件不存在",
-	"config.hint_run_setup": "请编辑配置文件: ~/.aiassist/config.yaml",
+	"config.hint_run_setup": "请运行 'aiassist config init' 创建配置，或编辑配置文件: ~/.aiassist/config.yaml",
 
 	// Interactive mode messages
 	"interactive.welcome":            "欢迎使用 AI Shell Assistant",
-	"interactive.exit_hint":          "提示: 随时按 Ctrl+C 退出",
-	"interactive.input_prompt":       "请输入问题: ",
+	"interactive.exit_hint":          "提示: 输入 /help 查看命令，输入 /exit 或按 Ctrl+C 退出",
+	"interactive.input_prompt":       "[%s] 请输入问题: ",
 	"interactive.goodbye":            "再见！",
 	"interactive.thinking":           "思考中",
 	"interactive.continue_analysis":  "根据以上完整的对话历史和已执行的命令输出，请继续进行接下来的分析和诊断，列出剩余的步骤和命令。",
 	"interactive.executed_command":   "执行命令",
+	"interactive.edited_command":     "建议的命令 (用户执行前已编辑)",
 	"interactive.execution_output":   "执行输出",
 	"interactive.execution_error":    "执行错误",
 	"interactive.user_label":         "用户",
@@ -21,18 +22,108 @@ var ChineseMessages = map[string]string{
 	"interactive.analysis_complete":  "✓ 所有分析已完成",
 	"interactive.pipe_user_question": "用户问题: ",
 	"interactive.pipe_data":          "管道输出数据:",
+	"interactive.pipe_data_summary":  "管道输出数据 (已在本地预处理为完整输入的摘要及代表性样本):",
+	"interactive.preprocess_done":    "已预处理 %d 行，归纳为 %d 个模板",
 	"interactive.pipe_source":        "数据来源: 通过管道输入",
 
+	// Follow mode messages
+	"interactive.follow_started":           "持续跟踪管道输入: 每 %d 行或每 %v 分析一次，按 Ctrl+C 停止",
+	"interactive.follow_window":            "窗口 %d (%d 行): 新发现",
+	"interactive.follow_no_new":            "窗口 %d (%d 行): 无新发现",
+	"interactive.follow_ended":             "✓ 输入已结束，跟踪模式结束",
+	"interactive.follow_previous_findings": "此前已报告的发现:",
+	"interactive.follow_no_findings_yet":   "(暂无，这是第一个窗口)",
+	"interactive.follow_window_data":       "新增数据 (窗口 %d，%d 行):",
+
 	// Executor messages
-	"executor.query_command":     "查询命令:",
-	"executor.modify_command":    "修改命令 (需要确认):",
-	"executor.execute_prompt":    "是否执行此命令?",
-	"executor.modify_warning":    "警告: 该命令将修改服务器配置，是否确定执行?",
-	"executor.executing":         "执行中",
-	"executor.execute_success":   "✓ 执行成功",
-	"executor.execute_failed":    "✗ 执行失败: %v",
-	"executor.no_output":         "(命令执行成功，但没有输出)",
-	"executor.max_depth_reached": "警告: 已达到最大命令分析深度。停止以防止无限递归。",
+	"executor.query_command":         "查询命令:",
+	"executor.modify_command":        "修改命令 (需要确认):",
+	"executor.execute_prompt":        "是否执行此命令?",
+	"executor.execute_prompt_remote": "是否在 %s 上执行此命令?",
+	"executor.edit_prompt":           "编辑命令 (回车确认):",
+	"executor.edit_rejected":         "已丢弃编辑后的命令，保留: %s",
+	"executor.modify_warning":        "警告: 该命令将修改服务器配置，是否确定执行?",
+	"executor.modify_warning_remote": "警告: 该命令将修改 %s 的配置，是否确定执行?",
+	"executor.executing":             "执行中",
+	"executor.execute_success":       "✓ 执行成功",
+	"executor.execute_failed":        "✗ 执行失败: %v",
+	"executor.no_output":             "(命令执行成功，但没有输出)",
+	"executor.max_depth_reached":     "警告: 已达到最大命令分析深度。停止以防止无限递归。",
+
+	// Command plan
+	"executor.plan_title":         "建议执行的命令 (%d 条):",
+	"executor.plan_prompt":        "如何执行这些命令?",
+	"executor.plan_prompt_remote": "如何在 %s 上执行这些命令?",
+	"executor.plan_run_queries":   "执行全部 %d 条查询命令",
+	"executor.plan_choose":        "选择要执行的命令",
+	"executor.plan_step":          "逐条确认执行",
+	"executor.plan_skip":          "全部跳过",
+	"executor.plan_choose_prompt": "选择命令 (↑/↓ 移动，空格选择，a 全选/全不选，回车确认)。修改类命令仍需确认:",
+	"executor.plan_blacklisted":   "(黑名单)",
+	"executor.plan_upgraded":      "(按修改命令处理: %s)",
+
+	// Kubernetes context guard
+	"executor.kube_context":         "Kubernetes 上下文: %s，命名空间: %s",
+	"executor.kube_context_unknown": "Kubernetes 上下文: 未知 (没有当前上下文)",
+
+	// Fleet mode
+	"interactive.fleet_target":      "%d 台主机",
+	"interactive.fleet_host_output": "%s，退出码 %s",
+	"interactive.fleet_summary":     "各主机退出码:",
+	"executor.executing_fleet":      "正在 %d 台主机上执行",
+	"executor.fleet_modify_refused": "不会在多台主机上批量执行修改命令: 请使用 --host 逐台执行变更",
+
+	// Sensitive data redaction
+	"interactive.redactions":    "🔒 发送给模型前已脱敏:",
+	"interactive.no_redactions": "🔒 发送给模型的内容无需脱敏",
+
+	// Runbook knowledge base
+	"interactive.kb_sources": "📖 参考手册: %s",
+
+	// Flag verification
+	"interactive.flags_unknown":    "⚠ %s: 目标主机上的帮助文档中没有 %s",
+	"interactive.flags_correcting": "正在请 AI 修正命令",
+	"interactive.flags_unverified": "⚠ 以上参数仍未在帮助文档中找到，执行前请检查命令",
+
+	// Slash commands
+	"slash.help_title":       "命令 (Tab 补全):",
+	"slash.help_help":        "显示本帮助",
+	"slash.help_exit":        "结束会话",
+	"slash.help_clear":       "开始新对话，保留系统信息",
+	"slash.help_model":       "优先使用另一个模型，其他模型仍作为备用",
+	"slash.help_models":      "按调用顺序列出模型",
+	"slash.help_history":     "列出对话中的消息",
+	"slash.help_save":        "将对话保存为 JSON (默认 ~/.aiassist/sessions/)",
+	"slash.help_export":      "将对话导出为 Markdown (默认 ./aiassist-<时间>.md)",
+	"slash.help_sysinfo":     "显示发送给 AI 的系统信息",
+	"slash.help_run":         "自己执行命令，与 AI 建议的命令一样经过检查和分析",
+	"slash.help_retry":       "重新提问上一个问题",
+	"slash.help_lang":        "切换语言",
+	"slash.cleared":          "✓ 已清空对话",
+	"slash.model_switched":   "✓ 正在使用 %s",
+	"slash.models_title":     "模型 (* 优先使用):",
+	"slash.history_empty":    "暂无消息",
+	"slash.saved":            "✓ 会话已保存到 %s",
+	"slash.exported":         "✓ 对话已导出到 %s",
+	"slash.no_sysinfo":       "没有系统信息 (已禁用或未收集)",
+	"slash.run_usage":        "用法: /run <命令>",
+	"slash.nothing_to_retry": "没有可以重试的问题",
+	"slash.lang_current":     "语言: %s",
+	"slash.lang_switched":    "✓ 语言: %s",
+
+	// Local command classification
+	"executor.classified_modify":   "⚠ 模型将其标记为查询命令，但它看起来是修改类命令 (%s)，将按修改类命令处理",
+	"executor.classified_readonly": "提示: 模型将其标记为修改类命令，但它看起来是只读命令，仍按修改类命令处理",
+
+	// Auto-approve mode
+	"executor.auto_approved":          "已自动批准 (策略: %s)",
+	"executor.auto_skipped":           "已跳过: %s",
+	"executor.auto_budget_exhausted":  "步数预算已用完 (--max-steps %d)",
+	"executor.auto_modify_refused":    "修改类命令不会自动批准 (需要 --auto-approve=all 且匹配 --allow 规则)",
+	"executor.auto_not_allowed":       "修改类命令不匹配任何 --allow 规则",
+	"interactive.auto_report_title":   "自动批准报告 (策略: %s，已用 %d/%d 步)",
+	"interactive.auto_report_empty":   "没有建议的命令",
+	"interactive.auto_report_summary": "执行 %d 条，失败 %d 条，跳过 %d 条，黑名单拒绝 %d 条",
 
 	// Blacklist messages
 	"executor.blacklisted":        "✗ 命令被拒绝: 该命令匹配黑名单规则 '%s'，禁止执行",
@@ -44,7 +135,7 @@ var ChineseMessages = map[string]string{
 
 	// Error messages
 	"error.no_models":      "✗ 错误: 未配置任何模型",
-	"error.hint_no_models": "请先编辑配置文件: ~/.aiassist/config.yaml",
+	"error.hint_no_models": "请使用 'aiassist config provider add' 添加 Provider，或编辑配置文件: ~/.aiassist/config.yaml",
 	"error.general":        "✗ 错误: %v",
 
 	// Version messages
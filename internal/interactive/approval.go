@@ -0,0 +1,177 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/blacklist"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// ApprovalPolicy decides which commands run without asking the user
+type ApprovalPolicy string
+
+const (
+	// ApproveNone asks the user to confirm every command
+	ApproveNone ApprovalPolicy = "none"
+	// ApproveQuery runs query commands without asking and never runs modify commands
+	ApproveQuery ApprovalPolicy = "query"
+	// ApproveAll additionally runs modify commands that match the allowlist
+	ApproveAll ApprovalPolicy = "all"
+)
+
+// DefaultMaxSteps is the default number of commands executed in auto-approve mode
+const DefaultMaxSteps = 20
+
+// ParseApprovalPolicy validates an --auto-approve value
+func ParseApprovalPolicy(value string) (ApprovalPolicy, error) {
+	switch policy := ApprovalPolicy(value); policy {
+	case ApproveNone, ApproveQuery, ApproveAll:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid auto-approve policy %q (supported: none, query, all)", value)
+}
+
+// AutoApproveOptions configures non-interactive command execution.
+// Blacklisted commands are always refused; modify commands only run with
+// ApproveAll and when they match an Allow pattern (blacklist pattern syntax).
+type AutoApproveOptions struct {
+	Policy   ApprovalPolicy
+	Allow    []string
+	MaxSteps int // Maximum number of commands to execute
+}
+
+// autoDecision records what happened to a command in auto-approve mode
+type autoDecision struct {
+	command string
+	cmdType executor.CommandType
+	outcome string // executed, failed, skipped or blacklisted
+	reason  string
+}
+
+// SetAutoApprove enables non-interactive command execution. The session never
+// prompts while a policy other than ApproveNone is set.
+func (s *Session) SetAutoApprove(opts AutoApproveOptions) {
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = DefaultMaxSteps
+	}
+	s.autoApprove = opts
+}
+
+// autoMode reports whether commands are approved by policy instead of the user
+func (s *Session) autoMode() bool {
+	return s.autoApprove.Policy != "" && s.autoApprove.Policy != ApproveNone
+}
+
+// approveByPolicy decides whether a (non-blacklisted) command may run without asking.
+// It returns the reason when the command is refused.
+func (s *Session) approveByPolicy(cmd executor.Command) (bool, string) {
+	if s.autoSteps >= s.autoApprove.MaxSteps {
+		return false, s.translator.T("executor.auto_budget_exhausted", s.autoApprove.MaxSteps)
+	}
+
+	if cmd.Type == executor.ModifyCommand {
+		if s.autoApprove.Policy != ApproveAll {
+			return false, s.translator.T("executor.auto_modify_refused")
+		}
+		if !isAllowed(s.autoApprove.Allow, cmd.Text) {
+			return false, s.translator.T("executor.auto_not_allowed")
+		}
+	}
+
+	s.autoSteps++
+	return true, ""
+}
+
+// approveCommand decides whether to run a command: by policy in auto-approve mode,
+// otherwise by asking the user. It returns the command to run, which the user may
+// have edited.
+func (s *Session) approveCommand(cmd executor.Command) (executor.Command, bool, error) {
+	if !s.autoMode() {
+		return s.confirmCommandExecution(cmd)
+	}
+
+	approved, reason := s.approveByPolicy(cmd)
+	if !approved {
+		color.Yellow(s.translator.T("executor.auto_skipped", reason) + "\n")
+		s.recordAuto(cmd, "skipped", reason)
+		return cmd, false, nil
+	}
+	color.Green(s.translator.T("executor.auto_approved", s.autoApprove.Policy) + "\n")
+	return cmd, true, nil
+}
+
+// isAllowed reports whether command matches an allowlist pattern. Patterns without a
+// trailing "*" must match the whole command, and commands chaining or redirecting
+// with shell operators are never allowed, so "systemctl restart nginx" can't be
+// extended with "; rm -rf /".
+func isAllowed(allow []string, command string) bool {
+	if strings.ContainsAny(command, ";&|<>`$()\n") {
+		return false
+	}
+
+	for _, pattern := range allow {
+		if !blacklist.Match(pattern, command) {
+			continue
+		}
+		if strings.HasSuffix(strings.TrimSpace(pattern), "*") || len(strings.Fields(pattern)) == len(strings.Fields(command)) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAuto adds a command outcome to the final auto-approve report
+func (s *Session) recordAuto(cmd executor.Command, outcome, reason string) {
+	if !s.autoMode() {
+		return
+	}
+	s.autoDecisions = append(s.autoDecisions, autoDecision{
+		command: cmd.Text,
+		cmdType: cmd.Type,
+		outcome: outcome,
+		reason:  reason,
+	})
+}
+
+// RunAutonomous answers a question without prompting: commands are executed
+// according to the auto-approve policy, then a final report is printed.
+func (s *Session) RunAutonomous(question string) error {
+	fmt.Printf("[%s]: %s\n", s.translator.T("interactive.user_label"), question)
+	err := s.processQuestion(question)
+	s.PrintAutoReport()
+	return err
+}
+
+// PrintAutoReport prints the commands executed or refused in auto-approve mode
+func (s *Session) PrintAutoReport() {
+	fmt.Println()
+	color.Cyan(ui.Separator() + "\n")
+	color.Cyan(s.translator.T("interactive.auto_report_title", s.autoApprove.Policy, s.autoSteps, s.autoApprove.MaxSteps) + "\n")
+	color.Cyan(ui.Separator() + "\n")
+
+	if len(s.autoDecisions) == 0 {
+		fmt.Println(s.translator.T("interactive.auto_report_empty"))
+		return
+	}
+
+	counts := make(map[string]int)
+	for i, d := range s.autoDecisions {
+		counts[d.outcome]++
+		line := fmt.Sprintf("%d. [%s] %s", i+1, d.cmdType, d.command)
+		switch d.outcome {
+		case "executed":
+			color.Green("✓ %s\n", line)
+		case "failed":
+			color.Red("✗ %s (%s)\n", line, d.reason)
+		default:
+			color.Yellow("- %s (%s)\n", line, d.reason)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(s.translator.T("interactive.auto_report_summary",
+		counts["executed"], counts["failed"], counts["skipped"], counts["blacklisted"]))
+}
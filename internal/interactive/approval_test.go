@@ -0,0 +1,134 @@
+package interactive
+
+import (
+	"testing"
+
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/i18n"
+)
+
+func TestApproveByPolicy(t *testing.T) {
+	query := executor.Command{Text: "systemctl status nginx", Type: executor.QueryCommand}
+	restart := executor.Command{Text: "systemctl restart nginx", Type: executor.ModifyCommand}
+	// Tagged query by the model, upgraded by the local classifier
+	upgraded := executor.Command{Text: "systemctl restart nginx", Type: executor.ModifyCommand, ModelType: executor.QueryCommand}
+
+	tests := []struct {
+		name   string
+		opts   AutoApproveOptions
+		cmd    executor.Command
+		want   bool
+		reason string
+	}{
+		{name: "query command", opts: AutoApproveOptions{Policy: ApproveQuery}, cmd: query, want: true},
+		{
+			name: "modify command with query policy", cmd: restart,
+			opts:   AutoApproveOptions{Policy: ApproveQuery, Allow: []string{"systemctl restart nginx"}},
+			reason: "executor.auto_modify_refused",
+		},
+		{
+			name: "upgraded command with query policy", cmd: upgraded,
+			opts:   AutoApproveOptions{Policy: ApproveQuery},
+			reason: "executor.auto_modify_refused",
+		},
+		{
+			name: "modify command without allowlist", cmd: restart,
+			opts:   AutoApproveOptions{Policy: ApproveAll},
+			reason: "executor.auto_not_allowed",
+		},
+		{
+			name: "modify command not in allowlist", cmd: restart,
+			opts:   AutoApproveOptions{Policy: ApproveAll, Allow: []string{"docker restart *"}},
+			reason: "executor.auto_not_allowed",
+		},
+		{
+			name: "allowed modify command", cmd: restart,
+			opts: AutoApproveOptions{Policy: ApproveAll, Allow: []string{"systemctl restart *"}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := i18n.New(config.LanguageEnglish)
+			s := &Session{translator: translator}
+			s.SetAutoApprove(tt.opts)
+
+			got, reason := s.approveByPolicy(tt.cmd)
+			if got != tt.want {
+				t.Fatalf("approveByPolicy(%q) = %v, %q, want %v", tt.cmd.Text, got, reason, tt.want)
+			}
+			if tt.reason != "" && reason != translator.T(tt.reason) {
+				t.Errorf("approveByPolicy(%q) reason = %q, want %q", tt.cmd.Text, reason, translator.T(tt.reason))
+			}
+			// Only approved commands use the step budget
+			if approvedSteps := s.autoSteps == 1; approvedSteps != tt.want {
+				t.Errorf("autoSteps = %d after approved = %v", s.autoSteps, tt.want)
+			}
+		})
+	}
+}
+
+func TestApproveByPolicyBudget(t *testing.T) {
+	translator := i18n.New(config.LanguageEnglish)
+	s := &Session{translator: translator}
+	s.SetAutoApprove(AutoApproveOptions{Policy: ApproveQuery, MaxSteps: 2})
+
+	query := executor.Command{Text: "uptime", Type: executor.QueryCommand}
+	modify := executor.Command{Text: "reboot", Type: executor.ModifyCommand}
+
+	// Refused commands don't use the budget
+	if ok, _ := s.approveByPolicy(modify); ok {
+		t.Fatal("approveByPolicy() approved a modify command")
+	}
+	for i := range 2 {
+		if ok, reason := s.approveByPolicy(query); !ok {
+			t.Fatalf("approveByPolicy() step %d refused: %s", i+1, reason)
+		}
+	}
+
+	ok, reason := s.approveByPolicy(query)
+	if want := translator.T("executor.auto_budget_exhausted", 2); ok || reason != want {
+		t.Errorf("approveByPolicy() after the budget = %v, %q, want false, %q", ok, reason, want)
+	}
+	if s.autoSteps != 2 {
+		t.Errorf("autoSteps = %d, want 2", s.autoSteps)
+	}
+
+	// Refusals are reported without prompting
+	cmd, ok, err := s.approveCommand(query)
+	if ok || err != nil || cmd.Text != query.Text {
+		t.Errorf("approveCommand() = %q, %v, %v, want a refusal", cmd.Text, ok, err)
+	}
+	if n := len(s.autoDecisions); n != 1 || s.autoDecisions[0].outcome != "skipped" {
+		t.Errorf("autoDecisions = %+v, want one skipped command", s.autoDecisions)
+	}
+}
+
+func TestIsAllowed(t *testing.T) {
+	allow := []string{"systemctl restart nginx", "docker restart *"}
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"systemctl restart nginx", true},
+		{"/usr/bin/systemctl restart nginx", true},
+		{"docker restart web", true},
+		{"docker restart web db", true},
+		{"docker restart", false},
+		{"systemctl restart nginx mysql", false},
+		{"systemctl restart nginx; rm -rf /", false},
+		{"systemctl restart nginx && reboot", false},
+		{"docker restart $(docker ps -q)", false},
+		{"docker restart web > /etc/passwd", false},
+		{"systemctl stop nginx", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAllowed(allow, tt.command); got != tt.want {
+			t.Errorf("isAllowed(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
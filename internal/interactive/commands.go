@@ -0,0 +1,274 @@
+package interactive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
+)
+
+const (
+	// maxHistoryLine limits the length of each message listed by /history
+	maxHistoryLine = 120
+
+	sessionsDir = "sessions"
+)
+
+// errExit ends the interactive loop, see /exit
+var errExit = errors.New("exit")
+
+// slashCommand is a command typed in the interactive loop, like /model
+type slashCommand struct {
+	name string
+	args string // Argument synopsis for /help, e.g. "<name>"
+	run  func(s *Session, arg string) error
+}
+
+// slashCommands returns the commands of the interactive loop, in the order of /help
+func slashCommands() []slashCommand {
+	return []slashCommand{
+		{name: "/help", run: (*Session).cmdHelp},
+		{name: "/exit", run: func(*Session, string) error { return errExit }},
+		{name: "/clear", run: (*Session).cmdClear},
+		{name: "/model", args: "<provider/model>", run: (*Session).cmdModel},
+		{name: "/models", run: (*Session).cmdModels},
+		{name: "/history", run: (*Session).cmdHistory},
+		{name: "/save", args: "[file]", run: (*Session).cmdSave},
+		{name: "/export", args: "[file]", run: (*Session).cmdExport},
+		{name: "/sysinfo", run: (*Session).cmdSysinfo},
+		{name: "/run", args: "<command>", run: (*Session).cmdRun},
+		{name: "/retry", run: (*Session).cmdRetry},
+		{name: "/lang", args: "<en|zh>", run: (*Session).cmdLang},
+	}
+}
+
+// runSlashCommand runs input if it starts with the name of a slash command, and
+// reports whether it did. Any other input, like "/tmp is full, why?", is a question.
+func (s *Session) runSlashCommand(input string) (bool, error) {
+	name, arg, _ := strings.Cut(input, " ")
+	arg = strings.TrimSpace(arg)
+	if (name == "exit" || name == "quit" || name == "/quit") && arg == "" {
+		return true, errExit
+	}
+
+	for _, cmd := range slashCommands() {
+		if cmd.name == name {
+			return true, cmd.run(s, arg)
+		}
+	}
+	return false, nil
+}
+
+// completions returns the suggestions for tab completion in the interactive loop
+func (s *Session) completions() []string {
+	var suggestions []string
+	for _, cmd := range slashCommands() {
+		suggestions = append(suggestions, cmd.name)
+	}
+	for _, name := range s.llmManager.ModelNames() {
+		suggestions = append(suggestions, "/model "+name)
+	}
+	return append(suggestions, "/lang "+config.LanguageEnglish, "/lang "+config.LanguageChinese)
+}
+
+func (s *Session) cmdHelp(string) error {
+	fmt.Println(s.translator.T("slash.help_title"))
+	for _, cmd := range slashCommands() {
+		usage := strings.TrimSpace(cmd.name + " " + cmd.args)
+		fmt.Printf("  %-26s %s\n", usage, s.translator.T("slash.help_"+cmd.name[1:]))
+	}
+	return nil
+}
+
+// cmdClear starts a new conversation, keeping the system information
+func (s *Session) cmdClear(string) error {
+	history := s.history[:0]
+	for _, msg := range s.history {
+		if msg.Role == "system" && msg.Sources == nil {
+			history = append(history, msg)
+		}
+	}
+	s.history = history
+	s.lastQuestion = ""
+	color.Green(s.translator.T("slash.cleared") + "\n")
+	return nil
+}
+
+func (s *Session) cmdModel(name string) error {
+	if name == "" {
+		return s.cmdModels("")
+	}
+	if err := s.llmManager.SetPrimaryModel(name); err != nil {
+		return fmt.Errorf("%w (available: %s)", err, strings.Join(s.llmManager.ModelNames(), ", "))
+	}
+	color.Green(s.translator.T("slash.model_switched", name) + "\n")
+	return nil
+}
+
+// cmdModels lists the models in the order they are tried
+func (s *Session) cmdModels(string) error {
+	fmt.Println(s.translator.T("slash.models_title"))
+	for i, name := range s.llmManager.ModelNames() {
+		if i == 0 {
+			color.Cyan("* %s\n", name)
+		} else {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	return nil
+}
+
+// cmdHistory lists the questions, answers and command results of the conversation
+func (s *Session) cmdHistory(string) error {
+	n := 0
+	for _, msg := range s.history {
+		if msg.Role == "system" {
+			continue
+		}
+		n++
+		label := s.translator.T("interactive.user_label")
+		if msg.Role == "assistant" {
+			label = s.translator.T("interactive.ai_label")
+		}
+		line, _, _ := strings.Cut(strings.TrimSpace(msg.Content), "\n")
+		if runes := []rune(line); len(runes) > maxHistoryLine {
+			line = string(runes[:maxHistoryLine]) + "…"
+		}
+		fmt.Printf("%3d [%s]: %s\n", n, label, line)
+	}
+	if n == 0 {
+		fmt.Println(s.translator.T("slash.history_empty"))
+	}
+	return nil
+}
+
+// savedSession is the format of /save
+type savedSession struct {
+	SavedAt  time.Time        `json:"saved_at"`
+	Models   []string         `json:"models"`
+	Language string           `json:"language"`
+	Target   string           `json:"target,omitempty"`
+	History  []SessionMessage `json:"history"`
+}
+
+// cmdSave saves the conversation with its context as JSON, by default to
+// ~/.aiassist/sessions/<time>.json
+func (s *Session) cmdSave(path string) error {
+	if path == "" {
+		path = filepath.Join(config.Get().ConfigDir, sessionsDir, time.Now().Format("20060102-150405")+".json")
+	}
+	data, err := json.MarshalIndent(savedSession{
+		SavedAt:  time.Now(),
+		Models:   s.llmManager.ModelNames(),
+		Language: s.translator.GetLanguage(),
+		Target:   s.target(),
+		History:  s.history,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// The conversation may contain secrets, it is stored unredacted
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	color.Green(s.translator.T("slash.saved", path) + "\n")
+	return nil
+}
+
+// cmdExport writes the questions, answers and command results as Markdown, by
+// default to aiassist-<time>.md in the current directory
+func (s *Session) cmdExport(path string) error {
+	if path == "" {
+		path = "aiassist-" + time.Now().Format("20060102-150405") + ".md"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# aiassist %s\n", time.Now().Format(time.DateTime))
+	for _, msg := range s.history {
+		switch msg.Role {
+		case "user":
+			// Command results and piped data keep their layout in a code block
+			content := strings.TrimSpace(msg.Content)
+			if strings.Contains(content, "\n") {
+				content = "```\n" + content + "\n```"
+			}
+			fmt.Fprintf(&sb, "\n## %s\n\n%s\n", s.translator.T("interactive.user_label"), content)
+		case "assistant":
+			fmt.Fprintf(&sb, "\n## %s\n\n%s\n", s.translator.T("interactive.ai_label"), strings.TrimSpace(msg.Content))
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to export conversation: %w", err)
+	}
+	color.Green(s.translator.T("slash.exported", path) + "\n")
+	return nil
+}
+
+// cmdSysinfo shows the system information sent to the model
+func (s *Session) cmdSysinfo(string) error {
+	for _, msg := range s.history {
+		if msg.Role == "system" && strings.HasPrefix(msg.Content, "[System Environment]") {
+			fmt.Println(msg.Content)
+			return nil
+		}
+	}
+	fmt.Println(s.translator.T("slash.no_sysinfo"))
+	return nil
+}
+
+// cmdRun runs a command typed by the user like a suggested one: it is classified
+// and checked against the blacklist, modify commands are confirmed, and the model
+// analyzes the output
+func (s *Session) cmdRun(text string) error {
+	if text == "" {
+		color.Yellow(s.translator.T("slash.run_usage") + "\n")
+		return nil
+	}
+
+	cmd := executor.NewCommand(text, executor.QueryCommand)
+	results, err := s.runCommands([]executor.Command{cmd}, true)
+	if err != nil || len(results) == 0 {
+		return err
+	}
+
+	s.recursionDepth++
+	defer func() { s.recursionDepth-- }()
+	return s.analyzeCommandOutput(results[0])
+}
+
+// cmdRetry asks the last question again, dropping the answer and what followed
+func (s *Session) cmdRetry(string) error {
+	if s.lastQuestion == "" {
+		color.Yellow(s.translator.T("slash.nothing_to_retry") + "\n")
+		return nil
+	}
+	s.history = s.history[:min(s.lastQuestionIndex, len(s.history))]
+	fmt.Printf("[%s]: %s\n", s.translator.T("interactive.user_label"), s.lastQuestion)
+	return s.processQuestion(s.lastQuestion)
+}
+
+// cmdLang switches the language of messages and answers
+func (s *Session) cmdLang(lang string) error {
+	if lang == "" {
+		fmt.Println(s.translator.T("slash.lang_current", s.translator.GetLanguage()))
+		return nil
+	}
+	if err := config.Get().ApplyOverrides(config.Overrides{Language: lang}); err != nil {
+		return err
+	}
+	s.translator.SetLanguage(lang)
+	s.llmManager.SetLanguage(lang)
+	color.Green(s.translator.T("slash.lang_switched", lang) + "\n")
+	return nil
+}
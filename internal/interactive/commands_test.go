@@ -0,0 +1,66 @@
+package interactive
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/i18n"
+	"github.com/llaoj/aiassist/internal/llm"
+)
+
+func TestRunSlashCommand(t *testing.T) {
+	manager := llm.NewManager(&config.Config{})
+	for _, name := range []string{"a/one", "b/two", "b/three"} {
+		manager.RegisterModel(llm.NewOpenAICompatibleModel(name, "http://127.0.0.1:1", "", name))
+	}
+	s := &Session{
+		llmManager: manager,
+		translator: i18n.New(config.LanguageEnglish),
+		history: []SessionMessage{
+			{Role: "system", Content: "[System Environment]\nOS: Linux"},
+			{Role: "system", Content: "[Runbooks]\n...", Sources: []string{"nginx.md:3"}},
+			{Role: "user", Content: "why is nginx down?"},
+			{Role: "assistant", Content: "Check the logs"},
+		},
+		lastQuestion: "why is nginx down?",
+	}
+
+	tests := []struct {
+		input   string
+		handled bool
+		err     error
+	}{
+		{"/var/log/syslog is huge, why?", false, nil},
+		{"exit code 137 means what?", false, nil},
+		{"exit", true, errExit},
+		{"/exit", true, errExit},
+		{"/unknown", false, nil},
+		{"/tmp is full, why?", false, nil},
+		{"/etc/hosts wrong?", false, nil},
+		{"/model b/three", true, nil},
+	}
+	for _, tt := range tests {
+		handled, err := s.runSlashCommand(tt.input)
+		if handled != tt.handled || !errors.Is(err, tt.err) {
+			t.Errorf("runSlashCommand(%q) = %v, %v, want %v, %v", tt.input, handled, err, tt.handled, tt.err)
+		}
+	}
+
+	// The chosen model is tried first, the others remain fallbacks in order
+	if got, want := manager.ModelNames(), []string{"b/three", "a/one", "b/two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ModelNames() after /model = %q, want %q", got, want)
+	}
+	if _, err := s.runSlashCommand("/model c/four"); err == nil {
+		t.Error("/model of an unknown model returned no error")
+	}
+
+	// /clear keeps the system information only
+	if _, err := s.runSlashCommand("/clear"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.history) != 1 || s.history[0].Content != "[System Environment]\nOS: Linux" || s.lastQuestion != "" {
+		t.Errorf("history after /clear = %+v", s.history)
+	}
+}
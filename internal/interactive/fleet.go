@@ -0,0 +1,157 @@
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// SetFleet makes the session run query commands on all hosts of runners in
+// parallel, at most parallel hosts at a time, and analyze their outputs together.
+// Modify commands are refused. Hosts that can't be used are reported as a warning.
+func (s *Session) SetFleet(runners []executor.Runner, parallel int) error {
+	fleet, err := s.executor.NewFleet(runners, parallel)
+	if fleet.Size() == 0 {
+		return errors.Join(errors.New("no host of the fleet is usable"), err)
+	}
+	if err != nil {
+		color.Yellow("Warning: %v\n", err)
+	}
+	s.fleet = fleet
+
+	// The hosts of a fleet are alike: the first one stands for all
+	first := fleet.Targets()[0]
+	for _, runner := range runners {
+		if runner.Target() == first {
+			s.useRemoteSysinfo(runner)
+			break
+		}
+	}
+	return nil
+}
+
+// shellContext returns the shell state where commands run, for the model
+func (s *Session) shellContext() string {
+	if s.fleet != nil {
+		return s.fleet.FormatAsContext()
+	}
+	return s.executor.ShellContext()
+}
+
+// refuseOnFleet refuses modify commands in fleet mode: changes are made host by
+// host, not fanned out. It returns the rejection for the model.
+func (s *Session) refuseOnFleet(cmd executor.Command) (string, bool) {
+	if s.fleet == nil || cmd.Type != executor.ModifyCommand {
+		return "", false
+	}
+
+	color.Yellow(s.translator.T("executor.fleet_modify_refused") + "\n")
+	s.recordAuto(cmd, "skipped", "modify commands are not run on a fleet")
+	return fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s",
+		s.translator.T("interactive.executed_command"), cmd.Text,
+		"Fleet Rejection", s.translator.T("executor.fleet_modify_refused")), true
+}
+
+// runFleetCommand executes an approved query command on every host of the fleet,
+// prints the outputs and a summary of exit codes, and returns the execution result
+// for the model with the output of each host.
+func (s *Session) runFleetCommand(cmd executor.Command, suggested string) string {
+	execStop := ui.StartSpinner(s.translator.T("executor.executing_fleet", s.fleet.Size()))
+	results := s.fleet.Execute(cmd.Text)
+	if execStop != nil {
+		execStop()
+	}
+
+	var sb strings.Builder
+	if cmd.Text != suggested {
+		sb.WriteString(fmt.Sprintf("[%s]\n%s\n\n", s.translator.T("interactive.edited_command"), suggested))
+	}
+	sb.WriteString(fmt.Sprintf("[%s]\n%s\n\n", s.translator.T("interactive.executed_command"), cmd.Text))
+
+	// Share the context between hosts, so the model sees all of them
+	maxChars := MaxContextChars / len(results)
+	failed := 0
+	for _, r := range results {
+		output := r.Output
+		if output == "" {
+			output = s.translator.T("executor.no_output")
+		}
+		header := s.translator.T("interactive.fleet_host_output", r.Target, executor.FormatExitCode(r.ExitCode))
+
+		fmt.Println()
+		if r.Err != nil {
+			failed++
+			color.Red("[%s]:\n", header)
+		} else {
+			color.Cyan("[%s]:\n", header)
+		}
+		fmt.Println(output)
+
+		// The model gets plain text without colors and terminal control sequences
+		output = s.truncateOutput(executor.CleanTerminalOutput(output), maxChars)
+		sb.WriteString(fmt.Sprintf("[%s]\n%s\n", header, output))
+		if r.Err != nil && r.ExitCode < 0 {
+			sb.WriteString(fmt.Sprintf("[%s]\n%s\n", s.translator.T("interactive.execution_error"), r.Err.Error()))
+		}
+		sb.WriteString("\n")
+	}
+
+	summary := formatFleetSummary(results)
+	fmt.Println()
+	fmt.Println(s.translator.T("interactive.fleet_summary"))
+	for i, line := range strings.Split(strings.TrimSuffix(summary, "\n"), "\n") {
+		switch {
+		case i == 0:
+			fmt.Println(line)
+		case results[i-1].Err != nil:
+			color.Red(line)
+		default:
+			color.Green(line)
+		}
+	}
+	sb.WriteString(fmt.Sprintf("[%s]\n%s", s.translator.T("interactive.fleet_summary"), summary))
+
+	if failed > 0 {
+		s.recordAuto(cmd, "failed", fmt.Sprintf("failed on %d of %d hosts", failed, len(results)))
+	} else {
+		s.recordAuto(cmd, "executed", "")
+	}
+	return sb.String()
+}
+
+// formatFleetSummary formats a table with the exit code and duration on each host,
+// one line per host. Hosts where the command couldn't run show the error instead of
+// an exit code.
+func formatFleetSummary(results []executor.HostResult) string {
+	width := len("HOST")
+	for _, r := range results {
+		width = max(width, len(r.Target))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-*s  %4s  %8s\n", width, "HOST", "EXIT", "TIME"))
+	for _, r := range results {
+		line := fmt.Sprintf("%-*s  %4s  %8s", width, r.Target, executor.FormatExitCode(r.ExitCode), r.Duration.Round(10*time.Millisecond))
+		if r.ExitCode < 0 && r.Err != nil {
+			line += "  " + singleLine(r.Err.Error())
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// singleLine joins the non-empty lines of text, e.g. of joined errors, with "; "
+func singleLine(text string) string {
+	var parts []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
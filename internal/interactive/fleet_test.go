@@ -0,0 +1,28 @@
+package interactive
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llaoj/aiassist/internal/executor"
+)
+
+func TestFormatFleetSummary(t *testing.T) {
+	results := []executor.HostResult{
+		{Target: "admin@web1", ExitCode: 0, Duration: 312 * time.Millisecond},
+		{Target: "admin@web-canary", ExitCode: 1, Err: errors.New("Process exited with status 1"), Duration: 2 * time.Second},
+		{Target: "admin@web3", ExitCode: -1, Err: errors.New("connection lost"), Duration: 5 * time.Millisecond},
+		{Target: "admin@web4", ExitCode: -1, Err: errors.Join(errors.New("dial tcp: timeout"), errors.New("ssh: handshake failed\n")), Duration: 0},
+	}
+
+	// Each host is on one line, so lines can be colored by result
+	want := "HOST              EXIT      TIME\n" +
+		"admin@web1           0     310ms\n" +
+		"admin@web-canary     1        2s\n" +
+		"admin@web3           -      10ms  connection lost\n" +
+		"admin@web4           -        0s  dial tcp: timeout; ssh: handshake failed\n"
+	if got := formatFleetSummary(results); got != want {
+		t.Errorf("formatFleetSummary() =\n%s\nwant\n%s", got, want)
+	}
+}
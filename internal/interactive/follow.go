@@ -0,0 +1,200 @@
+package interactive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/prompt"
+)
+
+const (
+	// DefaultFollowWindowLines is the default number of lines per follow-mode window
+	DefaultFollowWindowLines = 200
+
+	// DefaultFollowWindowInterval is the default maximum time a follow-mode window stays open
+	DefaultFollowWindowInterval = 30 * time.Second
+
+	// maxFollowFindingsChars caps the previously reported findings carried between windows
+	maxFollowFindingsChars = 8000
+)
+
+// FollowOptions controls how follow mode splits the input stream into windows.
+// A window is analyzed when it reaches WindowLines lines or WindowInterval has
+// elapsed with at least one new line, whichever comes first.
+type FollowOptions struct {
+	WindowLines    int
+	WindowInterval time.Duration
+}
+
+// followAnalyzer analyzes one window given the findings reported so far, and returns
+// the response of the model
+type followAnalyzer func(findings, data string, windowNum, lineCount int) (string, error)
+
+// RunWithPipeFollow reads piped input incrementally (e.g. tail -f) and analyzes it in
+// rolling windows, reporting only findings that are new compared to earlier windows.
+// It runs until the input ends or the user presses Ctrl+C.
+func (s *Session) RunWithPipeFollow(initialQuestion string, opts FollowOptions) error {
+	// Every window is analyzed with the same base context (system info)
+	// plus the findings reported so far, instead of the whole history
+	baseHistory := s.history[:len(s.history):len(s.history)]
+	return s.follow(os.Stdin, opts, func(findings, data string, windowNum, lineCount int) (string, error) {
+		return s.analyzeFollowWindow(baseHistory, initialQuestion, findings, data, windowNum, lineCount)
+	})
+}
+
+// follow splits r into windows and analyzes each with analyzeWindow until r ends
+func (s *Session) follow(r io.Reader, opts FollowOptions, analyzeWindow followAnalyzer) error {
+	if opts.WindowLines <= 0 {
+		opts.WindowLines = DefaultFollowWindowLines
+	}
+	if opts.WindowInterval <= 0 {
+		opts.WindowInterval = DefaultFollowWindowInterval
+	}
+
+	lines := make(chan string, opts.WindowLines*4)
+	readErr := make(chan error, 1)
+	go readLines(r, lines, readErr)
+
+	color.Cyan(s.translator.T("interactive.follow_started", opts.WindowLines, opts.WindowInterval) + "\n")
+
+	var findings string
+	var window []string
+	windowBytes := 0
+	windowNum := 0
+
+	ticker := time.NewTicker(opts.WindowInterval)
+	defer ticker.Stop()
+
+	analyze := func() error {
+		if len(window) == 0 {
+			return nil
+		}
+		windowNum++
+		data := strings.Join(window, "\n")
+		lineCount := len(window)
+		window = window[:0]
+		windowBytes = 0
+
+		response, err := analyzeWindow(findings, data, windowNum, lineCount)
+		// The next window starts now: a tick during a long analysis would
+		// send the few lines that arrived meanwhile right away
+		ticker.Reset(opts.WindowInterval)
+		if err != nil {
+			return err
+		}
+		if hasNewFindings(response) {
+			findings = appendFindings(findings, windowNum, response)
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if err := analyze(); err != nil {
+					return err
+				}
+				if err := <-readErr; err != nil {
+					return err
+				}
+				fmt.Println()
+				color.Green(s.translator.T("interactive.follow_ended") + "\n")
+				return nil
+			}
+
+			window = append(window, line)
+			windowBytes += len(line) + 1
+			if len(window) >= opts.WindowLines || windowBytes >= MaxContextChars {
+				if err := analyze(); err != nil {
+					color.Red("Error: %v\n", err)
+				}
+			}
+
+		case <-ticker.C:
+			if err := analyze(); err != nil {
+				color.Red("Error: %v\n", err)
+			}
+		}
+	}
+}
+
+// analyzeFollowWindow sends one window to the model and displays new findings.
+// It returns the response.
+func (s *Session) analyzeFollowWindow(baseHistory []SessionMessage, question, findings, data string, windowNum, lineCount int) (string, error) {
+	var msg strings.Builder
+	msg.WriteString(s.translator.T("interactive.pipe_source") + "\n")
+	if question != "" {
+		msg.WriteString(s.translator.T("interactive.pipe_user_question") + question + "\n")
+	}
+	msg.WriteString("\n" + s.translator.T("interactive.follow_previous_findings") + "\n")
+	if findings == "" {
+		msg.WriteString(s.translator.T("interactive.follow_no_findings_yet") + "\n")
+	} else {
+		msg.WriteString(findings + "\n")
+	}
+	msg.WriteString("\n" + s.translator.T("interactive.follow_window_data", windowNum, lineCount) + "\n")
+	msg.WriteString(s.truncateOutput(data, MaxContextChars))
+
+	s.history = append(baseHistory, SessionMessage{Role: "user", Content: msg.String()})
+	response, modelUsed, err := s.callLLM(prompt.GetFollowAnalysisPrompt())
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := time.Now().Format("15:04:05")
+	if !hasNewFindings(response) {
+		color.HiBlack("[%s] %s\n", timestamp, s.translator.T("interactive.follow_no_new", windowNum, lineCount))
+		return response, nil
+	}
+
+	fmt.Println()
+	color.Cyan("[%s] %s\n", timestamp, s.translator.T("interactive.follow_window", windowNum, lineCount))
+	s.displayResponse(modelUsed, response)
+	return response, nil
+}
+
+// hasNewFindings reports whether the model reported findings for a window
+func hasNewFindings(response string) bool {
+	return strings.TrimSpace(response) != "" && !strings.Contains(response, prompt.NoNewFindings)
+}
+
+// appendFindings adds a window's findings, dropping the oldest ones beyond the size cap
+func appendFindings(findings string, windowNum int, response string) string {
+	findings += fmt.Sprintf("[Window %d]\n%s\n", windowNum, strings.TrimSpace(response))
+	if len(findings) > maxFollowFindingsChars {
+		findings = findings[len(findings)-maxFollowFindingsChars:]
+		// Start at a line boundary
+		if i := strings.Index(findings, "\n"); i >= 0 {
+			findings = findings[i+1:]
+		}
+	}
+	return findings
+}
+
+// readLines sends each line from r to lines, closing lines at EOF.
+// Unlike bufio.Scanner it handles arbitrarily long lines.
+func readLines(r io.Reader, lines chan<- string, readErr chan<- error) {
+	defer close(lines)
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lines <- strings.TrimRight(line, "\r\n")
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			readErr <- err
+			return
+		}
+	}
+}
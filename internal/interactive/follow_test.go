@@ -0,0 +1,138 @@
+package interactive
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/i18n"
+	"github.com/llaoj/aiassist/internal/prompt"
+)
+
+// followCall records a window passed to the analyzer
+type followCall struct {
+	findings  string
+	data      string
+	lineCount int
+	at        time.Time
+}
+
+func TestFollowWindows(t *testing.T) {
+	s := &Session{translator: i18n.New(config.LanguageEnglish)}
+	r, w := io.Pipe()
+	go func() {
+		for i := 1; i <= 7; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		w.Close()
+	}()
+
+	// Windows 1 and 3 have findings, window 2 has nothing new
+	responses := []string{"disk sda is failing", prompt.NoNewFindings, "nginx restarts"}
+	var calls []followCall
+	err := s.follow(r, FollowOptions{WindowLines: 3, WindowInterval: time.Hour}, func(findings, data string, windowNum, lineCount int) (string, error) {
+		calls = append(calls, followCall{findings: findings, data: data, lineCount: lineCount})
+		return responses[windowNum-1], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data []string
+	for _, call := range calls {
+		data = append(data, call.data)
+	}
+	want := []string{"line 1\nline 2\nline 3", "line 4\nline 5\nline 6", "line 7"}
+	if !reflect.DeepEqual(data, want) {
+		t.Fatalf("windows = %q, want %q", data, want)
+	}
+	if calls[2].lineCount != 1 {
+		t.Errorf("line count of the last window = %d, want 1", calls[2].lineCount)
+	}
+
+	if calls[0].findings != "" {
+		t.Errorf("findings before window 1 = %q", calls[0].findings)
+	}
+	if want := "[Window 1]\ndisk sda is failing\n"; calls[1].findings != want || calls[2].findings != want {
+		t.Errorf("findings before windows 2 and 3 = %q, %q, want %q", calls[1].findings, calls[2].findings, want)
+	}
+}
+
+func TestFollowIntervalAfterSlowAnalysis(t *testing.T) {
+	s := &Session{translator: i18n.New(config.LanguageEnglish)}
+	const interval = 50 * time.Millisecond
+
+	// A stale tick competes with the line arriving during the analysis, so the
+	// order they are handled in is random: try a few times
+	for trial := 0; trial < 5; trial++ {
+		r, w := io.Pipe()
+		var calls []followCall
+		var slowDone time.Time
+		done := make(chan error, 1)
+		go func() {
+			done <- s.follow(r, FollowOptions{WindowLines: 100, WindowInterval: interval}, func(findings, data string, windowNum, lineCount int) (string, error) {
+				calls = append(calls, followCall{data: data, at: time.Now()})
+				if windowNum == 1 {
+					// Ticks fire while the model answers, and a line arrives
+					fmt.Fprintln(w, "second")
+					time.Sleep(3 * interval)
+					slowDone = time.Now()
+				} else {
+					w.Close()
+				}
+				return prompt.NoNewFindings, nil
+			})
+		}()
+
+		fmt.Fprintln(w, "first")
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+
+		if len(calls) != 2 || calls[0].data != "first" || calls[1].data != "second" {
+			t.Fatalf("windows = %+v, want first and second", calls)
+		}
+		if wait := calls[1].at.Sub(slowDone); wait < interval {
+			t.Fatalf("second window analyzed %v after the first analysis ended, want %v", wait, interval)
+		}
+	}
+}
+
+func TestAppendFindings(t *testing.T) {
+	findings := appendFindings("", 1, "  disk sda is failing\n")
+	if want := "[Window 1]\ndisk sda is failing\n"; findings != want {
+		t.Errorf("appendFindings() = %q, want %q", findings, want)
+	}
+
+	// The oldest findings are dropped at a line boundary
+	for i := 2; i <= 100; i++ {
+		findings = appendFindings(findings, i, strings.Repeat("错", 40))
+	}
+	if len(findings) > maxFollowFindingsChars {
+		t.Errorf("findings are %d bytes, want at most %d", len(findings), maxFollowFindingsChars)
+	}
+	if !strings.HasPrefix(findings, "[Window ") || !strings.HasSuffix(findings, "[Window 100]\n"+strings.Repeat("错", 40)+"\n") {
+		t.Errorf("appendFindings() = %q...", findings[:40])
+	}
+}
+
+func TestHasNewFindings(t *testing.T) {
+	tests := []struct {
+		response string
+		want     bool
+	}{
+		{"disk sda is failing", true},
+		{prompt.NoNewFindings, false},
+		{"  " + prompt.NoNewFindings + ".\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := hasNewFindings(tt.response); got != tt.want {
+			t.Errorf("hasNewFindings(%q) = %v, want %v", tt.response, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// maxFlagCorrections bounds how often the model is asked to correct the flags of an answer
+const maxFlagCorrections = 1
+
+// verifyFlags checks the flags of the commands suggested in response against the
+// help of their tools where commands run. When some don't exist, the help is given
+// back to the model to correct its answer, and the corrected answer is returned.
+// It does nothing unless --verify-flags or execution.verify_flags is set.
+func (s *Session) verifyFlags(systemPrompt, conversationContext, response, modelUsed string) (string, string) {
+	// Fleet hosts may run different versions of a tool
+	if !config.Get().VerifyFlagsEnabled() || s.fleet != nil {
+		return response, modelUsed
+	}
+
+	for attempt := 0; ; attempt++ {
+		var issues []executor.FlagIssue
+		for _, cmd := range s.executor.ExtractCommands(response) {
+			issues = append(issues, s.executor.CheckFlags(cmd.Text)...)
+		}
+		if len(issues) == 0 {
+			return response, modelUsed
+		}
+
+		for _, issue := range issues {
+			color.Yellow(s.translator.T("interactive.flags_unknown", issue.Tool, strings.Join(issue.Flags, " ")) + "\n")
+		}
+		if attempt == maxFlagCorrections {
+			color.Yellow(s.translator.T("interactive.flags_unverified") + "\n")
+			return response, modelUsed
+		}
+
+		correctionContext := fmt.Sprintf("%s[%s]: %s\n\n%s", conversationContext,
+			s.translator.T("interactive.ai_label"), response, formatFlagIssues(issues))
+		stopSpinner := ui.StartSpinner(s.translator.T("interactive.flags_correcting"))
+		corrected, correctedBy, err := s.sendToLLM(systemPrompt, correctionContext)
+		if stopSpinner != nil {
+			stopSpinner()
+		}
+		if err != nil {
+			color.Yellow("Warning: failed to correct the commands: %v\n", err)
+			return response, modelUsed
+		}
+		response, modelUsed = corrected, correctedBy
+	}
+}
+
+// formatFlagIssues asks the model to correct the commands, with the help of their tools
+func formatFlagIssues(issues []executor.FlagIssue) string {
+	var sb strings.Builder
+	sb.WriteString("[Flag Check]\n")
+	sb.WriteString("These flags of the suggested commands don't exist on the host the commands run on:\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&sb, "- %s: %s\n", issue.Tool, strings.Join(issue.Flags, " "))
+	}
+	shown := make(map[string]bool)
+	for _, issue := range issues {
+		if !shown[issue.Tool] {
+			shown[issue.Tool] = true
+			fmt.Fprintf(&sb, "\n[Help of %s]\n%s", issue.Tool, issue.Help)
+		}
+	}
+	sb.WriteString("\nGive your previous answer again in full, with the commands corrected to use only options documented above. Don't mention this check.\n")
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package interactive
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/kb"
+)
+
+// maxKBQueryChars limits the text searched in the runbooks, e.g. the head of piped logs
+const maxKBQueryChars = 2000
+
+// loadKnowledgeBase returns the runbook index, or nil when it is disabled or
+// wasn't built with aiassist kb index
+func loadKnowledgeBase() *kb.Index {
+	if !config.Get().KBEnabled() {
+		return nil
+	}
+	idx, err := kb.Load(kb.Path())
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			color.Yellow("Warning: failed to load runbooks: %v\n", err)
+		}
+		return nil
+	}
+	return idx
+}
+
+// addRunbooks adds the runbook sections relevant to query to the history, and
+// returns their sources. Sections already in the history aren't added again.
+func (s *Session) addRunbooks(query string) []string {
+	if s.kb == nil {
+		return nil
+	}
+	if runes := []rune(query); len(runes) > maxKBQueryChars {
+		query = string(runes[:maxKBQueryChars])
+	}
+
+	sent := make(map[string]bool)
+	for _, msg := range s.history {
+		for _, source := range msg.Sources {
+			sent[source] = true
+		}
+	}
+
+	var results []kb.Result
+	var sources []string
+	for _, r := range s.kb.Search(query, config.Get().GetKBTopK()) {
+		if !sent[r.Citation()] {
+			results = append(results, r)
+			sources = append(sources, r.Citation())
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	s.history = append(s.history, SessionMessage{Role: "system", Content: kb.FormatAsContext(results), Sources: sources})
+	return sources
+}
+
+// displayRunbooks shows the runbooks sent with a question
+func (s *Session) displayRunbooks(sources []string) {
+	if len(sources) > 0 {
+		color.HiBlack(s.translator.T("interactive.kb_sources", strings.Join(sources, ", ")) + "\n")
+	}
+}
@@ -0,0 +1,111 @@
+package interactive
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// Plan actions offered when the model proposes several commands
+const (
+	planRunQueries = iota
+	planChoose
+	planStep
+	planSkip
+)
+
+// Prompts choosing the commands of a plan, replaced in tests
+var (
+	promptSelect      = ui.PromptSelect
+	promptMultiSelect = ui.PromptMultiSelect
+)
+
+// runPlan lists all proposed commands and lets the user run all query commands,
+// choose a subset, step through them one by one, or skip them. It returns the
+// results of the commands that ran.
+func (s *Session) runPlan(commands []executor.Command) ([]string, error) {
+	s.displayPlan(commands)
+
+	var queries []executor.Command
+	for _, cmd := range commands {
+		if cmd.Type == executor.QueryCommand {
+			queries = append(queries, cmd)
+		}
+	}
+
+	var options []string
+	var actions []int
+	if len(queries) > 0 {
+		options = append(options, s.translator.T("executor.plan_run_queries", len(queries)))
+		actions = append(actions, planRunQueries)
+	}
+	options = append(options,
+		s.translator.T("executor.plan_choose"),
+		s.translator.T("executor.plan_step"),
+		s.translator.T("executor.plan_skip"))
+	actions = append(actions, planChoose, planStep, planSkip)
+
+	fmt.Println()
+	choice, err := promptSelect(s.targetPrompt("executor.plan_prompt"), options, s.translator)
+	s.exitOnInterrupt(err)
+	if err != nil {
+		return nil, err
+	}
+
+	switch actions[choice] {
+	case planRunQueries:
+		return s.runCommands(queries, true)
+	case planChoose:
+		chosen, err := s.chooseCommands(commands)
+		if err != nil {
+			return nil, err
+		}
+		return s.runCommands(chosen, true)
+	case planStep:
+		return s.runCommands(commands, false)
+	}
+	return nil, nil
+}
+
+// displayPlan prints the numbered list of proposed commands with their types
+func (s *Session) displayPlan(commands []executor.Command) {
+	fmt.Println()
+	color.Cyan(s.translator.T("executor.plan_title", len(commands)) + "\n")
+	for i, cmd := range commands {
+		_, colorFn := s.executor.GetCommandTypeInfo(cmd.Type, s.translator)
+		colorFn.Printf("%d. [%s] %s", i+1, cmd.Type, cmd.Text)
+		if blacklisted, _ := s.executor.IsBlacklisted(cmd.Text); blacklisted {
+			color.New(color.FgRed).Printf("  %s", s.translator.T("executor.plan_blacklisted"))
+		} else if cmd.Upgraded() {
+			color.New(color.FgYellow).Printf("  %s", s.translator.T("executor.plan_upgraded", cmd.Local.Reason))
+		}
+		fmt.Println()
+	}
+}
+
+// chooseCommands lets the user pick commands from the plan. Query commands are
+// preselected; the chosen commands keep their order.
+func (s *Session) chooseCommands(commands []executor.Command) ([]executor.Command, error) {
+	options := make([]string, len(commands))
+	preselected := make([]bool, len(commands))
+	for i, cmd := range commands {
+		options[i] = fmt.Sprintf("[%s] %s", cmd.Type, cmd.Text)
+		blacklisted, _ := s.executor.IsBlacklisted(cmd.Text)
+		preselected[i] = cmd.Type == executor.QueryCommand && !blacklisted
+	}
+
+	fmt.Println()
+	indices, err := promptMultiSelect(s.translator.T("executor.plan_choose_prompt"), options, preselected, s.translator)
+	s.exitOnInterrupt(err)
+	if err != nil {
+		return nil, err
+	}
+
+	chosen := make([]executor.Command, 0, len(indices))
+	for _, i := range indices {
+		chosen = append(chosen, commands[i])
+	}
+	return chosen, nil
+}
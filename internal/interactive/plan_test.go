@@ -0,0 +1,153 @@
+package interactive
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/i18n"
+	"github.com/llaoj/aiassist/internal/llm"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// scriptPlan answers the plan prompt with the option at choice, and the command
+// selection with chosen, recording the preselected commands
+func scriptPlan(t *testing.T, choice int, chosen []int) (preselected *[]bool) {
+	t.Helper()
+	savedSelect, savedMultiSelect := promptSelect, promptMultiSelect
+	t.Cleanup(func() { promptSelect, promptMultiSelect = savedSelect, savedMultiSelect })
+
+	preselected = new([]bool)
+	promptSelect = func(string, []string, *i18n.I18n) (int, error) {
+		return choice, nil
+	}
+	promptMultiSelect = func(_ string, _ []string, chosenFirst []bool, _ *i18n.I18n) ([]int, error) {
+		*preselected = chosenFirst
+		return chosen, nil
+	}
+	return preselected
+}
+
+// newPlanTestSession returns a session whose model answers without commands and
+// records the conversations it is sent
+func newPlanTestSession(t *testing.T) (*Session, func() []string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("commands run with sh")
+	}
+	initTestConfig(t, "language: en\nblacklist:\n  - reboot\nsysinfo:\n  disabled: true\n")
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, string(body))
+		mu.Unlock()
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"All good."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	manager := llm.NewManager(config.Get())
+	manager.RegisterModel(llm.NewOpenAICompatibleModel("fake/model", server.URL, "sk-test", "model"))
+	s := NewSession(manager, i18n.New(config.LanguageEnglish))
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestRunPlan(t *testing.T) {
+	s, _ := newPlanTestSession(t)
+	marker := filepath.Join(t.TempDir(), "modified")
+	commands := []executor.Command{
+		executor.NewCommand("echo first", executor.QueryCommand),
+		executor.NewCommand("touch "+marker, executor.QueryCommand), // Upgraded to modify
+		executor.NewCommand("echo second", executor.QueryCommand),
+		executor.NewCommand("reboot", executor.ModifyCommand),
+	}
+
+	tests := []struct {
+		name    string
+		choice  int // Offered: run queries, choose, step, skip
+		chosen  []int
+		choices []int    // Answers when a command is confirmed
+		want    []string // Outputs in the results, in order
+	}{
+		{name: "run queries", choice: 0, want: []string{"first", "second"}},
+		{name: "choose subset", choice: 1, chosen: []int{2}, want: []string{"second"}},
+		{
+			// Chosen modify commands are still confirmed
+			name: "choose modify, declined", choice: 1, chosen: []int{0, 1},
+			choices: []int{ui.ChoiceNo}, want: []string{"first"},
+		},
+		{
+			// The blacklist is checked even for chosen commands
+			name: "choose blacklisted", choice: 1, chosen: []int{3},
+			want: []string{"Blacklist Rejection"},
+		},
+		{name: "skip", choice: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preselected := scriptPlan(t, tt.choice, tt.chosen)
+			scriptPrompts(t, tt.choices, nil, nil)
+
+			results, err := s.runPlan(commands)
+			if err != nil {
+				t.Fatalf("runPlan() error = %v", err)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("runPlan() = %q, want %d results", results, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(results[i], want) {
+					t.Errorf("runPlan()[%d] = %q, want it to contain %q", i, results[i], want)
+				}
+			}
+			if _, err := os.Stat(marker); !os.IsNotExist(err) {
+				t.Errorf("runPlan() ran the modify command")
+			}
+			// Only query commands that aren't blacklisted are preselected
+			if tt.chosen != nil && !reflect.DeepEqual(*preselected, []bool{true, false, true, false}) {
+				t.Errorf("preselected = %v", *preselected)
+			}
+		})
+	}
+}
+
+func TestHandleCommandsPlan(t *testing.T) {
+	s, requests := newPlanTestSession(t)
+	scriptPlan(t, 0, nil)
+	scriptPrompts(t, nil, nil, nil)
+
+	err := s.handleCommands([]executor.Command{
+		executor.NewCommand("echo first-output", executor.QueryCommand),
+		executor.NewCommand("echo second-output", executor.QueryCommand),
+	})
+	if err != nil {
+		t.Fatalf("handleCommands() error = %v", err)
+	}
+
+	// The outputs of all commands are analyzed together
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("handleCommands() sent %d requests to the model, want 1", len(got))
+	}
+	for _, want := range []string{"first-output", "second-output"} {
+		if !strings.Contains(got[0], want) {
+			t.Errorf("analysis request is missing %q", want)
+		}
+	}
+}
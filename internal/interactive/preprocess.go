@@ -0,0 +1,83 @@
+package interactive
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/logproc"
+)
+
+// PreprocessMode controls local log pre-processing of piped input
+type PreprocessMode string
+
+const (
+	// PreprocessAuto summarizes the input only when it doesn't fit into the context
+	PreprocessAuto PreprocessMode = "auto"
+	// PreprocessOn always summarizes the input
+	PreprocessOn PreprocessMode = "on"
+	// PreprocessOff sends raw input, truncated to the context size
+	PreprocessOff PreprocessMode = "off"
+)
+
+// ParsePreprocessMode validates a --preprocess value
+func ParsePreprocessMode(value string) (PreprocessMode, error) {
+	switch mode := PreprocessMode(value); mode {
+	case PreprocessAuto, PreprocessOn, PreprocessOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid preprocess mode %q (supported: auto, on, off)", value)
+}
+
+// readPipeData reads piped input and returns the data to send to the model.
+//
+// With pre-processing the whole input is streamed through a log processor, so
+// nothing in the middle of a large log is lost; the model receives a summary of
+// templates, levels, status codes and latencies plus representative samples.
+// The returned bool reports whether the data is such a summary.
+func (s *Session) readPipeData(r io.Reader, mode PreprocessMode) (string, bool, error) {
+	if mode == PreprocessOff {
+		pipeData, err := io.ReadAll(io.LimitReader(r, MaxPipeDataBytes))
+		if err != nil {
+			return "", false, err
+		}
+		return s.truncateOutput(string(pipeData), MaxContextChars), false, nil
+	}
+
+	processor := logproc.New()
+	var raw strings.Builder
+	rawComplete := true
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			processor.Add(strings.TrimRight(line, "\r\n"))
+			// Keep the raw input only while it still fits into the context
+			if rawComplete {
+				if raw.Len()+len(line) > MaxContextChars {
+					rawComplete = false
+					raw.Reset()
+				} else {
+					raw.WriteString(line)
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", false, err
+		}
+	}
+
+	if mode == PreprocessAuto && rawComplete {
+		return raw.String(), false, nil
+	}
+
+	color.HiBlack(s.translator.T("interactive.preprocess_done", processor.TotalLines(), processor.TemplateCount()) + "\n")
+	return s.truncateOutput(processor.Summary(), MaxContextChars), true, nil
+}
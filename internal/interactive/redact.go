@@ -0,0 +1,97 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/redact"
+	"github.com/llaoj/aiassist/internal/sysinfo"
+)
+
+// maxRedactionDisplay limits the length of redacted values shown with --show-redactions
+const maxRedactionDisplay = 60
+
+// newRedactor returns the redactor of data sent to the model, or nil when
+// redaction is disabled
+func newRedactor() *redact.Redactor {
+	cfg := config.Get().GetRedaction()
+	if cfg.Disabled {
+		return nil
+	}
+
+	opts := redact.Options{Exclude: cfg.Exclude}
+	for _, p := range cfg.Patterns {
+		if p != nil {
+			opts.Patterns = append(opts.Patterns, redact.Pattern{Name: p.Name, Regex: p.Regex})
+		}
+	}
+	redactor, err := redact.New(opts)
+	if err != nil {
+		// Never send data unredacted because of a config mistake
+		color.Yellow("Warning: %v, using the built-in redaction only\n", err)
+		redactor, _ = redact.New(redact.Options{})
+	}
+	return redactor
+}
+
+// SetShowRedactions makes the session print the values redacted from each
+// request to the model, for checking what leaves the machine
+func (s *Session) SetShowRedactions(show bool) {
+	s.showRedactions = show
+}
+
+// redactIdentity makes the hostname and user of the system information redacted
+func (s *Session) redactIdentity(info *sysinfo.SystemInfo) {
+	s.redactor.AddLiteral("hostname", info.Hostname)
+	s.redactor.AddLiteral("user", info.User)
+}
+
+// sendToLLM sends the conversation to the model with sensitive values replaced by
+// placeholders, and returns the response with the values restored. The system
+// prompt is redacted too, as prompt templates may include system information.
+func (s *Session) sendToLLM(systemPrompt, conversationContext string) (response string, modelUsed string, err error) {
+	systemPrompt, redactions := s.redactor.Redact(systemPrompt)
+	conversationContext, contextRedactions := s.redactor.Redact(conversationContext)
+	for _, r := range contextRedactions {
+		if !slices.Contains(redactions, r) {
+			redactions = append(redactions, r)
+		}
+	}
+	if s.showRedactions {
+		s.displayRedactions(redactions)
+	}
+
+	response, modelUsed, err = s.llmManager.CallWithFallbackSystemPrompt(context.Background(), systemPrompt, conversationContext)
+	return s.redactor.Restore(response), modelUsed, err
+}
+
+// displayRedactions prints redactions to stderr, keeping stdout for the answer
+func (s *Session) displayRedactions(redactions []redact.Redaction) {
+	if len(redactions) == 0 {
+		fmt.Fprintln(os.Stderr, color.HiBlackString(s.translator.T("interactive.no_redactions")))
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, color.YellowString(s.translator.T("interactive.redactions")))
+	for _, r := range redactions {
+		fmt.Fprintf(os.Stderr, "  %s = %s (%s)\n", r.Placeholder, displayValue(r.Value), r.Detector)
+	}
+}
+
+// displayValue shortens a redacted value to its first line and maxRedactionDisplay characters
+func displayValue(value string) string {
+	line, _, multiline := strings.Cut(value, "\n")
+	runes := []rune(line)
+	if len(runes) > maxRedactionDisplay {
+		return string(runes[:maxRedactionDisplay]) + "…"
+	}
+	if multiline {
+		return line + " …"
+	}
+	return line
+}
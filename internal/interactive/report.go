@@ -0,0 +1,131 @@
+package interactive
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/llaoj/aiassist/internal/llm"
+	"github.com/llaoj/aiassist/internal/prompt"
+)
+
+// SeverityUnknown is reported when the model didn't state a severity
+const SeverityUnknown = "unknown"
+
+var severityRe = regexp.MustCompile(`(?i)\[severity:\s*(none|low|medium|high|critical)\s*\]`)
+
+// Report is the machine-readable result of a single analysis (--output json)
+type Report struct {
+	Model    string          `json:"model"`
+	Analysis string          `json:"analysis"`
+	Commands []ReportCommand `json:"commands"`
+	Severity string          `json:"severity"`
+	Runbooks []string        `json:"runbooks,omitempty"` // Sources of the runbook sections sent to the model
+	Usage    llm.Usage       `json:"usage"`
+	Errors   []string        `json:"errors"`
+}
+
+// ReportCommand is a command suggested by the model. Commands are never executed
+// in report mode; the blacklist verdict tells whether they would be allowed.
+// Type is the model's tag upgraded by the local classifier, whose Reason is set
+// for commands classified as modify.
+type ReportCommand struct {
+	Command          string `json:"command"`
+	Type             string `json:"type"`
+	ModelType        string `json:"model_type"`
+	Reason           string `json:"reason,omitempty"`
+	Blacklisted      bool   `json:"blacklisted"`
+	BlacklistPattern string `json:"blacklist_pattern,omitempty"`
+}
+
+// Failed reports whether the analysis could not be produced
+func (r *Report) Failed() bool {
+	return r.Model == ""
+}
+
+// SeverityAtLeast reports whether the reported severity is at or above level
+func (r *Report) SeverityAtLeast(level string) bool {
+	return severityRank(r.Severity) >= 0 && severityRank(r.Severity) >= severityRank(level)
+}
+
+// severityRank returns the position of level in prompt.SeverityLevels, or -1
+func severityRank(level string) int {
+	for i, l := range prompt.SeverityLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// ReportWithPipe analyzes piped input like RunWithPipe, without printing anything
+// to stdout, and returns the result as a report
+func (s *Session) ReportWithPipe(initialQuestion string, opts PipeOptions) *Report {
+	pipeMsg, err := s.readPipeMessage(initialQuestion, opts)
+	if err != nil {
+		return &Report{Severity: SeverityUnknown, Commands: []ReportCommand{}, Errors: []string{err.Error()}}
+	}
+	return s.report(pipeMsg, prompt.GetPipeInteractivePrompt())
+}
+
+// ReportQuestion answers a single question without printing anything to stdout,
+// and returns the result as a report. Suggested commands are not executed.
+func (s *Session) ReportQuestion(question string) *Report {
+	return s.report(question, prompt.GetInteractivePrompt())
+}
+
+func (s *Session) report(userMsg, systemPrompt string) *Report {
+	s.llmManager.SetQuiet(true)
+
+	runbooks := s.addRunbooks(userMsg)
+	s.history = append(s.history, SessionMessage{Role: "user", Content: userMsg})
+	response, modelUsed, err := s.sendToLLM(prompt.WithReportOutput(systemPrompt), s.buildConversationContext())
+
+	report := &Report{
+		Model:    modelUsed,
+		Runbooks: runbooks,
+		Severity: SeverityUnknown,
+		Commands: []ReportCommand{},
+		Usage:    s.llmManager.Usage(),
+		Errors:   s.llmManager.Errors(),
+	}
+	if report.Errors == nil {
+		report.Errors = []string{}
+	}
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+
+	report.Analysis, report.Severity = extractSeverity(response)
+	for _, cmd := range s.executor.ExtractCommands(response) {
+		blacklisted, pattern := s.executor.IsBlacklisted(cmd.Text)
+		report.Commands = append(report.Commands, ReportCommand{
+			Command:          cmd.Text,
+			Type:             cmd.Type.String(),
+			ModelType:        cmd.ModelType.String(),
+			Reason:           cmd.Local.Reason,
+			Blacklisted:      blacklisted,
+			BlacklistPattern: pattern,
+		})
+	}
+	return report
+}
+
+// extractSeverity removes [severity:<level>] markers from the response and
+// returns the cleaned text with the last stated severity
+func extractSeverity(response string) (string, string) {
+	severity := SeverityUnknown
+	if matches := severityRe.FindAllStringSubmatch(response, -1); len(matches) > 0 {
+		severity = strings.ToLower(matches[len(matches)-1][1])
+	}
+
+	var lines []string
+	for _, line := range strings.Split(response, "\n") {
+		line = severityRe.ReplaceAllString(line, "")
+		if strings.TrimSpace(line) == "" && len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), severity
+}
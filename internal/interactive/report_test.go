@@ -0,0 +1,84 @@
+package interactive
+
+import "testing"
+
+func TestExtractSeverity(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		wantAnalysis string
+		wantSeverity string
+	}{
+		{
+			name:         "final line",
+			response:     "Disk /var is 98% full.\n\n[severity:high]",
+			wantAnalysis: "Disk /var is 98% full.",
+			wantSeverity: "high",
+		},
+		{
+			name:         "missing",
+			response:     "Everything looks fine.",
+			wantAnalysis: "Everything looks fine.",
+			wantSeverity: SeverityUnknown,
+		},
+		{
+			name:         "mixed case and spaces",
+			response:     "OOM kills.\n[Severity: CRITICAL ]",
+			wantAnalysis: "OOM kills.",
+			wantSeverity: "critical",
+		},
+		{
+			name:         "malformed",
+			response:     "Slow queries.\n[severity:urgent]\n[severity high]\nseverity:low",
+			wantAnalysis: "Slow queries.\n[severity:urgent]\n[severity high]\nseverity:low",
+			wantSeverity: SeverityUnknown,
+		},
+		{
+			name:         "last one wins",
+			response:     "At first [severity:low] it looked harmless.\n\nThen the disk failed.\n\n[severity:critical]",
+			wantAnalysis: "At first  it looked harmless.\n\nThen the disk failed.",
+			wantSeverity: "critical",
+		},
+		{
+			name:         "blank lines collapse",
+			response:     "Line one.\n\n[severity:none]\n\nLine two.",
+			wantAnalysis: "Line one.\n\nLine two.",
+			wantSeverity: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, severity := extractSeverity(tt.response)
+			if analysis != tt.wantAnalysis || severity != tt.wantSeverity {
+				t.Errorf("extractSeverity() = %q, %q, want %q, %q", analysis, severity, tt.wantAnalysis, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity string
+		level    string
+		want     bool
+	}{
+		{"critical", "critical", true},
+		{"high", "critical", false},
+		{"critical", "high", true},
+		{"high", "high", true},
+		{"medium", "high", false},
+		{"medium", "low", true},
+		{"low", "medium", false},
+		{"none", "low", false},
+		{SeverityUnknown, "low", false},
+		{SeverityUnknown, "critical", false},
+	}
+
+	for _, tt := range tests {
+		r := &Report{Severity: tt.severity}
+		if got := r.SeverityAtLeast(tt.level); got != tt.want {
+			t.Errorf("Report{Severity: %q}.SeverityAtLeast(%q) = %v, want %v", tt.severity, tt.level, got, tt.want)
+		}
+	}
+}
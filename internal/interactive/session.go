@@ -1,18 +1,19 @@
 package interactive
 
 import (
-	"context"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
 	"github.com/llaoj/aiassist/internal/executor"
 	"github.com/llaoj/aiassist/internal/i18n"
+	"github.com/llaoj/aiassist/internal/kb"
 	"github.com/llaoj/aiassist/internal/llm"
 	"github.com/llaoj/aiassist/internal/prompt"
+	"github.com/llaoj/aiassist/internal/redact"
 	"github.com/llaoj/aiassist/internal/sysinfo"
 	"github.com/llaoj/aiassist/internal/ui"
 )
@@ -37,8 +38,9 @@ const (
 
 // SessionMessage represents a message in the session
 type SessionMessage struct {
-	Role    string // "system", "user", or "assistant"
-	Content string
+	Role    string   `json:"role"` // "system", "user", or "assistant"
+	Content string   `json:"content"`
+	Sources []string `json:"sources,omitempty"` // Citations of the runbook sections in Content
 }
 
 // Session represents an interactive session with user
@@ -49,6 +51,20 @@ type Session struct {
 	translator        *i18n.I18n
 	recursionDepth    int // Current recursion depth for command handling
 	maxRecursionDepth int // Maximum allowed recursion depth
+
+	autoApprove   AutoApproveOptions // Non-interactive approval policy, see SetAutoApprove
+	autoSteps     int                // Commands executed in auto-approve mode
+	autoDecisions []autoDecision     // Outcomes for the final auto-approve report
+
+	fleet *executor.Fleet // Hosts query commands run on in parallel, see SetFleet
+
+	redactor       *redact.Redactor // Masks sensitive data sent to the model, nil if disabled
+	showRedactions bool             // Print the redacted values, see SetShowRedactions
+
+	kb *kb.Index // Runbooks searched for each question, nil without index
+
+	lastQuestion      string // Question typed last, for /retry
+	lastQuestionIndex int    // Length of the history before lastQuestion
 }
 
 func NewSession(manager *llm.Manager, translator *i18n.I18n) *Session {
@@ -57,13 +73,22 @@ func NewSession(manager *llm.Manager, translator *i18n.I18n) *Session {
 		executor:          executor.NewCommandExecutor(),
 		history:           make([]SessionMessage, 0),
 		translator:        translator,
-		maxRecursionDepth: 10, // Allow deeper analysis for complex troubleshooting scenarios
+		maxRecursionDepth: config.Get().GetMaxDepth(), // Allow deeper analysis for complex troubleshooting scenarios
+		redactor:          newRedactor(),
+		kb:                loadKnowledgeBase(),
+	}
+
+	// System information can be disabled with --no-sysinfo
+	if !config.Get().SysinfoEnabled() {
+		return session
 	}
 
 	sysInfo, err := sysinfo.LoadOrCollect()
 	if err != nil {
 		color.Yellow("Warning: failed to load system info: %v\n", err)
 	} else {
+		session.redactIdentity(sysInfo)
+		prompt.SetSystemInfo(sysInfo)
 		session.history = append(session.history, SessionMessage{
 			Role:    "system",
 			Content: sysInfo.FormatAsContext(),
@@ -73,6 +98,74 @@ func NewSession(manager *llm.Manager, translator *i18n.I18n) *Session {
 	return session
 }
 
+// SetRemote makes the session run commands on another host through runner. The
+// system information of that host replaces the local one.
+func (s *Session) SetRemote(runner executor.Runner) error {
+	if err := s.executor.SetRunner(runner); err != nil {
+		return err
+	}
+	s.useRemoteSysinfo(runner)
+	return nil
+}
+
+// useRemoteSysinfo replaces the local system information by the one of the host of runner
+func (s *Session) useRemoteSysinfo(runner executor.Runner) {
+	if !config.Get().SysinfoEnabled() {
+		return
+	}
+	sysInfo, err := sysinfo.CollectRemote(func(script string) (string, error) {
+		return runner.Run(script, 0)
+	})
+	if err != nil {
+		color.Yellow("Warning: failed to load system info: %v\n", err)
+		sysInfo = nil
+	}
+	prompt.SetSystemInfo(sysInfo)
+
+	// Drop the local system information, it doesn't describe the target
+	history := s.history[:0]
+	for _, msg := range s.history {
+		if msg.Role != "system" || !strings.HasPrefix(msg.Content, "[System Environment]") {
+			history = append(history, msg)
+		}
+	}
+	s.history = history
+	if sysInfo != nil {
+		s.redactIdentity(sysInfo)
+		s.history = append([]SessionMessage{{Role: "system", Content: sysInfo.FormatAsContext()}}, s.history...)
+	}
+}
+
+// target returns where commands run: the remote host, the number of fleet hosts,
+// or "" when they run locally
+func (s *Session) target() string {
+	if s.fleet != nil {
+		return s.translator.T("interactive.fleet_target", s.fleet.Size())
+	}
+	return s.executor.Target()
+}
+
+// targetPrompt translates a confirmation prompt, naming the target host when
+// commands run remotely
+func (s *Session) targetPrompt(key string) string {
+	if target := s.target(); target != "" {
+		return s.translator.T(key+"_remote", target)
+	}
+	return s.translator.T(key)
+}
+
+// location returns where commands run for the input prompt: the working directory,
+// prefixed with the target host when commands run remotely
+func (s *Session) location() string {
+	if s.fleet != nil {
+		return s.target()
+	}
+	if target := s.target(); target != "" {
+		return target + ":" + s.executor.WorkingDir()
+	}
+	return displayDir(s.executor.WorkingDir())
+}
+
 // Run starts the interactive session
 // If initialQuestion is provided, it will be processed and ask if user wants to continue
 func (s *Session) Run(initialQuestion string) (err error) {
@@ -108,9 +201,9 @@ func (s *Session) Run(initialQuestion string) (err error) {
 	return s.runInteractiveLoop()
 }
 
-// readUserInput reads input from terminal
+// readUserInput reads input from terminal, completing slash commands with Tab
 func (s *Session) readUserInput(prompt string) (string, error) {
-	input, err := ui.PromptInput(prompt, s.translator)
+	input, err := ui.PromptInputWithSuggestions(prompt, s.completions(), s.translator)
 	if err != nil {
 		return "", err
 	}
@@ -118,47 +211,126 @@ func (s *Session) readUserInput(prompt string) (string, error) {
 	return input, nil
 }
 
-// confirmCommandExecution asks user to confirm command execution
-func (s *Session) confirmCommandExecution(cmdType executor.CommandType) (bool, error) {
-	// First confirmation
-	confirmed, err := s.askConfirmation(s.translator.T("executor.execute_prompt"))
-	if err != nil || !confirmed {
-		return false, err
+// Prompts confirming and editing commands, replaced in tests
+var (
+	promptConfirmOrEdit  = ui.PromptConfirmOrEdit
+	promptInputWithValue = ui.PromptInputWithValue
+	promptConfirm        = ui.PromptConfirm
+)
+
+// confirmCommandExecution asks user to confirm command execution. The user may
+// edit the command first: the edited command is displayed, classified and checked
+// against the blacklist again, and returned in place of cmd.
+func (s *Session) confirmCommandExecution(cmd executor.Command) (executor.Command, bool, error) {
+	// First confirmation, offering to edit the command
+	for {
+		s.displayKubeContext(cmd)
+		choice, err := promptConfirmOrEdit(s.targetPrompt("executor.execute_prompt"), s.translator)
+		s.exitOnInterrupt(err)
+		if err != nil || choice == ui.ChoiceNo {
+			return cmd, false, err
+		}
+		if choice == ui.ChoiceYes {
+			break
+		}
+
+		cmd, err = s.editCommand(cmd)
+		if err != nil {
+			return cmd, false, err
+		}
 	}
 
 	// Second confirmation for modify commands
-	if cmdType == executor.ModifyCommand {
+	if cmd.Type == executor.ModifyCommand {
 		fmt.Println()
-		confirmed, err = s.askConfirmation(s.translator.T("executor.modify_warning"))
+		confirmed, err := s.askConfirmation(s.targetPrompt("executor.modify_warning"))
 		if err != nil || !confirmed {
-			return false, err
+			return cmd, false, err
 		}
 	}
 
-	return true, nil
+	return cmd, true, nil
+}
+
+// displayKubeContext shows the Kubernetes context and namespace a kubectl modify
+// command acts on in red, so changes don't hit the wrong cluster by accident
+func (s *Session) displayKubeContext(cmd executor.Command) {
+	if cmd.Type != executor.ModifyCommand {
+		return
+	}
+	kubeContext, namespace, ok := s.executor.KubeContext(cmd.Text)
+	if !ok {
+		return
+	}
+	if kubeContext == "" {
+		color.Red(s.translator.T("executor.kube_context_unknown") + "\n")
+		return
+	}
+	color.Red(s.translator.T("executor.kube_context", kubeContext, namespace) + "\n")
+}
+
+// editCommand lets the user edit a command and returns the edited command.
+// Blacklisted edits are rejected and cmd is returned unchanged.
+func (s *Session) editCommand(cmd executor.Command) (executor.Command, error) {
+	fmt.Println()
+	text, err := promptInputWithValue(s.translator.T("executor.edit_prompt"), cmd.Text, s.translator)
+	s.exitOnInterrupt(err)
+	if err != nil {
+		return cmd, err
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" || text == cmd.Text {
+		return cmd, nil
+	}
+
+	// Classify the edited command against the model's original tag
+	edited := executor.NewCommand(text, cmd.ModelType)
+	fmt.Println()
+	s.executor.DisplayCommand(edited.Text, edited.Type, s.translator)
+	s.executor.DisplayClassificationWarning(edited, s.translator)
+
+	if blacklisted, pattern := s.executor.IsBlacklisted(edited.Text); blacklisted {
+		color.Red(s.translator.T("executor.blacklisted", pattern) + "\n")
+		color.Yellow(s.translator.T("executor.edit_rejected", cmd.Text) + "\n")
+		return cmd, nil
+	}
+	return edited, nil
 }
 
 // askConfirmation prompts user for yes/no confirmation
 func (s *Session) askConfirmation(prompt string) (bool, error) {
-	confirmed, err := ui.PromptConfirm(prompt, s.translator)
+	confirmed, err := promptConfirm(prompt, s.translator)
+	s.exitOnInterrupt(err)
 	if err != nil {
-		if errors.Is(err, ui.ErrInterrupted) {
-			fmt.Println()
-			fmt.Println(s.translator.T("interactive.goodbye"))
-			os.Exit(0)
-		}
 		return false, err
 	}
 
 	return confirmed, nil
 }
 
+// exitOnInterrupt exits cleanly when the user pressed Ctrl+C in a prompt
+func (s *Session) exitOnInterrupt(err error) {
+	if errors.Is(err, ui.ErrInterrupted) {
+		fmt.Println()
+		fmt.Println(s.translator.T("interactive.goodbye"))
+		os.Exit(0)
+	}
+}
+
 // buildConversationContext builds conversation context from history
 func (s *Session) buildConversationContext() string {
 	var context string
+	shellState := false
 
 	// Add all conversation history in chronological order
 	for _, msg := range s.history {
+		// The current shell state follows the system info
+		if msg.Role != "system" && !shellState {
+			context += s.shellContext() + "\n"
+			shellState = true
+		}
+
 		switch msg.Role {
 		case "system":
 			// System info doesn't need label
@@ -175,6 +347,8 @@ func (s *Session) buildConversationContext() string {
 
 // processQuestion handles a single question and its response
 func (s *Session) processQuestion(userInput string) error {
+	s.lastQuestion, s.lastQuestionIndex = userInput, len(s.history)
+	s.displayRunbooks(s.addRunbooks(userInput))
 	s.history = append(s.history, SessionMessage{Role: "user", Content: userInput})
 
 	response, modelUsed, err := s.callLLM(prompt.GetInteractivePrompt())
@@ -193,11 +367,16 @@ func (s *Session) processQuestion(userInput string) error {
 	return nil
 }
 
+// callLLM asks the model about the conversation, and has the flags of suggested
+// commands verified, see verifyFlags
 func (s *Session) callLLM(systemPrompt string) (response string, modelUsed string, err error) {
 	conversationContext := s.buildConversationContext()
-	ctx := context.Background()
-	response, modelUsed, err = s.llmManager.CallWithFallbackSystemPrompt(ctx, systemPrompt, conversationContext)
-	return
+	response, modelUsed, err = s.sendToLLM(systemPrompt, conversationContext)
+	if err != nil {
+		return response, modelUsed, err
+	}
+	response, modelUsed = s.verifyFlags(systemPrompt, conversationContext, response, modelUsed)
+	return response, modelUsed, nil
 }
 
 func (s *Session) displayResponse(modelUsed, response string) {
@@ -208,29 +387,39 @@ func (s *Session) displayResponse(modelUsed, response string) {
 	os.Stdout.Sync()
 }
 
-func (s *Session) RunWithPipe(initialQuestion string) error {
-	limitedReader := io.LimitReader(os.Stdin, MaxPipeDataBytes)
-	pipeData, err := io.ReadAll(limitedReader)
+// PipeOptions controls how piped input is analyzed
+type PipeOptions struct {
+	Preprocess PreprocessMode
+
+	// Interactive continues into an interactive session after the analysis,
+	// reading input from the terminal since stdin holds the piped data
+	Interactive bool
+}
+
+// RunWithPipe analyzes piped input
+func (s *Session) RunWithPipe(initialQuestion string, opts PipeOptions) error {
+	// Make sure a terminal is available before consuming the input
+	if opts.Interactive {
+		closeTTY, err := ui.UseTTY()
+		if err != nil {
+			return err
+		}
+		defer closeTTY()
+	}
+
+	pipeMsg, err := s.readPipeMessage(initialQuestion, opts)
 	if err != nil {
 		return err
 	}
 
-	truncatedPipeData := s.truncateOutput(string(pipeData), MaxContextChars)
-
-	var pipeMsg string
-	if initialQuestion != "" {
-		pipeMsg = fmt.Sprintf("%s\n%s%s\n\n%s\n%s",
-			s.translator.T("interactive.pipe_source"),
-			s.translator.T("interactive.pipe_user_question"), initialQuestion,
-			s.translator.T("interactive.pipe_data"), truncatedPipeData)
-	} else {
-		pipeMsg = fmt.Sprintf("%s\n\n%s\n%s",
-			s.translator.T("interactive.pipe_source"),
-			s.translator.T("interactive.pipe_data"), truncatedPipeData)
+	systemPrompt := prompt.GetPipeAnalysisPrompt()
+	if opts.Interactive || s.autoMode() {
+		systemPrompt = prompt.GetPipeInteractivePrompt()
 	}
 
+	s.displayRunbooks(s.addRunbooks(pipeMsg))
 	s.history = append(s.history, SessionMessage{Role: "user", Content: pipeMsg})
-	response, modelUsed, err := s.callLLM(prompt.GetPipeAnalysisPrompt())
+	response, modelUsed, err := s.callLLM(systemPrompt)
 	if err != nil {
 		return err
 	}
@@ -238,6 +427,28 @@ func (s *Session) RunWithPipe(initialQuestion string) error {
 	s.history = append(s.history, SessionMessage{Role: "assistant", Content: response})
 	s.displayResponse(modelUsed, response)
 
+	if s.autoMode() {
+		// Run suggested commands according to the approval policy, without prompting
+		if commands := s.executor.ExtractCommands(response); len(commands) > 0 {
+			err = s.handleCommands(commands)
+		}
+		s.PrintAutoReport()
+		return err
+	}
+
+	if opts.Interactive {
+		// Continue like an interactive session with the analysis in history:
+		// suggested commands go through the normal confirm-and-execute flow
+		fmt.Println()
+		color.Cyan(s.translator.T("interactive.exit_hint") + "\n")
+		if commands := s.executor.ExtractCommands(response); len(commands) > 0 {
+			if err := s.handleCommands(commands); err != nil {
+				color.Red("Error: %v\n", err)
+			}
+		}
+		return s.runInteractiveLoop()
+	}
+
 	// In pipe mode, just show the analysis and exit
 	// No interactive loop, no command execution
 	fmt.Println()
@@ -247,11 +458,38 @@ func (s *Session) RunWithPipe(initialQuestion string) error {
 	return nil
 }
 
+// readPipeMessage reads piped input and builds the user message presenting it to the model
+func (s *Session) readPipeMessage(initialQuestion string, opts PipeOptions) (string, error) {
+	if opts.Preprocess == "" {
+		opts.Preprocess = PreprocessAuto
+	}
+
+	pipeData, summarized, err := s.readPipeData(os.Stdin, opts.Preprocess)
+	if err != nil {
+		return "", err
+	}
+
+	dataLabel := s.translator.T("interactive.pipe_data")
+	if summarized {
+		dataLabel = s.translator.T("interactive.pipe_data_summary")
+	}
+
+	if initialQuestion != "" {
+		return fmt.Sprintf("%s\n%s%s\n\n%s\n%s",
+			s.translator.T("interactive.pipe_source"),
+			s.translator.T("interactive.pipe_user_question"), initialQuestion,
+			dataLabel, pipeData), nil
+	}
+	return fmt.Sprintf("%s\n\n%s\n%s",
+		s.translator.T("interactive.pipe_source"),
+		dataLabel, pipeData), nil
+}
+
 func (s *Session) runInteractiveLoop() error {
 	for {
 		// Print empty line before showing input prompt
 		fmt.Println()
-		prompt := s.translator.T("interactive.input_prompt")
+		prompt := s.translator.T("interactive.input_prompt", s.location())
 		userInput, err := s.readUserInput(prompt)
 		if err != nil {
 			if errors.Is(err, ui.ErrInterrupted) {
@@ -272,6 +510,17 @@ func (s *Session) runInteractiveLoop() error {
 
 		fmt.Println(userInput)
 
+		if handled, err := s.runSlashCommand(userInput); handled {
+			if errors.Is(err, errExit) {
+				fmt.Println(s.translator.T("interactive.goodbye"))
+				return nil
+			}
+			if err != nil {
+				color.Red("Error: %v\n", err)
+			}
+			continue
+		}
+
 		if err := s.processQuestion(userInput); err != nil {
 			color.Red("Error: %v\n", err)
 			continue
@@ -279,6 +528,8 @@ func (s *Session) runInteractiveLoop() error {
 	}
 }
 
+// handleCommands runs the proposed commands and feeds their combined results back
+// to the model in a single analysis turn. Several commands are shown as a plan first.
 func (s *Session) handleCommands(commands []executor.Command) error {
 	if len(commands) == 0 {
 		return nil
@@ -291,69 +542,146 @@ func (s *Session) handleCommands(commands []executor.Command) error {
 	s.recursionDepth++
 	defer func() { s.recursionDepth-- }()
 
+	var results []string
+	var err error
+	if len(commands) > 1 && !s.autoMode() {
+		results, err = s.runPlan(commands)
+	} else {
+		results, err = s.runCommands(commands, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		// All commands were rejected by user
+		fmt.Println()
+		color.Green(s.translator.T("interactive.analysis_complete") + "\n")
+		return nil
+	}
+
+	// Share the context between all results, so a large output doesn't hide the others
+	for i := range results {
+		results[i] = s.truncateOutput(results[i], MaxContextChars/len(results))
+	}
+	return s.analyzeCommandOutput(strings.Join(results, "\n\n"))
+}
+
+// runCommands displays and runs commands in order, and returns their results for
+// the model, including blacklist rejections. Each command is confirmed unless
+// preapproved is set: then query commands the user already chose run directly,
+// and only modify commands are confirmed.
+func (s *Session) runCommands(commands []executor.Command, preapproved bool) ([]string, error) {
+	var results []string
 	for _, cmd := range commands {
 		fmt.Println()
 		s.executor.DisplayCommand(cmd.Text, cmd.Type, s.translator)
+		s.executor.DisplayClassificationWarning(cmd, s.translator)
 
 		// Check if command is blacklisted
 		if blacklisted, pattern := s.executor.IsBlacklisted(cmd.Text); blacklisted {
 			color.Red(s.translator.T("executor.blacklisted", pattern))
 			fmt.Println(s.translator.T("executor.blacklist_hint"))
 
+			s.recordAuto(cmd, "blacklisted", pattern)
+
 			// Add blacklist rejection to conversation history for AI analysis
-			blacklistResult := fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s",
+			results = append(results, fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s",
 				s.translator.T("interactive.executed_command"), cmd.Text,
-				"Blacklist Rejection", s.translator.T("executor.blacklisted", pattern))
+				"Blacklist Rejection", s.translator.T("executor.blacklisted", pattern)))
+			continue
+		}
 
-			return s.analyzeCommandOutput(blacklistResult)
+		if rejection, refused := s.refuseOnFleet(cmd); refused {
+			results = append(results, rejection)
+			continue
 		}
 
-		confirmed, err := s.confirmCommandExecution(cmd.Type)
-		if err != nil {
-			return err
+		suggested := cmd.Text
+		confirmed := true
+		if !preapproved || cmd.Type == executor.ModifyCommand {
+			var err error
+			cmd, confirmed, err = s.approveCommand(cmd)
+			if err != nil {
+				return nil, err
+			}
 		}
 		if !confirmed {
 			continue
 		}
 
-		execStop := ui.StartSpinner(s.translator.T("executor.executing"))
-		output, err := s.executor.ExecuteCommand(cmd.Text)
-		if execStop != nil {
-			execStop()
+		if s.fleet != nil {
+			// The command may have been edited into a modify command
+			if rejection, refused := s.refuseOnFleet(cmd); refused {
+				results = append(results, rejection)
+				continue
+			}
+			results = append(results, s.runFleetCommand(cmd, suggested))
+		} else {
+			results = append(results, s.runCommand(cmd, suggested))
 		}
+	}
+	return results, nil
+}
 
-		if output == "" {
-			output = s.translator.T("executor.no_output")
-		}
+// runCommand executes an approved command, prints its output and returns the
+// execution result for the model. suggested is the command the model proposed.
+func (s *Session) runCommand(cmd executor.Command, suggested string) string {
+	execStop := ui.StartSpinner(s.translator.T("executor.executing"))
+	output, err := s.executor.ExecuteCommand(cmd.Text)
+	if execStop != nil {
+		execStop()
+	}
 
-		fmt.Println()
-		fmt.Printf("[%s]:\n", s.translator.T("interactive.execution_output"))
-		fmt.Println(output)
+	if output == "" {
+		output = s.translator.T("executor.no_output")
+	}
 
-		// Build execution result message including error information
-		var executionResult string
-		if err != nil {
-			color.Red(s.translator.T("executor.execute_failed", err))
-			// Include error information in the execution result for LLM analysis
-			executionResult = fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s\n\n[%s]\n%s",
-				s.translator.T("interactive.executed_command"), cmd.Text,
-				s.translator.T("interactive.execution_output"), output,
-				s.translator.T("interactive.execution_error"), err.Error())
-		} else {
-			// Show execution success message
-			color.Green(s.translator.T("executor.execute_success"))
-			executionResult = fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s",
-				s.translator.T("interactive.executed_command"), cmd.Text,
-				s.translator.T("interactive.execution_output"), output)
-		}
+	fmt.Println()
+	fmt.Printf("[%s]:\n", s.translator.T("interactive.execution_output"))
+	fmt.Println(output)
 
-		return s.analyzeCommandOutput(executionResult)
+	// The model gets plain text without colors and terminal control sequences
+	output = executor.CleanTerminalOutput(output)
+
+	// Build execution result message including error information.
+	// Edited commands also show the suggestion, so the model knows what actually ran.
+	var executionResult string
+	if cmd.Text != suggested {
+		executionResult = fmt.Sprintf("[%s]\n%s\n\n", s.translator.T("interactive.edited_command"), suggested)
 	}
+	if err != nil {
+		s.recordAuto(cmd, "failed", err.Error())
+		color.Red(s.translator.T("executor.execute_failed", err))
+		// Include error information in the execution result for LLM analysis
+		executionResult += fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s\n\n[%s]\n%s",
+			s.translator.T("interactive.executed_command"), cmd.Text,
+			s.translator.T("interactive.execution_output"), output,
+			s.translator.T("interactive.execution_error"), err.Error())
+	} else {
+		// Show execution success message
+		s.recordAuto(cmd, "executed", "")
+		color.Green(s.translator.T("executor.execute_success"))
+		executionResult += fmt.Sprintf("[%s]\n%s\n\n[%s]\n%s",
+			s.translator.T("interactive.executed_command"), cmd.Text,
+			s.translator.T("interactive.execution_output"), output)
+	}
+	return executionResult
+}
 
-	// If we reach here, all commands were rejected by user
-	fmt.Println()
-	color.Green(s.translator.T("interactive.analysis_complete") + "\n")
-	return nil
+// displayDir shortens dir for display by replacing the home directory with ~
+func displayDir(dir string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == "/" {
+		return dir
+	}
+	if dir == home {
+		return "~"
+	}
+	if strings.HasPrefix(dir, home+"/") {
+		return "~" + dir[len(home):]
+	}
+	return dir
 }
 
 func (s *Session) truncateOutput(output string, maxChars int) string {
@@ -378,9 +706,8 @@ func (s *Session) analyzeCommandOutput(executionResult string) error {
 	s.history = append(s.history, SessionMessage{Role: "user", Content: truncatedResult})
 
 	fullContext := s.buildConversationContext() + s.translator.T("interactive.continue_analysis")
-	ctx := context.Background()
 	stopSpinner := ui.StartSpinner(s.translator.T("interactive.thinking"))
-	response, modelUsed, err := s.llmManager.CallWithFallbackSystemPrompt(ctx, prompt.GetContinueAnalysisPrompt(), fullContext)
+	response, modelUsed, err := s.sendToLLM(prompt.GetContinueAnalysisPrompt(), fullContext)
 	if stopSpinner != nil {
 		stopSpinner()
 	}
@@ -389,6 +716,7 @@ func (s *Session) analyzeCommandOutput(executionResult string) error {
 		color.Red("Error: %v\n", err)
 		return err
 	}
+	response, modelUsed = s.verifyFlags(prompt.GetContinueAnalysisPrompt(), fullContext, response, modelUsed)
 
 	s.history = append(s.history, SessionMessage{Role: "assistant", Content: response})
 	s.displayResponse(modelUsed, response)
@@ -0,0 +1,175 @@
+package interactive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/executor"
+	"github.com/llaoj/aiassist/internal/i18n"
+	"github.com/llaoj/aiassist/internal/ui"
+)
+
+// initTestConfig makes content the global configuration, in a temporary home
+func initTestConfig(t *testing.T, content string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	configFile := filepath.Join(home, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.InitWithFile(configFile); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newPromptTestSession returns a session running commands locally with blacklist
+func newPromptTestSession(t *testing.T, blacklist string) *Session {
+	t.Helper()
+	initTestConfig(t, "language: en\nblacklist:\n  - "+blacklist+"\n")
+	return &Session{
+		executor:   executor.NewCommandExecutor(),
+		translator: i18n.New(config.LanguageEnglish),
+	}
+}
+
+// scriptPrompts answers the command prompts in turn, failing the test when one is
+// asked for more often than scripted
+func scriptPrompts(t *testing.T, choices []int, edits []string, confirms []bool) {
+	t.Helper()
+	savedChoice, savedInput, savedConfirm := promptConfirmOrEdit, promptInputWithValue, promptConfirm
+	t.Cleanup(func() {
+		promptConfirmOrEdit, promptInputWithValue, promptConfirm = savedChoice, savedInput, savedConfirm
+	})
+
+	promptConfirmOrEdit = func(string, *i18n.I18n) (int, error) {
+		if len(choices) == 0 {
+			t.Fatal("unexpected confirmation prompt")
+		}
+		choice := choices[0]
+		choices = choices[1:]
+		return choice, nil
+	}
+	promptInputWithValue = func(_, value string, _ *i18n.I18n) (string, error) {
+		if len(edits) == 0 {
+			t.Fatal("unexpected edit prompt")
+		}
+		edit := edits[0]
+		edits = edits[1:]
+		return edit, nil
+	}
+	promptConfirm = func(string, *i18n.I18n) (bool, error) {
+		if len(confirms) == 0 {
+			t.Fatal("unexpected modify confirmation")
+		}
+		confirmed := confirms[0]
+		confirms = confirms[1:]
+		return confirmed, nil
+	}
+}
+
+func TestEditCommand(t *testing.T) {
+	s := newPromptTestSession(t, "reboot")
+	original := executor.NewCommand("ls /tmp", executor.QueryCommand)
+
+	tests := []struct {
+		name     string
+		edit     string
+		wantText string
+		wantType executor.CommandType
+	}{
+		{"query edit", "ls -la /tmp", "ls -la /tmp", executor.QueryCommand},
+		{"reclassified as modify", "rm -rf /tmp/x", "rm -rf /tmp/x", executor.ModifyCommand},
+		{"blacklisted edit", "reboot", "ls /tmp", executor.QueryCommand},
+		{"empty edit", "  ", "ls /tmp", executor.QueryCommand},
+		{"unchanged", "ls /tmp", "ls /tmp", executor.QueryCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scriptPrompts(t, nil, []string{tt.edit}, nil)
+			got, err := s.editCommand(original)
+			if err != nil {
+				t.Fatalf("editCommand() error = %v", err)
+			}
+			if got.Text != tt.wantText || got.Type != tt.wantType {
+				t.Errorf("editCommand() = %q [%s], want %q [%s]", got.Text, got.Type, tt.wantText, tt.wantType)
+			}
+			// The model's tag is kept to report disagreements
+			if got.ModelType != executor.QueryCommand {
+				t.Errorf("editCommand() model type = %s, want %s", got.ModelType, executor.QueryCommand)
+			}
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		scriptPrompts(t, nil, nil, nil)
+		failed := errors.New("input error")
+		promptInputWithValue = func(string, string, *i18n.I18n) (string, error) { return "", failed }
+		got, err := s.editCommand(original)
+		if !errors.Is(err, failed) || got.Text != original.Text {
+			t.Errorf("editCommand() = %q, %v, want %q, %v", got.Text, err, original.Text, failed)
+		}
+	})
+}
+
+func TestConfirmCommandExecution(t *testing.T) {
+	s := newPromptTestSession(t, "reboot")
+	original := executor.NewCommand("systemctl status nginx", executor.QueryCommand)
+
+	tests := []struct {
+		name     string
+		choices  []int
+		edits    []string
+		confirms []bool
+		wantText string
+		wantRun  bool
+	}{
+		{name: "confirmed", choices: []int{ui.ChoiceYes}, wantText: "systemctl status nginx", wantRun: true},
+		{name: "declined", choices: []int{ui.ChoiceNo}, wantText: "systemctl status nginx"},
+		{
+			name:    "edited query",
+			choices: []int{ui.ChoiceEdit, ui.ChoiceYes}, edits: []string{"systemctl status sshd"},
+			wantText: "systemctl status sshd", wantRun: true,
+		},
+		{
+			// Edited into a modify command, it needs the second confirmation
+			name:    "edited into modify, confirmed",
+			choices: []int{ui.ChoiceEdit, ui.ChoiceYes}, edits: []string{"systemctl restart nginx"}, confirms: []bool{true},
+			wantText: "systemctl restart nginx", wantRun: true,
+		},
+		{
+			name:    "edited into modify, declined",
+			choices: []int{ui.ChoiceEdit, ui.ChoiceYes}, edits: []string{"systemctl restart nginx"}, confirms: []bool{false},
+			wantText: "systemctl restart nginx",
+		},
+		{
+			// A rejected edit asks again for the original command
+			name:    "blacklisted edit",
+			choices: []int{ui.ChoiceEdit, ui.ChoiceYes}, edits: []string{"reboot"},
+			wantText: "systemctl status nginx", wantRun: true,
+		},
+		{
+			name:    "edited twice",
+			choices: []int{ui.ChoiceEdit, ui.ChoiceEdit, ui.ChoiceNo}, edits: []string{"uptime", ""},
+			wantText: "uptime",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scriptPrompts(t, tt.choices, tt.edits, tt.confirms)
+			got, run, err := s.confirmCommandExecution(original)
+			if err != nil {
+				t.Fatalf("confirmCommandExecution() error = %v", err)
+			}
+			if got.Text != tt.wantText || run != tt.wantRun {
+				t.Errorf("confirmCommandExecution() = %q, %v, want %q, %v", got.Text, run, tt.wantText, tt.wantRun)
+			}
+		})
+	}
+}
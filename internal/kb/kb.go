@@ -0,0 +1,388 @@
+// Package kb indexes markdown runbooks and retrieves the sections relevant to a
+// question with BM25, so answers can follow documented procedures.
+package kb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/llaoj/aiassist/internal/config"
+)
+
+const (
+	indexDir  = "kb"
+	indexFile = "index.json"
+
+	// indexVersion changes when the index format does, requiring a new kb index
+	indexVersion = 1
+
+	// maxChunkChars splits long sections at paragraph boundaries
+	maxChunkChars = 1500
+
+	// maxSnippetChars limits the text of a section sent to the model
+	maxSnippetChars = 1200
+
+	// BM25 parameters
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Chunk is a section of a markdown document
+type Chunk struct {
+	Source  string         `json:"source"`  // Path of the document
+	Heading string         `json:"heading"` // Headings leading to the section, e.g. "Nginx > Restart"
+	Line    int            `json:"line"`    // First line of the section in the document
+	Text    string         `json:"text"`
+	Terms   map[string]int `json:"terms"`  // Term frequencies
+	Length  int            `json:"length"` // Number of terms
+}
+
+// Citation returns where the section comes from, as path:line
+func (c *Chunk) Citation() string {
+	return fmt.Sprintf("%s:%d", c.Source, c.Line)
+}
+
+// Index is a BM25 index of markdown sections
+type Index struct {
+	Version   int            `json:"version"`
+	Dirs      []string       `json:"dirs"`  // Indexed directories
+	Files     int            `json:"files"` // Number of indexed documents
+	Chunks    []Chunk        `json:"chunks"`
+	DocFreq   map[string]int `json:"doc_freq"` // Number of sections containing each term
+	AvgLength float64        `json:"avg_length"`
+	IndexedAt time.Time      `json:"indexed_at"`
+}
+
+// Result is a section matching a query
+type Result struct {
+	Chunk
+	Score float64
+}
+
+// Path returns the path of the index file
+func Path() string {
+	return filepath.Join(config.Get().ConfigDir, indexDir, indexFile)
+}
+
+// Build indexes the markdown files (.md, .markdown) under dirs. Hidden files and
+// directories are skipped.
+func Build(dirs []string) (*Index, error) {
+	idx := &Index{Version: indexVersion, DocFreq: make(map[string]int), IndexedAt: time.Now()}
+
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		idx.Dirs = append(idx.Dirs, abs)
+
+		err = filepath.WalkDir(abs, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != abs && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !isMarkdown(path) {
+				return nil
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			idx.Files++
+			idx.Chunks = append(idx.Chunks, Split(path, string(data))...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to index %s: %w", dir, err)
+		}
+	}
+
+	total := 0
+	for _, chunk := range idx.Chunks {
+		for term := range chunk.Terms {
+			idx.DocFreq[term]++
+		}
+		total += chunk.Length
+	}
+	if len(idx.Chunks) > 0 {
+		idx.AvgLength = float64(total) / float64(len(idx.Chunks))
+	}
+	return idx, nil
+}
+
+func isMarkdown(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// Split splits a markdown document into sections at headings. Sections longer
+// than maxChunkChars are split at blank lines; headings in code blocks, such as
+// shell comments, are ignored.
+func Split(source, content string) []Chunk {
+	var (
+		chunks   []Chunk
+		headings []string // Heading of each level, "" for skipped levels
+		body     strings.Builder
+		start    = 1
+		inFence  bool
+		fence    string
+	)
+
+	flush := func(next int) {
+		text := strings.TrimSpace(body.String())
+		body.Reset()
+		defer func() { start = next }()
+		if level, _ := parseHeading(text); text == "" || (level > 0 && !strings.Contains(text, "\n")) {
+			// Nothing but a heading
+			return
+		}
+
+		heading := strings.Join(nonEmpty(headings), " > ")
+		terms := tokenize(heading + "\n" + text)
+		if len(terms) == 0 {
+			return
+		}
+		chunk := Chunk{Source: source, Heading: heading, Line: start, Text: text, Terms: make(map[string]int)}
+		for _, term := range terms {
+			chunk.Terms[term]++
+		}
+		chunk.Length = len(terms)
+		chunks = append(chunks, chunk)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) && strings.TrimLeft(trimmed, fence[:1]) == "" {
+				inFence = false
+			}
+		} else if !inFence {
+			if level, title := parseHeading(trimmed); level > 0 {
+				flush(lineNum)
+				for len(headings) < level {
+					headings = append(headings, "")
+				}
+				headings = append(headings[:level-1], title)
+			} else if trimmed == "" && body.Len() >= maxChunkChars {
+				flush(lineNum + 1)
+				continue
+			}
+		}
+
+		if body.Len() == 0 && trimmed == "" {
+			start = lineNum + 1
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	flush(len(lines) + 1)
+
+	return chunks
+}
+
+// fenceMarker returns the marker opening or closing a fenced code block, or ""
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// parseHeading returns the level and title of an ATX heading, or 0
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, ""
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	return level, title
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// stopWords are common English words that don't help ranking
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "for": true, "from": true, "how": true, "i": true, "if": true, "in": true,
+	"is": true, "it": true, "my": true, "of": true, "on": true, "or": true, "the": true, "this": true,
+	"to": true, "what": true, "when": true, "why": true, "with": true, "you": true,
+}
+
+// tokenize returns the lowercase terms of text: words of letters and digits, and
+// overlapping pairs of Chinese characters since Chinese has no spaces
+func tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	var han []rune
+
+	flushWord := func() {
+		if len(word) > 1 || (len(word) == 1 && word[0] > unicode.MaxASCII) {
+			if term := string(word); !stopWords[term] {
+				terms = append(terms, term)
+			}
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		if len(han) == 1 {
+			terms = append(terms, string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			terms = append(terms, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return terms
+}
+
+// Search returns up to k sections matching query, best first
+func (idx *Index) Search(query string, k int) []Result {
+	if idx == nil || len(idx.Chunks) == 0 || k <= 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range tokenize(query) {
+		if !seen[term] && idx.DocFreq[term] > 0 {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.Chunks))
+	var results []Result
+	for _, chunk := range idx.Chunks {
+		score := 0.0
+		for _, term := range terms {
+			tf := float64(chunk.Terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(idx.DocFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(chunk.Length)/idx.AvgLength))
+		}
+		if score > 0 {
+			results = append(results, Result{Chunk: chunk, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// FormatAsContext formats results as context for the model, with their sources
+// so that answers can cite them
+func FormatAsContext(results []Result) string {
+	var sb strings.Builder
+	sb.WriteString("[Runbooks]\n")
+	sb.WriteString("Excerpts of the team's runbooks that may be relevant. When one applies, follow its procedure and cite its source, e.g. (source: docs/nginx.md:12).\n")
+	for _, r := range results {
+		text := r.Text
+		if runes := []rune(text); len(runes) > maxSnippetChars {
+			text = string(runes[:maxSnippetChars]) + "\n..."
+		}
+		fmt.Fprintf(&sb, "\n--- source: %s", r.Citation())
+		if r.Heading != "" {
+			fmt.Fprintf(&sb, " (%s)", r.Heading)
+		}
+		fmt.Fprintf(&sb, " ---\n%s\n", text)
+	}
+	return sb.String()
+}
+
+// Save writes the index to path
+func (idx *Index) Save(path string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write atomically, so sessions never read a partial index
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads the index from path. The error wraps fs.ErrNotExist when there is
+// no index.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse runbook index %s: %w", path, err)
+	}
+	if idx.Version != indexVersion {
+		return nil, errors.New("runbook index format changed, run 'aiassist kb index' again")
+	}
+	return &idx, nil
+}
@@ -0,0 +1,135 @@
+package kb
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	doc := `# Nginx
+
+Web servers behind the load balancer.
+
+## Restart
+
+Check the configuration first:
+
+` + "```bash" + `
+# not a heading
+nginx -t
+` + "```" + `
+
+#### Graceful
+
+nginx -s reload
+
+## Logs
+`
+	// The Logs section has no content
+
+	chunks := Split("nginx.md", doc)
+	want := []struct {
+		heading string
+		line    int
+		prefix  string
+	}{
+		{"Nginx", 1, "# Nginx\n\nWeb servers"},
+		{"Nginx > Restart", 5, "## Restart\n\nCheck"},
+		{"Nginx > Restart > Graceful", 14, "#### Graceful"},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("Split() returned %d chunks: %+v", len(chunks), chunks)
+	}
+	for i, w := range want {
+		c := chunks[i]
+		if c.Heading != w.heading || c.Line != w.line || !strings.HasPrefix(c.Text, w.prefix) {
+			t.Errorf("chunk %d = %q at line %d: %q, want %q at line %d: %q...", i, c.Heading, c.Line, c.Text, w.heading, w.line, w.prefix)
+		}
+	}
+	if !strings.Contains(chunks[1].Text, "# not a heading\nnginx -t") {
+		t.Errorf("code block was split: %q", chunks[1].Text)
+	}
+
+	// Long sections are split at paragraphs
+	long := "# Long\n\n" + strings.Repeat(strings.Repeat("word ", 100)+"\n\n", 6)
+	chunks = Split("long.md", long)
+	if len(chunks) < 2 || chunks[1].Heading != "Long" || chunks[1].Line <= chunks[0].Line {
+		t.Errorf("long section split into %+v", chunks)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := strings.Join(tokenize("How to restart the kube-proxy on node-1? 重启服务"), " ")
+	want := "restart kube proxy node 重启 启服 服务"
+	if got != want {
+		t.Errorf("tokenize() = %q, want %q", got, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"nginx.md":           "# Nginx\n\n## 502 Bad Gateway\n\nCheck the upstream with `curl` and restart php-fpm.\n\n## Certificates\n\nRenew with certbot.\n",
+		"postgres.md":        "# PostgreSQL\n\n## Disk full\n\nRemove old WAL files only with pg_archivecleanup.\n",
+		"zh/disk.md":         "# 磁盘空间不足\n\n使用 du 查找大文件，清理日志前先确认日志轮转配置。\n",
+		".git/ignored.md":    "# Disk full\n\ndisk full disk full\n",
+		"notes.txt":          "disk full",
+		"sub/empty.markdown": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx, err := Build([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Files != 4 {
+		t.Errorf("Build() indexed %d files, want 4", idx.Files)
+	}
+
+	// Save and load it like the kb command and sessions do
+	path := filepath.Join(t.TempDir(), "kb", "index.json")
+	if err := idx.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if idx, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"nginx returns 502 bad gateway", filepath.Join(dir, "nginx.md") + ":3"},
+		{"postgres disk is full", filepath.Join(dir, "postgres.md") + ":3"},
+		{"磁盘满了怎么清理", filepath.Join(dir, "zh", "disk.md") + ":1"},
+	}
+	for _, tt := range tests {
+		results := idx.Search(tt.query, 2)
+		if len(results) == 0 || results[0].Citation() != tt.want {
+			t.Errorf("Search(%q) = %+v, want %s first", tt.query, results, tt.want)
+		}
+	}
+
+	if results := idx.Search("kubernetes ingress", 3); len(results) != 0 {
+		t.Errorf("Search() of unknown terms returned %+v", results)
+	}
+
+	context := FormatAsContext(idx.Search("certbot", 1))
+	if !strings.Contains(context, "--- source: "+filepath.Join(dir, "nginx.md")+":7 (Nginx > Certificates) ---\n## Certificates") {
+		t.Errorf("FormatAsContext() = %q", context)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Load() of a missing index returned %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type modelListResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ListRemoteModels fetches the IDs of the models offered by an OpenAI-compatible
+// provider from its /models endpoint. The result is sorted.
+func ListRemoteModels(ctx context.Context, baseURL, apiKey string, proxyFunc func(*http.Request) (*url.URL, error)) ([]string, error) {
+	client := newHTTPClient()
+	if proxyFunc != nil {
+		client.Transport.(*http.Transport).Proxy = proxyFunc
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(baseURL, "/")+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("models request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, apiErrorMessage(body))
+	}
+
+	var respData modelListResponse
+	if err := json.Unmarshal(body, &respData); err != nil {
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
+	}
+	if respData.Error != nil {
+		return nil, fmt.Errorf("API error: %s", respData.Error.Message)
+	}
+
+	ids := make([]string, 0, len(respData.Data))
+	for _, model := range respData.Data {
+		if model.ID != "" {
+			ids = append(ids, model.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
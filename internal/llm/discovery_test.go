@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListRemoteModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "sorted ids",
+			status: http.StatusOK,
+			body:   `{"object":"list","data":[{"id":"gpt-4o-mini"},{"id":""},{"id":"gpt-3.5-turbo"}]}`,
+			want:   []string{"gpt-3.5-turbo", "gpt-4o-mini"},
+		},
+		{name: "empty list", status: http.StatusOK, body: `{"data":[]}`, want: []string{}},
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"message":"invalid api key"}}`,
+			wantErr: "HTTP 401: invalid api key",
+		},
+		{
+			name:    "error in body",
+			status:  http.StatusOK,
+			body:    `{"error":{"message":"models endpoint disabled"}}`,
+			wantErr: "API error: models endpoint disabled",
+		},
+		{
+			name:    "not json",
+			status:  http.StatusOK,
+			body:    `<html>proxy login</html>`,
+			wantErr: "failed to parse models response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer sk-test" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := ListRemoteModels(context.Background(), server.URL+"/v1/", "sk-test", nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ListRemoteModels() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListRemoteModels() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListRemoteModels() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
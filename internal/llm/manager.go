@@ -17,6 +17,11 @@ type Manager struct {
 	mu         sync.RWMutex
 	config     *config.Config
 	translator *i18n.I18n
+	quiet      bool // Don't show the spinner or print failed calls
+
+	statsMu sync.Mutex
+	usage   Usage    // Token usage accumulated over all calls
+	errors  []string // Failed model calls, including ones recovered by fallback
 }
 
 func NewManager(cfg *config.Config) *Manager {
@@ -49,6 +54,77 @@ func (m *Manager) RegisterModel(model Model) {
 	m.models = append(m.models, model)
 }
 
+// ModelNames returns the names of the registered models in the order they are
+// tried, the primary model first
+func (m *Manager) ModelNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.models))
+	for _, model := range m.models {
+		names = append(names, model.GetName())
+	}
+	return names
+}
+
+// SetPrimaryModel makes the named model the one tried first; the others remain
+// fallbacks in their order
+func (m *Manager) SetPrimaryModel(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, model := range m.models {
+		if model.GetName() == name {
+			m.models = append([]Model{model}, append(m.models[:i:i], m.models[i+1:]...)...)
+			return nil
+		}
+	}
+	return fmt.Errorf("model %s not found", name)
+}
+
+// SetLanguage switches the language of the messages printed by the manager
+func (m *Manager) SetLanguage(language string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.translator.SetLanguage(language)
+}
+
+// SetQuiet disables the spinner and printing of failed model calls,
+// e.g. for machine-readable output. Failures are still available from Errors.
+func (m *Manager) SetQuiet(quiet bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.quiet = quiet
+}
+
+// Usage returns the token usage accumulated over all calls
+func (m *Manager) Usage() Usage {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	return m.usage
+}
+
+// Errors returns the errors of all failed model calls so far
+func (m *Manager) Errors() []string {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	return append([]string(nil), m.errors...)
+}
+
+func (m *Manager) recordCall(usage Usage, err error) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+
+	m.usage.Add(usage)
+	if err != nil {
+		m.errors = append(m.errors, err.Error())
+	}
+}
+
 func (m *Manager) CallWithFallbackSystemPrompt(ctx context.Context, systemPrompt string, userPrompt string) (string, string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -67,14 +143,18 @@ func (m *Manager) CallWithFallbackSystemPrompt(ctx context.Context, systemPrompt
 
 		// Attempt to call
 		var response string
+		var usage Usage
 		var err error
 
 		// Start spinner before calling the model
-		stopSpinner := ui.StartSpinner(m.translator.T("interactive.thinking"))
+		stopSpinner := func() {}
+		if !m.quiet {
+			stopSpinner = ui.StartSpinner(m.translator.T("interactive.thinking"))
+		}
 
 		// If model supports system prompt, use the version with system prompt
 		if compatModel, ok := model.(*OpenAICompatibleModel); ok && systemPrompt != "" {
-			response, err = compatModel.CallWithSystemPrompt(ctx, systemPrompt, userPrompt)
+			response, usage, err = compatModel.CallWithUsage(ctx, systemPrompt, userPrompt)
 		} else {
 			response, err = model.Call(ctx, userPrompt)
 		}
@@ -84,8 +164,11 @@ func (m *Manager) CallWithFallbackSystemPrompt(ctx context.Context, systemPrompt
 			stopSpinner()
 		}
 
+		m.recordCall(usage, err)
 		if err != nil {
-			color.Red("Error: %v\n", err)
+			if !m.quiet {
+				color.Red("Error: %v\n", err)
+			}
 			continue
 		}
 
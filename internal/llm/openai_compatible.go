@@ -36,6 +36,7 @@ type chatMessage struct {
 
 type chatCompletionResponse struct {
 	Choices []choice `json:"choices"`
+	Usage   *Usage   `json:"usage"`
 	Error   *struct {
 		Message string `json:"message"`
 	} `json:"error"`
@@ -45,7 +46,32 @@ type choice struct {
 	Message chatMessage `json:"message"`
 }
 
+// Usage is the token usage reported by the API
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add accumulates another usage into u
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
 func NewOpenAICompatibleModel(name, baseURL, apiKey, modelName string) *OpenAICompatibleModel {
+	return &OpenAICompatibleModel{
+		name:       name,
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		modelName:  modelName,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// newHTTPClient creates the HTTP client used for OpenAI-compatible APIs
+func newHTTPClient() *http.Client {
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   10 * time.Second,
@@ -62,18 +88,10 @@ func NewOpenAICompatibleModel(name, baseURL, apiKey, modelName string) *OpenAICo
 		DisableCompression: false,
 	}
 
-	client := &http.Client{
+	return &http.Client{
 		Timeout:   120 * time.Second, // Total request timeout (increased for AI APIs)
 		Transport: transport,
 	}
-
-	return &OpenAICompatibleModel{
-		name:       name,
-		baseURL:    baseURL,
-		apiKey:     apiKey,
-		modelName:  modelName,
-		httpClient: client,
-	}
 }
 
 // SetProxyFunc configures proxy function for the model
@@ -102,6 +120,13 @@ func (o *OpenAICompatibleModel) Call(ctx context.Context, prompt string) (string
 }
 
 func (o *OpenAICompatibleModel) CallWithSystemPrompt(ctx context.Context, systemPrompt string, userPrompt string) (string, error) {
+	response, _, err := o.CallWithUsage(ctx, systemPrompt, userPrompt)
+	return response, err
+}
+
+// CallWithUsage is like CallWithSystemPrompt and also returns the token usage
+// reported by the API (zero if the provider doesn't report it)
+func (o *OpenAICompatibleModel) CallWithUsage(ctx context.Context, systemPrompt string, userPrompt string) (string, Usage, error) {
 	messages := []chatMessage{}
 
 	if systemPrompt != "" {
@@ -123,52 +148,66 @@ func (o *OpenAICompatibleModel) CallWithSystemPrompt(ctx context.Context, system
 
 	reqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	resp, err := o.postChatCompletion(ctx, reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
-
-	resp, err := o.httpClient.Do(httpReq)
-	if err != nil {
-		// Check if it's a timeout error
-		if urlErr, ok := err.(*url.Error); ok && urlErr.Timeout() {
-			return "", fmt.Errorf("%s API call timeout: %w", o.name, err)
-		}
-		return "", fmt.Errorf("%s API call failed: %w", o.name, err)
+		return "", Usage{}, err
 	}
 	defer resp.Body.Close()
 
 	// Check HTTP status code
 	if resp.StatusCode == 429 {
 		// Rate limit or quota exceeded
-		return "", fmt.Errorf("%s: quota exceeded or rate limited (HTTP 429)", o.name)
+		return "", Usage{}, fmt.Errorf("%s: quota exceeded or rate limited (HTTP 429)", o.name)
 	}
 
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var respData chatCompletionResponse
 	if err := json.Unmarshal(respBody, &respData); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	// Check for API errors
 	if respData.Error != nil {
-		return "", fmt.Errorf("API error from %s: %s", o.name, respData.Error.Message)
+		return "", Usage{}, fmt.Errorf("API error from %s: %s", o.name, respData.Error.Message)
 	}
 
 	if len(respData.Choices) == 0 {
-		return "", fmt.Errorf("no response from %s", o.name)
+		return "", Usage{}, fmt.Errorf("no response from %s", o.name)
+	}
+
+	var usage Usage
+	if respData.Usage != nil {
+		usage = *respData.Usage
+	}
+	return respData.Choices[0].Message.Content, usage, nil
+}
+
+// postChatCompletion sends a raw chat completion request body to the provider
+func (o *OpenAICompatibleModel) postChatCompletion(ctx context.Context, reqBody []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.httpClient.Do(httpReq)
+	if err != nil {
+		// Check if it's a timeout error
+		if urlErr, ok := err.(*url.Error); ok && urlErr.Timeout() {
+			return nil, fmt.Errorf("%s API call timeout: %w", o.name, err)
+		}
+		return nil, fmt.Errorf("%s API call failed: %w", o.name, err)
 	}
 
-	return respData.Choices[0].Message.Content, nil
+	return resp, nil
 }
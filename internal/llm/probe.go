@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CapabilityCheck is the outcome of probing a single optional capability
+type CapabilityCheck struct {
+	Supported bool
+	Detail    string
+}
+
+// ProbeResult holds the outcome of a connectivity test against a model
+type ProbeResult struct {
+	Latency     time.Duration
+	StatusCode  int
+	AuthValid   bool
+	Err         error // Set when the basic chat completion failed
+	ToolCalling CapabilityCheck
+	Streaming   CapabilityCheck
+}
+
+// OK reports whether the basic chat completion succeeded
+func (r *ProbeResult) OK() bool {
+	return r.Err == nil
+}
+
+// Probe sends minimal requests to the model to check connectivity, authentication,
+// tool calling and streaming support. Capability checks only run if the basic
+// request succeeded.
+func (o *OpenAICompatibleModel) Probe(ctx context.Context) *ProbeResult {
+	result := &ProbeResult{}
+
+	start := time.Now()
+	status, body, err := o.probeRequest(ctx, map[string]interface{}{
+		"model":      o.modelName,
+		"messages":   []chatMessage{{Role: "user", Content: "ping"}},
+		"max_tokens": 1,
+	})
+	result.Latency = time.Since(start)
+	result.StatusCode = status
+
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.AuthValid = status != http.StatusUnauthorized && status != http.StatusForbidden
+	if status != http.StatusOK {
+		result.Err = fmt.Errorf("HTTP %d: %s", status, apiErrorMessage(body))
+		return result
+	}
+
+	var respData chatCompletionResponse
+	if err := json.Unmarshal(body, &respData); err != nil {
+		result.Err = fmt.Errorf("failed to parse response: %w", err)
+		return result
+	}
+	if respData.Error != nil {
+		result.Err = fmt.Errorf("API error: %s", respData.Error.Message)
+		return result
+	}
+	if len(respData.Choices) == 0 {
+		result.Err = fmt.Errorf("no choices in response")
+		return result
+	}
+
+	result.ToolCalling = o.probeToolCalling(ctx)
+	result.Streaming = o.probeStreaming(ctx)
+	return result
+}
+
+// probeToolCalling asks the model to call a trivial function
+func (o *OpenAICompatibleModel) probeToolCalling(ctx context.Context) CapabilityCheck {
+	status, body, err := o.probeRequest(ctx, map[string]interface{}{
+		"model":    o.modelName,
+		"messages": []chatMessage{{Role: "user", Content: "Call the get_time function."}},
+		"tools": []map[string]interface{}{{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        "get_time",
+				"description": "Returns the current time",
+				"parameters":  map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+			},
+		}},
+		"max_tokens": 32,
+	})
+	if err != nil {
+		return CapabilityCheck{Detail: err.Error()}
+	}
+	if status != http.StatusOK {
+		return CapabilityCheck{Detail: fmt.Sprintf("HTTP %d: %s", status, apiErrorMessage(body))}
+	}
+
+	var respData struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []json.RawMessage `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &respData); err != nil {
+		return CapabilityCheck{Detail: "unparseable response"}
+	}
+	if len(respData.Choices) > 0 && len(respData.Choices[0].Message.ToolCalls) > 0 {
+		return CapabilityCheck{Supported: true}
+	}
+	return CapabilityCheck{Detail: "tools accepted but no tool call returned"}
+}
+
+// probeStreaming requests a streamed response and waits for the first SSE data line
+func (o *OpenAICompatibleModel) probeStreaming(ctx context.Context) CapabilityCheck {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"model":      o.modelName,
+		"messages":   []chatMessage{{Role: "user", Content: "ping"}},
+		"max_tokens": 1,
+		"stream":     true,
+	})
+	if err != nil {
+		return CapabilityCheck{Detail: err.Error()}
+	}
+
+	resp, err := o.postChatCompletion(ctx, reqBody)
+	if err != nil {
+		return CapabilityCheck{Detail: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return CapabilityCheck{Detail: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, apiErrorMessage(body))}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data:") {
+			return CapabilityCheck{Supported: true}
+		}
+	}
+	return CapabilityCheck{Detail: "no server-sent events in response"}
+}
+
+// probeRequest posts a chat completion request and returns status code and body
+func (o *OpenAICompatibleModel) probeRequest(ctx context.Context, payload map[string]interface{}) (int, []byte, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := o.postChatCompletion(ctx, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// apiErrorMessage extracts the error message from an OpenAI-style error body
+func apiErrorMessage(body []byte) string {
+	var respData chatCompletionResponse
+	if err := json.Unmarshal(body, &respData); err == nil && respData.Error != nil && respData.Error.Message != "" {
+		return respData.Error.Message
+	}
+
+	msg := strings.TrimSpace(string(body))
+	if runes := []rune(msg); len(runes) > 200 {
+		msg = string(runes[:200]) + "..."
+	}
+	if msg == "" {
+		msg = "empty response"
+	}
+	return msg
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeProvider serves chat completions; the handlers for tool calling and
+// streaming requests can be replaced to simulate providers lacking them
+type fakeProvider struct {
+	chat   http.HandlerFunc
+	tools  http.HandlerFunc
+	stream http.HandlerFunc
+}
+
+func (f *fakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Tools  []json.RawMessage `json:"tools"`
+		Stream bool              `json:"stream"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case req.Stream:
+		f.stream(w, r)
+	case len(req.Tools) > 0:
+		f.tools(w, r)
+	default:
+		f.chat(w, r)
+	}
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{
+		chat: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"pong"}}]}`))
+		},
+		tools: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`))
+		},
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"p\"}}]}\n\ndata: [DONE]\n\n"))
+		},
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(f *fakeProvider)
+		wantErr      string
+		wantStatus   int
+		wantAuth     bool
+		wantTools    bool
+		toolsDetail  string
+		wantStream   bool
+		streamDetail string
+	}{
+		{
+			name:       "all supported",
+			wantStatus: http.StatusOK, wantAuth: true, wantTools: true, wantStream: true,
+		},
+		{
+			name: "no tool call returned",
+			modify: func(f *fakeProvider) {
+				f.tools = f.chat
+			},
+			wantStatus: http.StatusOK, wantAuth: true, wantStream: true,
+			toolsDetail: "tools accepted but no tool call returned",
+		},
+		{
+			name: "tools rejected",
+			modify: func(f *fakeProvider) {
+				f.tools = func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error":{"message":"tools are not supported"}}`))
+				}
+			},
+			wantStatus: http.StatusOK, wantAuth: true, wantStream: true,
+			toolsDetail: "HTTP 400: tools are not supported",
+		},
+		{
+			name: "no server-sent events",
+			modify: func(f *fakeProvider) {
+				f.stream = f.chat
+			},
+			wantStatus: http.StatusOK, wantAuth: true, wantTools: true,
+			streamDetail: "no server-sent events in response",
+		},
+		{
+			name: "invalid key",
+			modify: func(f *fakeProvider) {
+				f.chat = func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+				}
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    "HTTP 401: Incorrect API key provided",
+		},
+		{
+			name: "error in body",
+			modify: func(f *fakeProvider) {
+				f.chat = func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(`{"error":{"message":"model not found"}}`))
+				}
+			},
+			wantStatus: http.StatusOK, wantAuth: true,
+			wantErr: "API error: model not found",
+		},
+		{
+			name: "no choices",
+			modify: func(f *fakeProvider) {
+				f.chat = func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(`{"choices":[]}`))
+				}
+			},
+			wantStatus: http.StatusOK, wantAuth: true,
+			wantErr: "no choices in response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeProvider()
+			if tt.modify != nil {
+				tt.modify(provider)
+			}
+			server := httptest.NewServer(provider)
+			defer server.Close()
+
+			model := NewOpenAICompatibleModel("fake", server.URL, "sk-test", "fake-model")
+			result := model.Probe(context.Background())
+
+			if tt.wantErr == "" && result.Err != nil {
+				t.Fatalf("Probe() error = %v", result.Err)
+			}
+			if tt.wantErr != "" && (result.Err == nil || result.Err.Error() != tt.wantErr) {
+				t.Fatalf("Probe() error = %v, want %q", result.Err, tt.wantErr)
+			}
+			if result.StatusCode != tt.wantStatus || result.AuthValid != tt.wantAuth {
+				t.Errorf("Probe() status = %d, auth = %v, want %d, %v", result.StatusCode, result.AuthValid, tt.wantStatus, tt.wantAuth)
+			}
+			if result.ToolCalling.Supported != tt.wantTools || result.ToolCalling.Detail != tt.toolsDetail {
+				t.Errorf("tool calling = %+v, want supported %v, detail %q", result.ToolCalling, tt.wantTools, tt.toolsDetail)
+			}
+			if result.Streaming.Supported != tt.wantStream || result.Streaming.Detail != tt.streamDetail {
+				t.Errorf("streaming = %+v, want supported %v, detail %q", result.Streaming, tt.wantStream, tt.streamDetail)
+			}
+		})
+	}
+}
+
+func TestApiErrorMessage(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"error":{"message":"rate limited"}}`, "rate limited"},
+		{`{"error":{"message":""}}`, `{"error":{"message":""}}`},
+		{"  upstream timeout\n", "upstream timeout"},
+		{"", "empty response"},
+		{strings.Repeat("x", 250), strings.Repeat("x", 200) + "..."},
+		{strings.Repeat("错", 250), strings.Repeat("错", 200) + "..."},
+	}
+
+	for _, tt := range tests {
+		if got := apiErrorMessage([]byte(tt.body)); got != tt.want {
+			t.Errorf("apiErrorMessage(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
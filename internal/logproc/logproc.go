@@ -0,0 +1,724 @@
+package logproc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format identifies a detected log line format
+type Format string
+
+const (
+	FormatUnknown  Format = "unknown"
+	FormatCombined Format = "nginx/apache combined"
+	FormatJSON     Format = "json"
+	FormatSyslog   Format = "syslog"
+	FormatJournald Format = "journald"
+	FormatKlog     Format = "klog"
+)
+
+// Level is the severity of a log line
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelWarning
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelError:
+		return "ERROR"
+	case LevelWarning:
+		return "WARN"
+	}
+	return "INFO"
+}
+
+// Limits keep memory bounded regardless of input size
+const (
+	maxTemplates        = 10000 // distinct templates tracked; further ones are counted as overflow
+	maxTemplateLen      = 300   // characters of a template key
+	maxSampleLen        = 500   // characters of a sample line
+	maxClients          = 10000 // distinct client addresses tracked
+	maxLatencySamples   = 10000 // reservoir size for latency percentiles
+	recentErrorLines    = 20    // most recent error/warning lines kept verbatim
+	excerptLines        = 20    // first and last lines kept verbatim
+	maxSummaryTemplates = 30    // templates listed per summary section
+)
+
+var (
+	combinedRe = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "([^"]*)" (\d{3}) (\S+)(?: "([^"]*)" "([^"]*)")?(.*)$`)
+	klogRe     = regexp.MustCompile(`^([IWEF])(\d{4} \d{2}:\d{2}:\d{2}\.\d+)\s+\d+ ([^\]]+)\] (.*)$`)
+	syslogRe   = regexp.MustCompile(`^([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[\d+\])?: (.*)$`)
+	journalRe  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\S*) (\S+) ([^:\[\s]+)(?:\[\d+\])?: (.*)$`)
+
+	errorWordRe   = regexp.MustCompile(`(?i)\b(fatal|panic|critical|crit|emerg|alert|error|err|exception|fail|failed|failure|denied|refused|timeout|timed out|oom|killed)\b`)
+	warningWordRe = regexp.MustCompile(`(?i)\b(warn|warning|deprecated|retry|retrying)\b`)
+	latencyRe     = regexp.MustCompile(`(?:^|[\s=:])(\d+\.\d+|\d+)(ms|s)?\s*$`)
+
+	uuidRe   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	ipv4Re   = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	isoTSRe  = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	hexRe    = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{8,}\b`)
+	numberRe = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// template is a cluster of lines that only differ in variable parts
+type template struct {
+	key    string
+	count  int
+	level  Level
+	sample string
+}
+
+// Processor incrementally analyzes log lines with bounded memory
+type Processor struct {
+	totalLines int
+	totalBytes int
+
+	formats map[Format]int
+
+	templates        map[string]*template
+	overflowLines    int
+	errorCount       int
+	warningCount     int
+	recentErrors     []string
+	recentErrorsNext int
+
+	statusCodes map[int]int
+	clients     map[string]int
+
+	latencyCount   int
+	latencySum     float64 // milliseconds
+	latencyMax     float64
+	latencySamples []float64
+	rng            *rand.Rand
+
+	firstTime string
+	lastTime  string
+
+	head     []string
+	tail     []string
+	tailNext int
+}
+
+// New creates a log processor
+func New() *Processor {
+	return &Processor{
+		formats:     make(map[Format]int),
+		templates:   make(map[string]*template),
+		statusCodes: make(map[int]int),
+		clients:     make(map[string]int),
+		rng:         rand.New(rand.NewSource(1)),
+	}
+}
+
+// parsedLine is the normalized form of a single log line
+type parsedLine struct {
+	format    Format
+	timestamp string
+	source    string // program, component or request line
+	message   string
+	template  string // set when the format already yields a normalized message
+	level     Level
+	status    int
+	client    string
+	latencyMS float64 // -1 if unknown
+}
+
+// Add processes a single line
+func (p *Processor) Add(line string) {
+	p.totalLines++
+	p.totalBytes += len(line) + 1
+
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	p.addExcerpt(line)
+
+	parsed := parseLine(line)
+	p.formats[parsed.format]++
+
+	if parsed.timestamp != "" {
+		if p.firstTime == "" {
+			p.firstTime = parsed.timestamp
+		}
+		p.lastTime = parsed.timestamp
+	}
+
+	if parsed.status > 0 {
+		p.statusCodes[parsed.status]++
+	}
+	if parsed.client != "" {
+		if _, ok := p.clients[parsed.client]; ok || len(p.clients) < maxClients {
+			p.clients[parsed.client]++
+		}
+	}
+	if parsed.latencyMS >= 0 {
+		p.addLatency(parsed.latencyMS)
+	}
+
+	switch parsed.level {
+	case LevelError:
+		p.errorCount++
+	case LevelWarning:
+		p.warningCount++
+	}
+	if parsed.level > LevelInfo {
+		p.addRecentError(line)
+	}
+
+	key := parsed.template
+	if key == "" {
+		key = Templatize(parsed.message)
+	}
+	if parsed.source != "" {
+		key = parsed.source + ": " + key
+	}
+	key = truncate(key, maxTemplateLen)
+
+	tpl, ok := p.templates[key]
+	if !ok {
+		if len(p.templates) >= maxTemplates {
+			p.overflowLines++
+			return
+		}
+		tpl = &template{key: key, level: parsed.level, sample: truncate(line, maxSampleLen)}
+		p.templates[key] = tpl
+	}
+	tpl.count++
+	if parsed.level > tpl.level {
+		tpl.level = parsed.level
+		tpl.sample = truncate(line, maxSampleLen)
+	}
+}
+
+func (p *Processor) addLatency(ms float64) {
+	p.latencyCount++
+	p.latencySum += ms
+	if ms > p.latencyMax {
+		p.latencyMax = ms
+	}
+
+	// Reservoir sampling keeps percentiles representative for any input size
+	if len(p.latencySamples) < maxLatencySamples {
+		p.latencySamples = append(p.latencySamples, ms)
+	} else if i := p.rng.Intn(p.latencyCount); i < maxLatencySamples {
+		p.latencySamples[i] = ms
+	}
+}
+
+func (p *Processor) addExcerpt(line string) {
+	line = truncate(line, maxSampleLen)
+	if len(p.head) < excerptLines {
+		p.head = append(p.head, line)
+		return
+	}
+	if len(p.tail) < excerptLines {
+		p.tail = append(p.tail, line)
+		return
+	}
+	p.tail[p.tailNext] = line
+	p.tailNext = (p.tailNext + 1) % excerptLines
+}
+
+func (p *Processor) addRecentError(line string) {
+	line = truncate(line, maxSampleLen)
+	if len(p.recentErrors) < recentErrorLines {
+		p.recentErrors = append(p.recentErrors, line)
+		return
+	}
+	p.recentErrors[p.recentErrorsNext] = line
+	p.recentErrorsNext = (p.recentErrorsNext + 1) % recentErrorLines
+}
+
+// TotalLines returns the number of lines processed
+func (p *Processor) TotalLines() int {
+	return p.totalLines
+}
+
+// TemplateCount returns the number of distinct templates found
+func (p *Processor) TemplateCount() int {
+	return len(p.templates)
+}
+
+// parseLine detects the format of a line and extracts its fields
+func parseLine(line string) parsedLine {
+	parsed := parsedLine{format: FormatUnknown, message: line, latencyMS: -1}
+
+	if strings.HasPrefix(line, "{") {
+		if parseJSONLine(line, &parsed) {
+			return parsed
+		}
+	}
+
+	if m := combinedRe.FindStringSubmatch(line); m != nil {
+		parsed.format = FormatCombined
+		parsed.client = m[1]
+		parsed.timestamp = m[2]
+		parsed.status, _ = strconv.Atoi(m[4])
+		parsed.template = requestTemplate(m[3]) + " " + m[4]
+		if ms, ok := parseTrailingLatency(m[8]); ok {
+			parsed.latencyMS = ms
+		}
+		switch {
+		case parsed.status >= 500:
+			parsed.level = LevelError
+		case parsed.status >= 400:
+			parsed.level = LevelWarning
+		}
+		return parsed
+	}
+
+	if m := klogRe.FindStringSubmatch(line); m != nil {
+		parsed.format = FormatKlog
+		parsed.timestamp = m[2]
+		parsed.source = m[3]
+		parsed.message = m[4]
+		switch m[1] {
+		case "E", "F":
+			parsed.level = LevelError
+		case "W":
+			parsed.level = LevelWarning
+		}
+		return parsed
+	}
+
+	if m := journalRe.FindStringSubmatch(line); m != nil {
+		parsed.format = FormatJournald
+		parsed.timestamp = m[1]
+		parsed.source = m[3]
+		parsed.message = m[4]
+		parsed.level = keywordLevel(m[4])
+		return parsed
+	}
+
+	if m := syslogRe.FindStringSubmatch(line); m != nil {
+		parsed.format = FormatSyslog
+		parsed.timestamp = m[1]
+		parsed.source = m[3]
+		parsed.message = m[4]
+		parsed.level = keywordLevel(m[4])
+		return parsed
+	}
+
+	parsed.level = keywordLevel(line)
+	return parsed
+}
+
+// parseJSONLine handles structured JSON logs, including journalctl -o json
+func parseJSONLine(line string, parsed *parsedLine) bool {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return false
+	}
+
+	parsed.format = FormatJSON
+	if _, ok := fields["__REALTIME_TIMESTAMP"]; ok {
+		parsed.format = FormatJournald
+		parsed.source = stringField(fields, "SYSLOG_IDENTIFIER", "_COMM")
+		parsed.message = stringField(fields, "MESSAGE")
+		if priority, err := strconv.Atoi(stringField(fields, "PRIORITY")); err == nil {
+			switch {
+			case priority <= 3:
+				parsed.level = LevelError
+			case priority == 4:
+				parsed.level = LevelWarning
+			}
+		}
+		if usec, err := strconv.ParseInt(stringField(fields, "__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+			parsed.timestamp = time.UnixMicro(usec).UTC().Format(time.RFC3339)
+		}
+		return true
+	}
+
+	parsed.timestamp = stringField(fields, "time", "timestamp", "ts", "@timestamp")
+	parsed.source = stringField(fields, "logger", "component", "caller")
+	parsed.message = stringField(fields, "msg", "message", "log", "error")
+	if parsed.message == "" {
+		parsed.message = jsonKeysTemplate(fields)
+	}
+
+	switch strings.ToLower(stringField(fields, "level", "severity", "lvl", "log.level")) {
+	case "error", "err", "fatal", "panic", "critical", "crit", "dpanic", "emerg", "alert":
+		parsed.level = LevelError
+	case "warn", "warning":
+		parsed.level = LevelWarning
+	case "":
+		parsed.level = keywordLevel(parsed.message)
+	}
+
+	if status, err := strconv.Atoi(stringField(fields, "status", "status_code", "statusCode", "code")); err == nil && status >= 100 && status < 600 {
+		parsed.status = status
+		if status >= 500 && parsed.level < LevelError {
+			parsed.level = LevelError
+		}
+	}
+	parsed.client = stringField(fields, "remote_addr", "client_ip", "clientIP", "ip")
+
+	for _, key := range []string{"latency", "duration", "elapsed", "request_time", "response_time", "latency_ms", "duration_ms", "took"} {
+		if value, ok := fields[key]; ok {
+			if ms, ok := latencyValueMS(key, value); ok {
+				parsed.latencyMS = ms
+				break
+			}
+		}
+	}
+
+	return true
+}
+
+// stringField returns the first non-empty field among keys as a string
+func stringField(fields map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch v := fields[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(v)
+		}
+	}
+	return ""
+}
+
+// jsonKeysTemplate describes a JSON line without a message field by its keys
+func jsonKeysTemplate(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return "{" + strings.Join(keys, ",") + "}"
+}
+
+// latencyValueMS converts a JSON latency value to milliseconds.
+// Strings are parsed as Go durations ("12ms"); numbers use the key to pick the unit:
+// *_ms keys are milliseconds, request_time/response_time are seconds (nginx style),
+// other numbers are assumed to be milliseconds if large and seconds if fractional and small.
+func latencyValueMS(key string, value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return float64(d) / float64(time.Millisecond), true
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return latencyValueMS(key, f)
+		}
+	case float64:
+		switch {
+		case strings.HasSuffix(key, "_ms"):
+			return v, true
+		case key == "request_time" || key == "response_time":
+			return v * 1000, true
+		case v < 100 && v != float64(int64(v)):
+			return v * 1000, true
+		default:
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// parseTrailingLatency extracts a latency from fields after the combined format,
+// e.g. nginx $request_time (seconds) or "rt=0.123"
+func parseTrailingLatency(rest string) (float64, bool) {
+	rest = strings.TrimSpace(strings.ReplaceAll(rest, `"`, ""))
+	if rest == "" {
+		return 0, false
+	}
+	m := latencyRe.FindStringSubmatch(rest)
+	if m == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case m[2] == "ms":
+		return value, true
+	case m[2] == "s" || strings.Contains(m[1], "."):
+		return value * 1000, true
+	}
+	// Plain integers are typically microseconds (Apache %D)
+	return value / 1000, true
+}
+
+// requestTemplate normalizes a request line like "GET /api/users/42?x=1 HTTP/1.1"
+func requestTemplate(request string) string {
+	parts := strings.Fields(request)
+	if len(parts) < 2 {
+		return Templatize(request)
+	}
+
+	path := parts[1]
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i] + "?<query>"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isVariableSegment(segment) {
+			segments[i] = "<*>"
+		}
+	}
+	return parts[0] + " " + strings.Join(segments, "/")
+}
+
+// isVariableSegment reports whether a URL path segment looks like an ID
+func isVariableSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if uuidRe.MatchString(segment) || hexRe.MatchString(segment) {
+		return true
+	}
+	digits := 0
+	for _, r := range segment {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits > 0 && digits*2 > len(segment)
+}
+
+// Templatize replaces variable parts of a message (IDs, addresses, numbers,
+// timestamps) with placeholders so similar lines cluster together
+func Templatize(message string) string {
+	message = isoTSRe.ReplaceAllString(message, "<ts>")
+	message = uuidRe.ReplaceAllString(message, "<uuid>")
+	message = ipv4Re.ReplaceAllString(message, "<ip>")
+	message = hexRe.ReplaceAllString(message, "<hex>")
+	message = numberRe.ReplaceAllString(message, "<n>")
+	return strings.Join(strings.Fields(message), " ")
+}
+
+// keywordLevel guesses a level from keywords in unstructured text
+func keywordLevel(text string) Level {
+	if errorWordRe.MatchString(text) {
+		return LevelError
+	}
+	if warningWordRe.MatchString(text) {
+		return LevelWarning
+	}
+	return LevelInfo
+}
+
+// truncate shortens s to n characters, marking it as cut
+func truncate(s string, n int) string {
+	// Lines of at most n bytes have at most n characters
+	if len(s) <= n {
+		return s
+	}
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n]) + "..."
+	}
+	return s
+}
+
+// Summary formats a compact description of everything processed so far,
+// meant to be sent to the LLM instead of the raw lines
+func (p *Processor) Summary() string {
+	var sb strings.Builder
+
+	sb.WriteString("[Log Summary]\n")
+	sb.WriteString(fmt.Sprintf("Total lines: %d (%d bytes)\n", p.totalLines, p.totalBytes))
+	sb.WriteString("Detected formats: " + p.formatSummary() + "\n")
+	if p.firstTime != "" {
+		sb.WriteString(fmt.Sprintf("Time range: %s → %s\n", p.firstTime, p.lastTime))
+	}
+	sb.WriteString(fmt.Sprintf("Errors: %d, Warnings: %d\n", p.errorCount, p.warningCount))
+	sb.WriteString(fmt.Sprintf("Distinct templates: %d", len(p.templates)))
+	if p.overflowLines > 0 {
+		sb.WriteString(fmt.Sprintf(" (+%d lines beyond the template limit)", p.overflowLines))
+	}
+	sb.WriteString("\n")
+
+	if len(p.statusCodes) > 0 {
+		sb.WriteString("\n[Status Codes]\n")
+		total := 0
+		codes := make([]int, 0, len(p.statusCodes))
+		for code, count := range p.statusCodes {
+			codes = append(codes, code)
+			total += count
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			count := p.statusCodes[code]
+			sb.WriteString(fmt.Sprintf("%d: %d (%.1f%%)\n", code, count, percent(count, total)))
+		}
+	}
+
+	if p.latencyCount > 0 {
+		sb.WriteString("\n[Latency]\n")
+		sb.WriteString(p.latencySummary())
+	}
+
+	if len(p.clients) > 1 {
+		sb.WriteString("\n[Top Clients]\n")
+		for _, kv := range topCounts(p.clients, 10) {
+			sb.WriteString(fmt.Sprintf("%s: %d\n", kv.key, kv.count))
+		}
+	}
+
+	templates := p.sortedTemplates()
+
+	var problems, others []*template
+	for _, tpl := range templates {
+		if tpl.level > LevelInfo {
+			problems = append(problems, tpl)
+		} else {
+			others = append(others, tpl)
+		}
+	}
+	writeTemplates(&sb, "Error/Warning Templates", problems)
+	writeTemplates(&sb, "Other Templates", others)
+
+	if len(p.recentErrors) > 0 {
+		sb.WriteString("\n[Most Recent Error/Warning Lines]\n")
+		for i := 0; i < len(p.recentErrors); i++ {
+			sb.WriteString(p.recentErrors[(p.recentErrorsNext+i)%len(p.recentErrors)] + "\n")
+		}
+	}
+
+	if len(p.head) > 0 {
+		sb.WriteString("\n[First Lines]\n")
+		sb.WriteString(strings.Join(p.head, "\n") + "\n")
+	}
+	if len(p.tail) > 0 {
+		sb.WriteString("\n[Last Lines]\n")
+		for i := 0; i < len(p.tail); i++ {
+			sb.WriteString(p.tail[(p.tailNext+i)%len(p.tail)] + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// writeTemplates writes the most frequent templates of a section with a sample each
+func writeTemplates(sb *strings.Builder, title string, templates []*template) {
+	if len(templates) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n[%s] (count x [level] template, sample)\n", title))
+	for i, tpl := range templates {
+		if i >= maxSummaryTemplates {
+			sb.WriteString(fmt.Sprintf("... %d more templates\n", len(templates)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("%d x [%s] %s\n    e.g. %s\n", tpl.count, tpl.level, tpl.key, tpl.sample))
+	}
+}
+
+func (p *Processor) formatSummary() string {
+	parsedLines := 0
+	for _, count := range p.formats {
+		parsedLines += count
+	}
+
+	counts := make(map[string]int, len(p.formats))
+	for format, count := range p.formats {
+		counts[string(format)] = count
+	}
+
+	var parts []string
+	for _, kv := range topCounts(counts, len(counts)) {
+		parts = append(parts, fmt.Sprintf("%s (%.1f%%)", kv.key, percent(kv.count, parsedLines)))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p *Processor) latencySummary() string {
+	samples := append([]float64(nil), p.latencySamples...)
+	sort.Float64s(samples)
+	pct := func(q float64) float64 {
+		return samples[int(q*float64(len(samples)-1))]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Requests with latency: %d, avg %.1fms, p50 %.1fms, p90 %.1fms, p99 %.1fms, max %.1fms\n",
+		p.latencyCount, p.latencySum/float64(p.latencyCount), pct(0.5), pct(0.9), pct(0.99), p.latencyMax))
+
+	bounds := []float64{10, 50, 100, 500, 1000, 5000}
+	buckets := make([]int, len(bounds)+1)
+	for _, ms := range samples {
+		i := sort.SearchFloat64s(bounds, ms)
+		if i < len(bounds) && ms == bounds[i] {
+			i++
+		}
+		buckets[i]++
+	}
+	for i, count := range buckets {
+		var label string
+		switch {
+		case i == 0:
+			label = fmt.Sprintf("<%gms", bounds[0])
+		case i == len(bounds):
+			label = fmt.Sprintf(">=%gms", bounds[len(bounds)-1])
+		default:
+			label = fmt.Sprintf("%g-%gms", bounds[i-1], bounds[i])
+		}
+		sb.WriteString(fmt.Sprintf("%s: %.1f%%\n", label, percent(count, len(samples))))
+	}
+	return sb.String()
+}
+
+func (p *Processor) sortedTemplates() []*template {
+	templates := make([]*template, 0, len(p.templates))
+	for _, tpl := range p.templates {
+		templates = append(templates, tpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].count != templates[j].count {
+			return templates[i].count > templates[j].count
+		}
+		return templates[i].key < templates[j].key
+	})
+	return templates
+}
+
+type keyCount struct {
+	key   string
+	count int
+}
+
+// topCounts returns the n largest entries, ties broken by key
+func topCounts(counts map[string]int, n int) []keyCount {
+	sorted := make([]keyCount, 0, len(counts))
+	for key, count := range counts {
+		sorted = append(sorted, keyCount{key, count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].key < sorted[j].key
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+func percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(total)
+}
@@ -0,0 +1,221 @@
+package logproc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseLineFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		format    Format
+		level     Level
+		status    int
+		latencyMS float64
+	}{
+		{
+			name:      "nginx combined with request_time",
+			line:      `192.168.1.10 - - [10/Oct/2026:13:55:36 +0000] "GET /api/users/42 HTTP/1.1" 200 612 "-" "curl/8.0" 0.123`,
+			format:    FormatCombined,
+			level:     LevelInfo,
+			status:    200,
+			latencyMS: 123,
+		},
+		{
+			name:      "apache combined 5xx without latency",
+			line:      `10.0.0.1 - frank [10/Oct/2026:13:55:36 -0700] "POST /login HTTP/1.0" 502 2326 "http://example.com/" "Mozilla/5.0"`,
+			format:    FormatCombined,
+			level:     LevelError,
+			status:    502,
+			latencyMS: -1,
+		},
+		{
+			name:      "json with level and duration string",
+			line:      `{"time":"2026-10-10T13:55:36Z","level":"warn","msg":"slow query","duration":"250ms"}`,
+			format:    FormatJSON,
+			level:     LevelWarning,
+			latencyMS: 250,
+		},
+		{
+			name:      "json with status code",
+			line:      `{"ts":1760104536,"msg":"request","status":503,"latency_ms":12}`,
+			format:    FormatJSON,
+			level:     LevelError,
+			status:    503,
+			latencyMS: 12,
+		},
+		{
+			name:      "journalctl json",
+			line:      `{"__REALTIME_TIMESTAMP":"1760104536000000","PRIORITY":"3","SYSLOG_IDENTIFIER":"kubelet","MESSAGE":"failed to sync pod"}`,
+			format:    FormatJournald,
+			level:     LevelError,
+			latencyMS: -1,
+		},
+		{
+			name:      "journalctl short-iso",
+			line:      `2026-10-10T13:55:36+0000 node1 sshd[1234]: Accepted publickey for root from 10.0.0.2 port 50000 ssh2`,
+			format:    FormatJournald,
+			level:     LevelInfo,
+			latencyMS: -1,
+		},
+		{
+			name:      "syslog",
+			line:      `Oct 10 13:55:36 node1 kernel: Out of memory: Killed process 4321 (java)`,
+			format:    FormatSyslog,
+			level:     LevelError,
+			latencyMS: -1,
+		},
+		{
+			name:      "klog warning",
+			line:      `W1010 13:55:36.123456    1234 reflector.go:424] watch of *v1.Pod ended with: too old resource version`,
+			format:    FormatKlog,
+			level:     LevelWarning,
+			latencyMS: -1,
+		},
+		{
+			name:      "unknown with error keyword",
+			line:      `something went wrong: connection refused`,
+			format:    FormatUnknown,
+			level:     LevelError,
+			latencyMS: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLine(tt.line)
+			if got.format != tt.format {
+				t.Errorf("format = %q, want %q", got.format, tt.format)
+			}
+			if got.level != tt.level {
+				t.Errorf("level = %v, want %v", got.level, tt.level)
+			}
+			if got.status != tt.status {
+				t.Errorf("status = %d, want %d", got.status, tt.status)
+			}
+			if got.latencyMS != tt.latencyMS {
+				t.Errorf("latencyMS = %v, want %v", got.latencyMS, tt.latencyMS)
+			}
+		})
+	}
+}
+
+func TestTemplatize(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Accepted publickey for root from 10.0.0.2:50000", "Accepted publickey for root from <ip>"},
+		{"request 550e8400-e29b-41d4-a716-446655440000 took 12.5 ms", "request <uuid> took <n> ms"},
+		{"commit deadbeef42 at 2026-10-10T13:55:36Z", "commit <hex> at <ts>"},
+		{"retry   3  of 5", "retry <n> of <n>"},
+	}
+
+	for _, tt := range tests {
+		if got := Templatize(tt.message); got != tt.want {
+			t.Errorf("Templatize(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestRequestTemplate(t *testing.T) {
+	tests := []struct {
+		request string
+		want    string
+	}{
+		{"GET /api/users/42 HTTP/1.1", "GET /api/users/<*>"},
+		{"GET /api/orders/550e8400-e29b-41d4-a716-446655440000/items?page=2 HTTP/1.1", "GET /api/orders/<*>/items?<query>"},
+		{"POST /v2/login HTTP/1.1", "POST /v2/login"},
+	}
+
+	for _, tt := range tests {
+		if got := requestTemplate(tt.request); got != tt.want {
+			t.Errorf("requestTemplate(%q) = %q, want %q", tt.request, got, tt.want)
+		}
+	}
+}
+
+func TestProcessorSummary(t *testing.T) {
+	p := New()
+	for i := 0; i < 1000; i++ {
+		status := 200
+		if i%100 == 0 {
+			status = 500
+		}
+		p.Add(fmt.Sprintf(`10.0.0.%d - - [10/Oct/2026:13:55:36 +0000] "GET /api/items/%d HTTP/1.1" %d 100 "-" "curl" 0.0%02d`,
+			i%4, i, status, i%100))
+	}
+
+	if p.TotalLines() != 1000 {
+		t.Fatalf("TotalLines() = %d, want 1000", p.TotalLines())
+	}
+	if p.TemplateCount() != 2 {
+		t.Fatalf("TemplateCount() = %d, want 2", p.TemplateCount())
+	}
+
+	summary := p.Summary()
+	for _, want := range []string{
+		"Total lines: 1000",
+		"nginx/apache combined (100.0%)",
+		"Errors: 10, Warnings: 0",
+		"200: 990 (99.0%)",
+		"500: 10 (1.0%)",
+		"Requests with latency: 1000",
+		"990 x [INFO] GET /api/items/<*> 200",
+		"10 x [ERROR] GET /api/items/<*> 500",
+		"10.0.0.0: 250",
+		"[Last Lines]",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestProcessorBoundedTemplates(t *testing.T) {
+	p := New()
+	for i := 0; i < maxTemplates+100; i++ {
+		// Letters are not templatized, so every line is a distinct template
+		p.Add("event " + strings.Repeat("x", i%50) + fmt.Sprintf("%c%c%c", 'a'+i%26, 'a'+(i/26)%26, 'a'+(i/676)%26))
+	}
+
+	if p.TemplateCount() > maxTemplates {
+		t.Errorf("TemplateCount() = %d, want at most %d", p.TemplateCount(), maxTemplates)
+	}
+	if !strings.Contains(p.Summary(), "lines beyond the template limit") {
+		t.Error("summary does not mention template overflow")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"longer than ten", 10, "longer tha..."},
+		{"磁盘空间不足", 6, "磁盘空间不足"},
+		{"磁盘空间不足，写入失败", 6, "磁盘空间不足..."},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestProcessorChineseLines(t *testing.T) {
+	p := New()
+	line := "2024-05-01 12:00:00 ERROR " + strings.Repeat("数据库连接超时", 100)
+	p.Add(line)
+	p.Add(line)
+
+	if summary := p.Summary(); !utf8.ValidString(summary) {
+		t.Errorf("summary of Chinese lines is not valid UTF-8")
+	}
+}
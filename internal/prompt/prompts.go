@@ -7,50 +7,67 @@ import (
 	"github.com/llaoj/aiassist/internal/config"
 )
 
-// SystemPrompts defines LLM system prompts for different scenarios
-type SystemPrompts struct {
-	Interactive      string
-	ContinueAnalysis string
-	PipeAnalysis     string
-}
+// Severity levels reported in machine-readable output, from lowest to highest
+var SeverityLevels = []string{"none", "low", "medium", "high", "critical"}
 
-func GetSystemPrompts() SystemPrompts {
-	lang := config.Get().GetLanguage()
+// NoNewFindings is the exact reply expected in follow mode when a window has nothing new
+const NoNewFindings = "NO_NEW_FINDINGS"
 
-	// Start with base English prompts
-	prompts := SystemPrompts{
-		Interactive:      baseInteractivePrompt,
-		ContinueAnalysis: baseContinueAnalysisPrompt,
-		PipeAnalysis:     basePipeAnalysisPrompt,
-	}
+// Prompt names, also the names of their template files
+const (
+	NameInteractive     = "interactive"
+	NameContinue        = "continue"
+	NamePipe            = "pipe"
+	NamePipeInteractive = "pipe-interactive"
+	NameFollow          = "follow"
+)
+
+// Names lists the prompt names
+var Names = []string{NameInteractive, NameContinue, NamePipe, NamePipeInteractive, NameFollow}
 
-	// Append language instruction based on user preference
+// basePrompts holds the built-in prompts by name
+var basePrompts = map[string]string{
+	NameInteractive:     baseInteractivePrompt,
+	NameContinue:        baseContinueAnalysisPrompt,
+	NamePipe:            basePipeAnalysisPrompt,
+	NamePipeInteractive: basePipeInteractivePrompt,
+	NameFollow:          baseFollowAnalysisPrompt,
+}
+
+// languageInstruction returns the instruction appended to prompts to answer in lang
+func languageInstruction(lang string) string {
 	if lang == config.LanguageChinese {
-		prompts.Interactive += "\n\nIMPORTANT: Please respond in Chinese (Simplified)."
-		prompts.ContinueAnalysis += "\n\nIMPORTANT: Please respond in Chinese (Simplified)."
-		prompts.PipeAnalysis += "\n\nIMPORTANT: Please respond in Chinese (Simplified)."
-	} else {
-		prompts.Interactive += "\n\nIMPORTANT: Please respond in English."
-		prompts.ContinueAnalysis += "\n\nIMPORTANT: Please respond in English."
-		prompts.PipeAnalysis += "\n\nIMPORTANT: Please respond in English."
+		return "\n\nIMPORTANT: Please respond in Chinese (Simplified)."
 	}
-
-	return prompts
+	return "\n\nIMPORTANT: Please respond in English."
 }
 
 func GetInteractivePrompt() string {
-	prompt := GetSystemPrompts().Interactive
-	return injectBlacklist(prompt)
+	return get(NameInteractive)
 }
 
 func GetContinueAnalysisPrompt() string {
-	prompt := GetSystemPrompts().ContinueAnalysis
-	return injectBlacklist(prompt)
+	return get(NameContinue)
 }
 
 func GetPipeAnalysisPrompt() string {
-	prompt := GetSystemPrompts().PipeAnalysis
-	return injectBlacklist(prompt)
+	return get(NamePipe)
+}
+
+// GetPipeInteractivePrompt returns the pipe analysis prompt that marks suggested
+// commands with [cmd:*], used when they can be executed or reported
+func GetPipeInteractivePrompt() string {
+	return get(NamePipeInteractive)
+}
+
+func GetFollowAnalysisPrompt() string {
+	return get(NameFollow)
+}
+
+// WithReportOutput extends a system prompt with the requirements of machine-readable
+// output: marked commands and a final [severity:<level>] line
+func WithReportOutput(prompt string) string {
+	return prompt + reportOutputPrompt
 }
 
 // injectBlacklist replaces {{COMMAND_BLACKLIST}} placeholder with actual blacklist content
@@ -0,0 +1,151 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/sysinfo"
+)
+
+const (
+	// NameCommon is the template appended to every prompt, e.g. for house rules
+	NameCommon = "common"
+
+	templatesDir      = "prompts"
+	templateExtension = ".tmpl"
+)
+
+// TemplateData holds the variables of prompt templates
+type TemplateData struct {
+	Name      string              // Prompt name, e.g. "interactive"
+	Default   string              // Built-in prompt, to extend rather than replace it
+	Language  string              // Answer language: en or zh
+	Sysinfo   *sysinfo.SystemInfo // System information, nil when unavailable or disabled
+	Blacklist []string            // Command blacklist patterns
+}
+
+var (
+	sysInfoMu sync.RWMutex
+	sysInfo   *sysinfo.SystemInfo
+
+	// warned holds the prompts whose template failed, warned about once
+	warned sync.Map
+)
+
+// templateFuncs are available in prompt templates
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"contains": slices.Contains[[]string],
+}
+
+// SetSystemInfo sets the system information available to prompt templates
+func SetSystemInfo(info *sysinfo.SystemInfo) {
+	sysInfoMu.Lock()
+	defer sysInfoMu.Unlock()
+
+	sysInfo = info
+}
+
+// TemplatePath returns the path of the template file of the named prompt
+func TemplatePath(name string) string {
+	return filepath.Join(config.Get().ConfigDir, templatesDir, name+templateExtension)
+}
+
+// Render returns the named system prompt. A template for the prompt replaces the
+// built-in one and the common template is appended. Templates are read from
+// ~/.aiassist/prompts/<name>.tmpl, else from the prompts of the configuration,
+// which may come from Consul. sources lists the templates used.
+func Render(name string) (text string, sources []string, err error) {
+	base, ok := basePrompts[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown prompt %q (available: %s)", name, strings.Join(Names, ", "))
+	}
+
+	cfg := config.Get()
+	sysInfoMu.RLock()
+	data := TemplateData{
+		Name:      name,
+		Default:   injectBlacklist(base),
+		Language:  cfg.GetLanguage(),
+		Sysinfo:   sysInfo,
+		Blacklist: cfg.GetBlacklist(),
+	}
+	sysInfoMu.RUnlock()
+
+	text = data.Default
+	for _, tmplName := range []string{name, NameCommon} {
+		tmpl, source, err := loadTemplate(tmplName)
+		if err != nil {
+			return "", sources, err
+		}
+		if source == "" {
+			continue
+		}
+		sources = append(sources, source)
+
+		rendered, err := execute(tmplName, tmpl, data)
+		if err != nil {
+			return "", sources, fmt.Errorf("%s: %w", source, err)
+		}
+		if tmplName == name {
+			// Like the built-in prompts, end with a single newline
+			text = strings.TrimRight(rendered, "\n") + "\n"
+		} else if rendered = strings.TrimSpace(rendered); rendered != "" {
+			text += "\n" + rendered + "\n"
+		}
+	}
+
+	return text + languageInstruction(data.Language), sources, nil
+}
+
+// loadTemplate returns the template of the named prompt and where it comes from,
+// or an empty source when there is none
+func loadTemplate(name string) (tmpl, source string, err error) {
+	path := TemplatePath(name)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return string(data), path, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", "", fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	if tmpl, ok := config.Get().GetPromptTemplate(name); ok {
+		return tmpl, fmt.Sprintf("config prompts.%s", name), nil
+	}
+	return "", "", nil
+}
+
+func execute(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// get returns the named system prompt. A failing template is reported once and
+// the built-in prompt is used instead.
+func get(name string) string {
+	text, _, err := Render(name)
+	if err == nil {
+		return text
+	}
+
+	if _, loaded := warned.LoadOrStore(name, true); !loaded {
+		color.Yellow("Warning: prompt template for %s ignored: %v\n", name, err)
+	}
+	return injectBlacklist(basePrompts[name]) + languageInstruction(config.Get().GetLanguage())
+}
@@ -0,0 +1,148 @@
+package prompt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/llaoj/aiassist/internal/config"
+	"github.com/llaoj/aiassist/internal/sysinfo"
+)
+
+func TestRender(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("home directory is not taken from HOME")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvConfig, "")
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := filepath.Join(home, ".aiassist")
+	write(filepath.Join(dir, "config.yaml"), `language: zh
+blacklist:
+  - "reboot"
+prompts:
+  follow: "Team follow prompt for {{.Name}}"
+  interactive: "Overridden by the template file"
+`)
+	write(filepath.Join(dir, "prompts", "interactive.tmpl"), `{{.Default}}
+
+[House Rules]:
+{{- if .Sysinfo}}{{if contains .Sysinfo.AvailableTools "podman"}}
+- Use podman, not docker{{end}}{{end}}
+- Never suggest: {{join .Blacklist ", "}}`)
+	write(filepath.Join(dir, "prompts", "common.tmpl"), "Runbooks: https://wiki.example.com/runbooks ({{.Language}})\n")
+	if err := config.InitWithFile(""); err != nil {
+		t.Fatal(err)
+	}
+
+	SetSystemInfo(&sysinfo.SystemInfo{AvailableTools: []string{"git", "podman"}})
+	defer SetSystemInfo(nil)
+
+	text, sources, err := Render(NameInteractive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Command Blacklist:\n- reboot\n", // The built-in prompt, with the blacklist
+		"[House Rules]:\n- Use podman, not docker\n- Never suggest: reboot\n\nRunbooks: https://wiki.example.com/runbooks (zh)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Render() = %q, missing %q", text, want)
+		}
+	}
+	if !strings.HasSuffix(text, "Please respond in Chinese (Simplified).") {
+		t.Errorf("Render() doesn't end with the language instruction: %q", text[len(text)-80:])
+	}
+	if len(sources) != 2 || sources[0] != filepath.Join(dir, "prompts", "interactive.tmpl") {
+		t.Errorf("Render() sources = %q", sources)
+	}
+
+	// Templates from the configuration, e.g. from Consul
+	text, sources, err = Render(NameFollow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, "Team follow prompt for follow\n\nRunbooks:") || sources[0] != "config prompts.follow" {
+		t.Errorf("Render() = %q from %q", text, sources)
+	}
+
+	// Without a template of its own, the built-in prompt is used
+	text, _, err = Render(NamePipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, injectBlacklist(basePipeAnalysisPrompt)) {
+		t.Errorf("Render() didn't use the built-in pipe prompt")
+	}
+
+	// A broken template falls back to the built-in prompt
+	write(filepath.Join(dir, "prompts", "continue.tmpl"), "{{.Unknown}}")
+	if _, _, err := Render(NameContinue); err == nil || !strings.Contains(err.Error(), "continue.tmpl") {
+		t.Errorf("Render() of a broken template returned %v", err)
+	}
+	if got := GetContinueAnalysisPrompt(); !strings.HasPrefix(got, injectBlacklist(baseContinueAnalysisPrompt)) {
+		t.Errorf("GetContinueAnalysisPrompt() didn't fall back to the built-in prompt")
+	}
+
+	if _, _, err := Render("unknown"); err == nil {
+		t.Error("Render() of an unknown prompt returned no error")
+	}
+}
+
+func TestRenderConsulTemplate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("home directory is not taken from HOME")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvConfig, "")
+
+	// A Consul agent serving the shared configuration
+	shared := `language: en
+prompts:
+  follow: "Fleet follow prompt for {{.Name}}"
+`
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/aiassist/config" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{{"Key": "aiassist/config", "Value": []byte(shared)}})
+	}))
+	defer consul.Close()
+
+	dir := filepath.Join(home, ".aiassist")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	local := "consul:\n  enabled: true\n  address: " + strings.TrimPrefix(consul.URL, "http://") + "\n  key: aiassist/config\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.InitWithFile(""); err != nil {
+		t.Fatal(err)
+	}
+
+	text, sources, err := Render(NameFollow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(text, "Fleet follow prompt for follow\n") || len(sources) != 1 || sources[0] != "config prompts.follow" {
+		t.Errorf("Render() = %q from %q", text, sources)
+	}
+}
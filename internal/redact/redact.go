@@ -0,0 +1,250 @@
+// Package redact replaces sensitive values in text sent to language models with
+// placeholders, and restores the values in the responses.
+package redact
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// placeholderPattern matches placeholders like REDACTED_EMAIL_1
+var placeholderPattern = regexp.MustCompile(`\bREDACTED_[A-Z0-9_]+_[0-9]+\b`)
+
+// minLiteralLength is the length below which literal values aren't redacted,
+// since short words like a hostname "db" occur everywhere
+const minLiteralLength = 3
+
+// commonLiterals identify nobody and are left as is
+var commonLiterals = []string{"root", "localhost", "localhost.localdomain"}
+
+// Detector finds one kind of sensitive value.
+type Detector struct {
+	Name    string         // Kind of value, used in placeholders, e.g. "email"
+	Pattern *regexp.Regexp // Matches the value, or the context of the value in its first group
+	Valid   func(string) bool
+}
+
+// Pattern is a custom detector given as a regular expression
+type Pattern struct {
+	Name  string
+	Regex string
+}
+
+// Options configures a Redactor
+type Options struct {
+	Exclude  []string  // Names of built-in detectors not to apply
+	Patterns []Pattern // Custom detectors, applied before the built-in ones
+}
+
+// Redaction is a value replaced by a placeholder
+type Redaction struct {
+	Placeholder string
+	Value       string
+	Detector    string
+}
+
+// Redactor replaces sensitive values with placeholders. A value always gets the
+// same placeholder, so the model can relate occurrences across a session.
+// A nil Redactor leaves text unchanged.
+type Redactor struct {
+	detectors []Detector
+	exclude   []string
+
+	mu           sync.Mutex
+	placeholders map[string]string    // Value by placeholder
+	values       map[string]Redaction // Redaction by detector and value
+	counts       map[string]int       // Placeholders by kind
+}
+
+// Builtin returns the built-in detectors
+func Builtin() []Detector {
+	return []Detector{
+		{Name: "private_key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)},
+		{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+		{Name: "aws_key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "aws_secret", Pattern: regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`)},
+		{Name: "bearer", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]+=*)`)},
+		// password=..., DB_PASSWORD: ..., "api_key": "...", token=...
+		{Name: "password", Pattern: regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|credentials?)["']?\s*[=:]\s*["']?([^\s"'&,;]+)`)},
+		{Name: "email", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+		{Name: "ipv6", Pattern: regexp.MustCompile(`(?i)\b(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}\b`), Valid: validIP(true)},
+		{Name: "ipv4", Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\b`), Valid: validIP(false)},
+	}
+}
+
+// validIP accepts IP addresses that identify a host, leaving out loopback,
+// unspecified and broadcast addresses
+func validIP(v6 bool) func(string) bool {
+	return func(s string) bool {
+		ip := net.ParseIP(s)
+		if ip == nil || strings.Contains(s, ":") != v6 {
+			return false
+		}
+		return !ip.IsLoopback() && !ip.IsUnspecified() && !ip.Equal(net.IPv4bcast)
+	}
+}
+
+// New returns a Redactor applying the custom patterns of opts and the built-in
+// detectors that aren't excluded.
+func New(opts Options) (*Redactor, error) {
+	r := &Redactor{
+		exclude:      opts.Exclude,
+		placeholders: make(map[string]string),
+		values:       make(map[string]Redaction),
+		counts:       make(map[string]int),
+	}
+
+	for _, p := range opts.Patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", p.Name, err)
+		}
+		r.detectors = append(r.detectors, Detector{Name: p.Name, Pattern: re})
+	}
+
+	builtin := Builtin()
+	for _, name := range opts.Exclude {
+		known := slices.ContainsFunc(builtin, func(d Detector) bool { return d.Name == name })
+		if !known && name != "hostname" && name != "user" {
+			return nil, fmt.Errorf("unknown redaction detector %q", name)
+		}
+	}
+	for _, d := range builtin {
+		if !slices.Contains(opts.Exclude, d.Name) {
+			r.detectors = append(r.detectors, d)
+		}
+	}
+	return r, nil
+}
+
+// AddLiteral redacts value wherever it occurs as a whole word, e.g. the hostname.
+// Values that are short, common or excluded by name are ignored.
+func (r *Redactor) AddLiteral(name, value string) {
+	if r == nil || len(value) < minLiteralLength || slices.Contains(commonLiterals, value) || slices.Contains(r.exclude, name) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pattern := regexp.MustCompile(`(?:^|\b)` + regexp.QuoteMeta(value) + `(?:\b|$)`)
+	for _, d := range r.detectors {
+		if d.Name == name && d.Pattern.String() == pattern.String() {
+			return
+		}
+	}
+	r.detectors = append(r.detectors, Detector{Name: name, Pattern: pattern})
+}
+
+// Redact replaces the sensitive values in text with placeholders. It returns the
+// redactions made, in order of appearance.
+func (r *Redactor) Redact(text string) (string, []Redaction) {
+	if r == nil {
+		return text, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []Redaction
+	for _, d := range r.detectors {
+		text = replace(d.Pattern, text, func(value string) string {
+			// Values of earlier detectors, e.g. password=REDACTED_JWT_1
+			if placeholderPattern.FindString(value) == value {
+				return value
+			}
+			if d.Valid != nil && !d.Valid(value) {
+				return value
+			}
+			redaction := r.redaction(d.Name, value)
+			if !slices.Contains(found, redaction) {
+				found = append(found, redaction)
+			}
+			return redaction.Placeholder
+		})
+	}
+	return text, found
+}
+
+// redaction returns the redaction of value, creating its placeholder if needed
+func (r *Redactor) redaction(detector, value string) Redaction {
+	key := detector + "\x00" + value
+	if redaction, ok := r.values[key]; ok {
+		return redaction
+	}
+
+	kind := placeholderKind(detector)
+	r.counts[kind]++
+	redaction := Redaction{
+		Placeholder: fmt.Sprintf("REDACTED_%s_%d", kind, r.counts[kind]),
+		Value:       value,
+		Detector:    detector,
+	}
+	r.values[key] = redaction
+	r.placeholders[redaction.Placeholder] = value
+	return redaction
+}
+
+// placeholderKind turns a detector name into the kind part of its placeholders
+func placeholderKind(name string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z':
+			return c - 'a' + 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return c
+		}
+		return '_'
+	}, name)
+}
+
+// replace replaces the matches of re in text with fn of the match, or of its
+// first group when re has groups
+func replace(re *regexp.Regexp, text string, fn func(string) string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllStringFunc(text, fn)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[2], m[3]
+		if start < 0 {
+			continue
+		}
+		sb.WriteString(text[last:start])
+		sb.WriteString(fn(text[start:end]))
+		last = end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// Restore replaces the placeholders in text with the original values
+func (r *Redactor) Restore(text string) string {
+	if r == nil {
+		return text
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Values may contain placeholders of earlier detectors
+	for range 3 {
+		restored := placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			if value, ok := r.placeholders[placeholder]; ok {
+				return value
+			}
+			return placeholder
+		})
+		if restored == text {
+			break
+		}
+		text = restored
+	}
+	return text
+}
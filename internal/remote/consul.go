@@ -0,0 +1,46 @@
+package remote
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulServiceHosts returns the nodes running a service registered in the Consul
+// catalog, with the service tags as groups. Empty address and token use the Consul
+// defaults, e.g. CONSUL_HTTP_ADDR and CONSUL_HTTP_TOKEN.
+func ConsulServiceHosts(address, token, service string) ([]Host, error) {
+	config := api.DefaultConfig()
+	if address != "" {
+		config.Address = address
+	}
+	if token != "" {
+		config.Token = token
+	}
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %w", err)
+	}
+
+	services, _, err := client.Catalog().Service(service, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service %s from consul: %w", service, err)
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("service %s has no instances in consul", service)
+	}
+
+	// A node may run several instances of the service, SSH only once
+	seen := make(map[string]bool)
+	var hosts []Host
+	for _, s := range services {
+		if seen[s.Node] {
+			continue
+		}
+		seen[s.Node] = true
+		hosts = append(hosts, Host{Name: s.Node, Address: s.Address, Groups: s.ServiceTags})
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts, nil
+}
@@ -0,0 +1,100 @@
+package remote
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+const inventoryFile = "inventory.yaml"
+
+// Host is an inventory entry
+type Host struct {
+	Name    string   `yaml:"name"`
+	Address string   `yaml:"address"`          // Host name or IP address
+	User    string   `yaml:"user,omitempty"`   // SSH user, default from ~/.ssh/config or the local user
+	Port    int      `yaml:"port,omitempty"`   // SSH port, default 22
+	Groups  []string `yaml:"groups,omitempty"` // Groups the host belongs to
+}
+
+// Target returns the SSH target of the host as [user@]address[:port]
+func (h Host) Target() string {
+	target := h.Address
+	if h.Port > 0 {
+		target = net.JoinHostPort(h.Address, strconv.Itoa(h.Port))
+	}
+	if h.User != "" {
+		target = h.User + "@" + target
+	}
+	return target
+}
+
+// Inventory lists the hosts commands can run on, see --host
+type Inventory struct {
+	Hosts []Host `yaml:"hosts"`
+}
+
+// DefaultInventoryPath returns ~/.aiassist/inventory.yaml
+func DefaultInventoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".aiassist", inventoryFile), nil
+}
+
+// LoadInventory reads an inventory file
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var inv Inventory
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory %s: %w", path, err)
+	}
+	for i, h := range inv.Hosts {
+		if h.Name == "" || h.Address == "" {
+			return nil, fmt.Errorf("inventory %s: host %d needs a name and an address", path, i+1)
+		}
+	}
+	return &inv, nil
+}
+
+// Find returns the host with the given name, or nil
+func (inv *Inventory) Find(name string) *Host {
+	for i := range inv.Hosts {
+		if inv.Hosts[i].Name == name {
+			return &inv.Hosts[i]
+		}
+	}
+	return nil
+}
+
+// Group returns the hosts in the given group
+func (inv *Inventory) Group(name string) []Host {
+	var hosts []Host
+	for _, h := range inv.Hosts {
+		if slices.Contains(h.Groups, name) {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// ResolveTarget resolves --host: inventory host names map to their target,
+// anything else is used as [user@]host[:port]. inv may be nil.
+func ResolveTarget(host string, inv *Inventory) string {
+	if inv != nil {
+		if h := inv.Find(host); h != nil {
+			return h.Target()
+		}
+	}
+	return host
+}
@@ -0,0 +1,341 @@
+// Package remote runs commands on other hosts over SSH
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultPort    = 22
+	defaultTimeout = 10 * time.Second
+	ptyRows        = 50
+)
+
+// Options configures how to connect. DefaultOptions uses the same files as ssh.
+type Options struct {
+	ConfigFile      string   // ssh client config, e.g. ~/.ssh/config
+	KnownHostsFiles []string // Host keys to verify the server against
+	IdentityFiles   []string // Private keys tried after the agent and IdentityFile entries
+	UseAgent        bool     // Use keys from the ssh-agent at $SSH_AUTH_SOCK
+	Timeout         time.Duration
+}
+
+// DefaultOptions returns the options of a standard OpenSSH setup
+func DefaultOptions() Options {
+	opts := Options{
+		KnownHostsFiles: []string{"/etc/ssh/ssh_known_hosts"},
+		UseAgent:        true,
+		Timeout:         defaultTimeout,
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		sshDir := filepath.Join(home, ".ssh")
+		opts.ConfigFile = filepath.Join(sshDir, "config")
+		opts.KnownHostsFiles = append([]string{filepath.Join(sshDir, "known_hosts")}, opts.KnownHostsFiles...)
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			opts.IdentityFiles = append(opts.IdentityFiles, filepath.Join(sshDir, name))
+		}
+	}
+	return opts
+}
+
+// Client runs commands on a remote host
+type Client struct {
+	client *ssh.Client
+	target string
+}
+
+// Dial connects to target, given as [user@]host[:port] where host may be an
+// alias from the ssh config. Host keys must already be in known_hosts.
+func Dial(target string, opts Options) (*Client, error) {
+	user, host, port, err := parseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &sshConfig{}
+	if opts.ConfigFile != "" {
+		if config, err = loadSSHConfig(opts.ConfigFile); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", opts.ConfigFile, err)
+		}
+	}
+
+	// Apply the ssh config to the name given on the command line
+	alias := host
+	if hostName := config.Get(alias, "HostName"); hostName != "" {
+		host = strings.ReplaceAll(hostName, "%h", alias)
+	}
+	if user == "" {
+		user = config.Get(alias, "User")
+	}
+	if user == "" {
+		user = currentUser()
+	}
+	if port == 0 {
+		if p, err := strconv.Atoi(config.Get(alias, "Port")); err == nil {
+			port = p
+		}
+	}
+	if port == 0 {
+		port = defaultPort
+	}
+
+	identityFiles := append(expandHome(config.GetAll(alias, "IdentityFile")), opts.IdentityFiles...)
+	signers, closeAgent, err := loadSigners(opts.UseAgent, identityFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	hostKeyCallback, err := loadKnownHosts(opts.KnownHostsFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	clientConfig := &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}
+
+	client, err := ssh.Dial("tcp", address, clientConfig)
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) && len(keyErr.Want) > 0 {
+		// The server may have offered a key type that isn't in known_hosts:
+		// retry asking for the known key types only
+		for _, known := range keyErr.Want {
+			clientConfig.HostKeyAlgorithms = append(clientConfig.HostKeyAlgorithms, hostKeyAlgorithms(known.Key.Type())...)
+		}
+		client, err = ssh.Dial("tcp", address, clientConfig)
+	}
+	if err != nil {
+		return nil, dialError(target, err)
+	}
+
+	return &Client{client: client, target: user + "@" + displayHost(alias, port)}, nil
+}
+
+// DialAll connects to targets, at most parallel at a time. Clients and errors are
+// in the order of targets, with either the client or the error set for each.
+func DialAll(targets []string, opts Options, parallel int) ([]*Client, []error) {
+	clients := make([]*Client, len(targets))
+	errs := make([]error, len(targets))
+
+	sem := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			clients[i], errs[i] = Dial(target, opts)
+		}()
+	}
+	wg.Wait()
+	return clients, errs
+}
+
+// Target returns where commands run, as user@host
+func (c *Client) Target() string {
+	return c.target
+}
+
+// Run executes script with sh on the remote host and returns its combined output.
+// With a terminal width above zero the script runs in a pseudo-terminal of that width.
+func (c *Client) Run(script string, terminalWidth int) (string, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	defer session.Close()
+
+	if terminalWidth > 0 {
+		modes := ssh.TerminalModes{ssh.ECHO: 0}
+		if err := session.RequestPty("xterm-256color", ptyRows, terminalWidth, modes); err != nil {
+			return "", fmt.Errorf("failed to request pseudo-terminal: %w", err)
+		}
+	}
+
+	// Stdout and stderr are copied concurrently
+	var output lockedBuffer
+	session.Stdout = &output
+	session.Stderr = &output
+
+	// Use sh whatever the login shell of the user is
+	err = session.Run("sh -c " + shellQuote(script))
+	return output.String(), err
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// parseTarget splits [user@]host[:port]; IPv6 addresses with a port need brackets
+func parseTarget(target string) (string, string, int, error) {
+	var user string
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		user, target = target[:i], target[i+1:]
+	}
+	if target == "" {
+		return "", "", 0, fmt.Errorf("invalid host %q", target)
+	}
+
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		// No port given
+		return user, strings.Trim(target, "[]"), 0, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", "", 0, fmt.Errorf("invalid port in host %q", target)
+	}
+	return user, host, port, nil
+}
+
+func displayHost(host string, port int) string {
+	if port == defaultPort {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func expandHome(paths []string) []string {
+	home, _ := os.UserHomeDir()
+	expanded := make([]string, len(paths))
+	for i, p := range paths {
+		if strings.HasPrefix(p, "~/") && home != "" {
+			p = filepath.Join(home, p[2:])
+		}
+		expanded[i] = p
+	}
+	return expanded
+}
+
+// loadSigners collects keys from the agent and unencrypted identity files. Keys of
+// the agent sign through its connection, closed with closeAgent after the handshake.
+func loadSigners(useAgent bool, identityFiles []string) (signers []ssh.Signer, closeAgent func(), err error) {
+	closeAgent = func() {}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); useAgent && sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			closeAgent = func() { conn.Close() }
+			if agentSigners, err := agent.NewClient(conn).Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range identityFiles {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			// Passphrase protected keys are only used through the agent
+			continue
+		}
+		signers = append(signers, signer)
+	}
+
+	if len(signers) == 0 {
+		closeAgent()
+		return nil, nil, errors.New("no SSH keys available: add a key to ssh-agent or create ~/.ssh/id_ed25519")
+	}
+	return signers, closeAgent, nil
+}
+
+func loadKnownHosts(files []string) (ssh.HostKeyCallback, error) {
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			existing = append(existing, file)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, errors.New("no known_hosts file found: connect once with ssh to verify and add the host key")
+	}
+
+	callback, err := knownhosts.New(existing...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// hostKeyAlgorithms returns the host key algorithms for a key type
+func hostKeyAlgorithms(keyType string) []string {
+	if keyType == ssh.KeyAlgoRSA {
+		return []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	}
+	return []string{keyType}
+}
+
+// dialError explains host key failures, which are the most common setup problem
+func dialError(target string, err error) error {
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) == 0 {
+			return fmt.Errorf("host key of %s is not in known_hosts: connect once with ssh to verify and add it", target)
+		}
+		return fmt.Errorf("host key of %s does not match known_hosts: the host may have been reinstalled, or the connection intercepted", target)
+	}
+	return fmt.Errorf("failed to connect to %s: %w", target, err)
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,440 @@
+package remote
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/llaoj/aiassist/internal/executor"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testServer is an in-process SSH server running exec requests with the local sh
+type testServer struct {
+	addr    string
+	hostKey ssh.Signer
+}
+
+func newTestSigner(t *testing.T) (ssh.Signer, ed25519.PrivateKey) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, key
+}
+
+func startTestServer(t *testing.T, clientKey ssh.PublicKey) *testServer {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	hostKey, _ := newTestSigner(t)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "tester" && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unauthorized")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+	return &testServer{addr: listener.Addr().String(), hostKey: hostKey}
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				switch req.Type {
+				case "pty-req":
+					req.Reply(true, nil)
+				case "exec":
+					var payload struct{ Command string }
+					if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+						req.Reply(false, nil)
+						continue
+					}
+					req.Reply(true, nil)
+
+					cmd := exec.Command("sh", "-c", payload.Command)
+					cmd.Stdout = channel
+					cmd.Stderr = channel.Stderr()
+					status := uint32(0)
+					if err := cmd.Run(); err != nil {
+						var exitErr *exec.ExitError
+						if errors.As(err, &exitErr) {
+							status = uint32(exitErr.ExitCode())
+						} else {
+							status = 127
+						}
+					}
+					channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+					return
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
+
+// clientOptions writes a known_hosts file trusting hostKey, the client identity and
+// an ssh config into a temporary directory
+func clientOptions(t *testing.T, hostAddr string, hostKey ssh.PublicKey, clientKey ed25519.PrivateKey, sshConfig string) Options {
+	t.Helper()
+	dir := t.TempDir()
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostAddr)}, hostKey)
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(identity, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(dir, "config")
+	if err := os.WriteFile(configFile, []byte(sshConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return Options{
+		ConfigFile:      configFile,
+		KnownHostsFiles: []string{knownHosts},
+		IdentityFiles:   []string{identity},
+	}
+}
+
+func TestDialAndRun(t *testing.T) {
+	clientSigner, clientKey := newTestSigner(t)
+	server := startTestServer(t, clientSigner.PublicKey())
+	_, port, _ := net.SplitHostPort(server.addr)
+
+	sshConfig := fmt.Sprintf("Host box\n  HostName 127.0.0.1\n  Port %s\n  User tester\n", port)
+	opts := clientOptions(t, server.addr, server.hostKey.PublicKey(), clientKey, sshConfig)
+
+	tests := []struct {
+		target     string
+		wantTarget string
+	}{
+		{"tester@" + server.addr, "tester@" + server.addr},
+		{"box", "tester@box:" + port},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			client, err := Dial(tt.target, opts)
+			if err != nil {
+				t.Fatalf("Dial(%q) error = %v", tt.target, err)
+			}
+			defer client.Close()
+
+			if client.Target() != tt.wantTarget {
+				t.Errorf("Target() = %q, want %q", client.Target(), tt.wantTarget)
+			}
+
+			output, err := client.Run("echo 'it''s' out; echo err >&2; exit 3", 0)
+			var exitErr *ssh.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 {
+				t.Errorf("Run() error = %v, want exit status 3", err)
+			}
+			if !strings.Contains(output, "its out\n") || !strings.Contains(output, "err\n") {
+				t.Errorf("Run() output = %q", output)
+			}
+		})
+	}
+}
+
+func TestDialRejectsUnknownHostKey(t *testing.T) {
+	clientSigner, clientKey := newTestSigner(t)
+	server := startTestServer(t, clientSigner.PublicKey())
+	otherKey, _ := newTestSigner(t)
+
+	opts := clientOptions(t, server.addr, otherKey.PublicKey(), clientKey, "")
+	if _, err := Dial("tester@"+server.addr, opts); err == nil || !strings.Contains(err.Error(), "does not match known_hosts") {
+		t.Errorf("Dial() with a changed host key error = %v", err)
+	}
+
+	opts = clientOptions(t, "127.0.0.1:1", server.hostKey.PublicKey(), clientKey, "")
+	if _, err := Dial("tester@"+server.addr, opts); err == nil || !strings.Contains(err.Error(), "not in known_hosts") {
+		t.Errorf("Dial() with an unknown host error = %v", err)
+	}
+}
+
+func TestDialClosesAgentConnection(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unix socket agent")
+	}
+	clientSigner, clientKey := newTestSigner(t)
+	server := startTestServer(t, clientSigner.PublicKey())
+
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: clientKey}); err != nil {
+		t.Fatal(err)
+	}
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	served := make(chan struct{})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		agent.ServeAgent(keyring, conn)
+		close(served)
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	opts := clientOptions(t, server.addr, server.hostKey.PublicKey(), clientKey, "")
+	opts.IdentityFiles = nil
+	opts.UseAgent = true
+	client, err := Dial("tester@"+server.addr, opts)
+	if err != nil {
+		t.Fatalf("Dial() with the agent error = %v", err)
+	}
+	defer client.Close()
+
+	// The agent is only needed for the handshake
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Error("Dial() left the agent connection open")
+	}
+}
+
+func TestRemoteExecutor(t *testing.T) {
+	clientSigner, clientKey := newTestSigner(t)
+	server := startTestServer(t, clientSigner.PublicKey())
+	opts := clientOptions(t, server.addr, server.hostKey.PublicKey(), clientKey, "")
+
+	client, err := Dial("tester@"+server.addr, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// SetRunner captures the shell state, so the zero executor is enough here
+	ce := &executor.CommandExecutor{}
+	if err := ce.SetRunner(client); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ce.ExecuteCommand("cd / && export AIASSIST_SSH_TEST=1"); err != nil {
+		t.Fatal(err)
+	}
+	output, err := ce.ExecuteCommand(`echo "$(pwd) $AIASSIST_SSH_TEST"`)
+	if err != nil || output != "/ 1\n" {
+		t.Errorf("ExecuteCommand() = %q, %v, want %q", output, err, "/ 1\n")
+	}
+	if !strings.Contains(ce.ShellContext(), "Host: tester@"+server.addr) {
+		t.Errorf("ShellContext() = %q, want the host", ce.ShellContext())
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		user   string
+		host   string
+		port   int
+		err    bool
+	}{
+		{"web1", "", "web1", 0, false},
+		{"admin@web1", "admin", "web1", 0, false},
+		{"admin@10.0.0.1:2222", "admin", "10.0.0.1", 2222, false},
+		{"[::1]:22", "", "::1", 22, false},
+		{"::1", "", "::1", 0, false},
+		{"web1:ssh", "", "", 0, true},
+		{"admin@", "", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		user, host, port, err := parseTarget(tt.target)
+		if (err != nil) != tt.err {
+			t.Errorf("parseTarget(%q) error = %v, wantErr %v", tt.target, err, tt.err)
+			continue
+		}
+		if user != tt.user || host != tt.host || port != tt.port {
+			t.Errorf("parseTarget(%q) = %q, %q, %d, want %q, %q, %d", tt.target, user, host, port, tt.user, tt.host, tt.port)
+		}
+	}
+}
+
+func TestParseSSHConfig(t *testing.T) {
+	config, err := parseSSHConfig(strings.NewReader(`
+# Global default
+IdentityFile ~/.ssh/global
+
+Host web* !web-old
+    HostName %h.example.com
+    User deploy
+    IdentityFile ~/.ssh/web
+
+Host db
+    HostName=10.0.0.5
+    Port 2222
+
+Match host db
+    User ignored
+
+Host *
+    User fallback
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host, keyword, want string
+	}{
+		{"web1", "HostName", "%h.example.com"},
+		{"web1", "user", "deploy"},
+		{"web-old", "User", "fallback"},
+		{"db", "HostName", "10.0.0.5"},
+		{"db", "Port", "2222"},
+		{"db", "User", "fallback"},
+		{"other", "HostName", ""},
+	}
+	for _, tt := range tests {
+		if got := config.Get(tt.host, tt.keyword); got != tt.want {
+			t.Errorf("Get(%q, %q) = %q, want %q", tt.host, tt.keyword, got, tt.want)
+		}
+	}
+
+	if got := config.GetAll("web1", "IdentityFile"); strings.Join(got, ",") != "~/.ssh/global,~/.ssh/web" {
+		t.Errorf("GetAll(web1, IdentityFile) = %v", got)
+	}
+}
+
+func TestLoadInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.yaml")
+	data := `hosts:
+  - name: web1
+    address: 10.0.0.1
+    user: admin
+    groups: [web]
+  - name: db
+    address: db.internal
+    port: 2222
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"web1":         "admin@10.0.0.1",
+		"db":           "db.internal:2222",
+		"root@web2:22": "root@web2:22",
+	}
+	for host, want := range tests {
+		if got := ResolveTarget(host, inv); got != want {
+			t.Errorf("ResolveTarget(%q) = %q, want %q", host, got, want)
+		}
+	}
+
+	if group := inv.Group("web"); len(group) != 1 || group[0].Name != "web1" {
+		t.Errorf("Group(web) = %v, want web1", group)
+	}
+	if group := inv.Group("db"); len(group) != 0 {
+		t.Errorf("Group(db) = %v, want none", group)
+	}
+
+	if err := os.WriteFile(path, []byte("hosts:\n  - name: web1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadInventory(path); err == nil {
+		t.Error("LoadInventory() accepted a host without address")
+	}
+}
+
+func TestConsulServiceHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/catalog/service/nginx" || r.Header.Get("X-Consul-Token") != "secret" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"Node": "web2", "Address": "10.0.0.2", "ServiceTags": ["edge"]},
+			{"Node": "web1", "Address": "10.0.0.1", "ServicePort": 80},
+			{"Node": "web1", "Address": "10.0.0.1", "ServicePort": 8080}
+		]`)
+	}))
+	defer server.Close()
+
+	hosts, err := ConsulServiceHosts(server.URL, "secret", "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0].Target() != "10.0.0.1" || hosts[1].Name != "web2" || hosts[1].Groups[0] != "edge" {
+		t.Errorf("ConsulServiceHosts() = %+v", hosts)
+	}
+
+	if _, err := ConsulServiceHosts(server.URL, "secret", "missing"); err == nil {
+		t.Error("ConsulServiceHosts() of an unknown service succeeded")
+	}
+}
@@ -0,0 +1,106 @@
+package remote
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// sshConfig is a minimal reader of OpenSSH client configuration files. It supports
+// Host blocks with wildcard and negated patterns and the keywords aiassist needs:
+// HostName, User, Port and IdentityFile. Match blocks and Include are ignored.
+type sshConfig struct {
+	blocks []sshConfigBlock
+}
+
+type sshConfigBlock struct {
+	patterns []string
+	settings map[string][]string // Lower-cased keyword to values in file order
+}
+
+// loadSSHConfig reads an ssh config file. A missing file yields an empty config.
+func loadSSHConfig(file string) (*sshConfig, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &sshConfig{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return parseSSHConfig(f)
+}
+
+func parseSSHConfig(r io.Reader) (*sshConfig, error) {
+	// Settings before the first Host line apply to all hosts
+	current := sshConfigBlock{patterns: []string{"*"}, settings: make(map[string][]string)}
+	config := &sshConfig{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value := splitConfigLine(line)
+		keyword = strings.ToLower(keyword)
+		switch keyword {
+		case "host":
+			config.blocks = append(config.blocks, current)
+			current = sshConfigBlock{patterns: strings.Fields(value), settings: make(map[string][]string)}
+		case "match":
+			// Not supported: skip the block
+			config.blocks = append(config.blocks, current)
+			current = sshConfigBlock{settings: make(map[string][]string)}
+		default:
+			current.settings[keyword] = append(current.settings[keyword], strings.Trim(value, `"`))
+		}
+	}
+	config.blocks = append(config.blocks, current)
+	return config, scanner.Err()
+}
+
+// splitConfigLine splits "Keyword value" or "Keyword=value"
+func splitConfigLine(line string) (string, string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimLeft(line[i:], " \t=")
+}
+
+// Get returns the first value of keyword for host, as the first obtained value wins in ssh
+func (c *sshConfig) Get(host, keyword string) string {
+	if values := c.GetAll(host, keyword); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// GetAll returns all values of keyword for host, e.g. every IdentityFile
+func (c *sshConfig) GetAll(host, keyword string) []string {
+	var values []string
+	for _, block := range c.blocks {
+		if block.matches(host) {
+			values = append(values, block.settings[strings.ToLower(keyword)]...)
+		}
+	}
+	return values
+}
+
+func (b sshConfigBlock) matches(host string) bool {
+	matched := false
+	for _, pattern := range b.patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		if ok, _ := path.Match(strings.TrimPrefix(pattern, "!"), host); ok {
+			if negated {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
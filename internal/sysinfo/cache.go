@@ -0,0 +1,117 @@
+package sysinfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/llaoj/aiassist/internal/config"
+)
+
+// refreshes tracks background refreshes of the cache
+var refreshes sync.WaitGroup
+
+// cacheTTL returns the age after which cached system information is refreshed
+func cacheTTL() time.Duration {
+	if cfg := config.Get(); cfg != nil {
+		return cfg.GetSysinfoTTL()
+	}
+	return config.DefaultSysinfoTTL
+}
+
+// WaitForRefresh waits up to timeout for a background refresh of the cache to
+// finish, so that exiting doesn't interrupt it. It reports whether none is left.
+func WaitForRefresh(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		refreshes.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// refreshInBackground recollects the system information and updates the cache
+// without delaying the caller. Failures keep the current cache.
+func refreshInBackground() {
+	refreshes.Add(1)
+	go func() {
+		defer refreshes.Done()
+		_, _ = CollectAndSave()
+	}()
+}
+
+// toolDirs are the system directories whose modification time changes when tools
+// are installed or removed. A fixed list rather than PATH, which differs between
+// cron, login and interactive shells.
+var toolDirs = []string{
+	"/bin", "/sbin", "/usr/bin", "/usr/sbin", "/usr/local/bin", "/usr/local/sbin",
+	"/opt/homebrew/bin", "/snap/bin",
+}
+
+// fingerprint cheaply identifies the state of the system the cached information
+// depends on: the kernel version, the distribution release and the system tool
+// directories.
+func fingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "kernel=%s\n", kernelVersion())
+	if data, err := os.ReadFile("/etc/os-release"); err == nil {
+		fmt.Fprintf(h, "os-release=%x\n", sha256.Sum256(data))
+	}
+	for _, dir := range toolDirs {
+		if stat, err := os.Stat(dir); err == nil {
+			fmt.Fprintf(h, "path=%s %d\n", dir, stat.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// kernelVersion returns the kernel release without collecting the full system information
+func kernelVersion() string {
+	switch runtime.GOOS {
+	case "linux":
+		if data, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	case "windows":
+		return ""
+	}
+	output, err := exec.Command("uname", "-r").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// writeFileAtomic writes data to a temporary file renamed over path, so that
+// concurrent readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,147 @@
+package sysinfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLoadOrCollect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("home directory is not taken from HOME")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// Without kubectl in PATH, no Kubernetes refresh gets in the way
+	t.Setenv("PATH", t.TempDir())
+
+	cache := func(info *SystemInfo) {
+		t.Helper()
+		if err := Save(info); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A fresh cache is used as is
+	cache(&SystemInfo{Hostname: "cached", CollectedAt: time.Now(), Fingerprint: fingerprint()})
+	info, err := LoadOrCollect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname != "cached" {
+		t.Errorf("LoadOrCollect() recollected a fresh cache")
+	}
+
+	// An expired cache is used, then refreshed in the background
+	cache(&SystemInfo{Hostname: "cached", CollectedAt: time.Now().Add(-48 * time.Hour), Fingerprint: fingerprint()})
+	if info, err = LoadOrCollect(); err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname != "cached" {
+		t.Errorf("LoadOrCollect() didn't use the expired cache")
+	}
+	if !WaitForRefresh(10 * time.Second) {
+		t.Fatal("WaitForRefresh() timed out")
+	}
+	if info, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname == "cached" || time.Since(info.CollectedAt) > time.Minute {
+		t.Errorf("LoadOrCollect() didn't refresh the expired cache")
+	}
+
+	// A changed system is recollected right away
+	cache(&SystemInfo{Hostname: "cached", CollectedAt: time.Now(), Fingerprint: "0123456789abcdef"})
+	if info, err = LoadOrCollect(); err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname == "cached" || info.Fingerprint != fingerprint() {
+		t.Errorf("LoadOrCollect() used the cache of a changed system")
+	}
+
+	// Atomic writes leave no temporary files behind
+	entries, err := os.ReadDir(filepath.Join(home, configDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != sysInfoFile {
+		t.Errorf("config directory contains %d entries, want only %s", len(entries), sysInfoFile)
+	}
+}
+
+func TestLoadOrCollectVolatile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("home directory is not taken from HOME")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+
+	saved := registeredCollectors()
+	defer func() { collectors = saved }()
+	RegisterCollector(volatileCollector{collector{"filesystems", time.Second, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+		return func(info *SystemInfo) { info.Filesystems = []Filesystem{{Mount: "/", Size: 100, Used: 100}} }, nil
+	}}})
+	RegisterCollector(volatileCollector{collector{"memory", time.Second, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+		return nil, errors.New("failed")
+	}}})
+
+	for _, age := range []time.Duration{time.Minute, 48 * time.Hour} {
+		cached := &SystemInfo{
+			Hostname:    "cached",
+			Filesystems: []Filesystem{{Mount: "/", Size: 100, Used: 40}},
+			Memory:      &MemoryInfo{Total: 1 << 30, Available: 1 << 29},
+			CollectedAt: time.Now().Add(-age),
+			Fingerprint: fingerprint(),
+		}
+		if err := Save(cached); err != nil {
+			t.Fatal(err)
+		}
+		info, err := LoadOrCollect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !WaitForRefresh(10 * time.Second) {
+			t.Fatal("WaitForRefresh() timed out")
+		}
+
+		if info.Hostname != "cached" {
+			t.Errorf("cache collected %v ago: LoadOrCollect() didn't use the cache", age)
+		}
+		if len(info.Filesystems) != 1 || info.Filesystems[0].Used != 100 {
+			t.Errorf("cache collected %v ago: LoadOrCollect() filesystems = %+v, want them collected anew", age, info.Filesystems)
+		}
+		if info.Memory != nil {
+			t.Errorf("cache collected %v ago: LoadOrCollect() served the cached memory %+v", age, info.Memory)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	saved := toolDirs
+	toolDirs = []string{dir}
+	defer func() { toolDirs = saved }()
+	before := fingerprint()
+	if before != fingerprint() {
+		t.Fatal("fingerprint() isn't stable")
+	}
+
+	// PATH differs between cron and interactive shells
+	t.Setenv("PATH", t.TempDir())
+	if before != fingerprint() {
+		t.Fatal("fingerprint() changed with PATH")
+	}
+
+	// Installing a tool changes the fingerprint
+	time.Sleep(10 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if before == fingerprint() {
+		t.Error("fingerprint() didn't change after a tool was installed")
+	}
+}
@@ -0,0 +1,171 @@
+package sysinfo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/llaoj/aiassist/internal/config"
+)
+
+// DefaultCollectorTimeout is the time budget of a collector that doesn't set its own
+const DefaultCollectorTimeout = 2 * time.Second
+
+// Collector gathers one aspect of the system information, e.g. memory or listening ports.
+//
+// Collect receives a copy of the basic system information (OS, init system, available
+// tools, ...) and must return within the deadline of ctx. The returned function stores
+// the result; it is called sequentially with the collected info and never for a
+// collector that ran out of time. Collect may return a nil function when there is
+// nothing to report, e.g. on an unsupported OS.
+type Collector interface {
+	Name() string           // Name used in the sysinfo.collectors config
+	Timeout() time.Duration // Default time budget
+	Collect(ctx context.Context, base SystemInfo) (func(*SystemInfo), error)
+}
+
+// VolatileCollector is implemented by collectors of facts that change from minute
+// to minute, like memory usage. They run in every session, as their facts in the
+// cache are outdated by then.
+type VolatileCollector interface {
+	Collector
+	Volatile() bool
+}
+
+// collector implements Collector with a function
+type collector struct {
+	name    string
+	timeout time.Duration
+	collect func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error)
+}
+
+func (c collector) Name() string { return c.name }
+
+func (c collector) Timeout() time.Duration { return c.timeout }
+
+func (c collector) Collect(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	return c.collect(ctx, base)
+}
+
+// volatileCollector implements VolatileCollector with a function
+type volatileCollector struct {
+	collector
+}
+
+func (volatileCollector) Volatile() bool { return true }
+
+func isVolatile(c Collector) bool {
+	v, ok := c.(VolatileCollector)
+	return ok && v.Volatile()
+}
+
+var (
+	collectorsMu sync.Mutex
+	collectors   = []Collector{
+		collector{"cpu", DefaultCollectorTimeout, collectCPU},
+		volatileCollector{collector{"memory", DefaultCollectorTimeout, collectMemory}},
+		volatileCollector{collector{"filesystems", DefaultCollectorTimeout, collectFilesystems}},
+		collector{"network", DefaultCollectorTimeout, collectNetwork},
+		volatileCollector{collector{"ports", DefaultCollectorTimeout, collectListeningPorts}},
+		volatileCollector{collector{"systemd", DefaultCollectorTimeout, collectFailedUnits}},
+		collector{"cgroup", DefaultCollectorTimeout, collectCgroupLimits},
+		volatileCollector{collector{"processes", DefaultCollectorTimeout, collectTopProcesses}},
+		kubernetesCollector,
+	}
+)
+
+// RegisterCollector adds a collector run by Collect after the built-in ones.
+// A collector with the name of a registered one replaces it.
+func RegisterCollector(c Collector) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+
+	for i, registered := range collectors {
+		if registered.Name() == c.Name() {
+			collectors[i] = c
+			return
+		}
+	}
+	collectors = append(collectors, c)
+}
+
+// registeredCollectors returns a snapshot of the registered collectors
+func registeredCollectors() []Collector {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+
+	return append([]Collector(nil), collectors...)
+}
+
+// refreshVolatileInfo recollects the facts of volatile collectors in info, which
+// were cached by an earlier session. Facts of the built-in ones are dropped first,
+// so that a collector failing now doesn't leave outdated values.
+func refreshVolatileInfo(info *SystemInfo) {
+	info.Memory = nil
+	info.Filesystems = nil
+	info.ListeningPorts = nil
+	info.FailedUnits = nil
+	info.TopProcesses = nil
+
+	var volatile []Collector
+	for _, c := range registeredCollectors() {
+		if isVolatile(c) {
+			volatile = append(volatile, c)
+		}
+	}
+	runCollectors(info, volatile, collectorSettings)
+}
+
+// collectorSettings returns the configured settings of the named collector
+func collectorSettings(name string) config.CollectorConfig {
+	if cfg := config.Get(); cfg != nil {
+		return cfg.GetSysinfoCollector(name)
+	}
+	return config.CollectorConfig{}
+}
+
+// runCollectors runs the enabled collectors concurrently, each within its time
+// budget, and stores their results in info. Failing and late collectors are skipped.
+func runCollectors(info *SystemInfo, collectors []Collector, settings func(name string) config.CollectorConfig) {
+	base := *info
+	results := make(chan func(*SystemInfo), len(collectors))
+	pending := 0
+	for _, c := range collectors {
+		s := settings(c.Name())
+		if s.Disabled {
+			continue
+		}
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = c.Timeout()
+		}
+
+		pending++
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			// Collectors should stop at the deadline, but don't wait for those that don't
+			done := make(chan func(*SystemInfo), 1)
+			go func() {
+				apply, err := c.Collect(ctx, base)
+				if err != nil {
+					apply = nil
+				}
+				done <- apply
+			}()
+			select {
+			case apply := <-done:
+				results <- apply
+			case <-ctx.Done():
+				results <- nil
+			}
+		}()
+	}
+
+	for range pending {
+		if apply := <-results; apply != nil {
+			apply(info)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package sysinfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/llaoj/aiassist/internal/config"
+)
+
+func TestRunCollectors(t *testing.T) {
+	hostname := func(name string) func(*SystemInfo) {
+		return func(info *SystemInfo) { info.Hostname += name }
+	}
+	collectors := []Collector{
+		collector{"fast", time.Second, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			if base.OS != "linux" {
+				t.Errorf("collector got base OS %q, want linux", base.OS)
+			}
+			return hostname("fast"), nil
+		}},
+		collector{"slow", 50 * time.Millisecond, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			<-ctx.Done()
+			return hostname("slow"), nil
+		}},
+		// Ignores its deadline: must not delay the others
+		collector{"stuck", 50 * time.Millisecond, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			time.Sleep(time.Hour)
+			return hostname("stuck"), nil
+		}},
+		collector{"failing", time.Second, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			return hostname("failing"), errors.New("failed")
+		}},
+		collector{"nothing", time.Second, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			return nil, nil
+		}},
+		collector{"disabled", time.Second, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			return hostname("disabled"), nil
+		}},
+		// Given more time than its default by the config
+		collector{"extended", time.Millisecond, func(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+			time.Sleep(20 * time.Millisecond)
+			return hostname("extended"), ctx.Err()
+		}},
+	}
+	settings := func(name string) config.CollectorConfig {
+		switch name {
+		case "disabled":
+			return config.CollectorConfig{Disabled: true}
+		case "extended":
+			return config.CollectorConfig{Timeout: time.Second}
+		}
+		return config.CollectorConfig{}
+	}
+
+	info := &SystemInfo{OS: "linux"}
+	start := time.Now()
+	runCollectors(info, collectors, settings)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("runCollectors() took %v", elapsed)
+	}
+	// Results are applied in completion order
+	if info.Hostname != "fastextended" && info.Hostname != "extendedfast" {
+		t.Errorf("runCollectors() applied %q, want fast and extended", info.Hostname)
+	}
+}
+
+func TestRegisterCollector(t *testing.T) {
+	saved := registeredCollectors()
+	defer func() { collectors = saved }()
+
+	RegisterCollector(collector{"custom", time.Second, nil})
+	RegisterCollector(collector{"memory", time.Minute, nil})
+
+	registered := registeredCollectors()
+	if len(registered) != len(saved)+1 || registered[len(registered)-1].Name() != "custom" {
+		t.Errorf("RegisterCollector() didn't append the custom collector")
+	}
+	for _, c := range registered {
+		if c.Name() == "memory" && c.Timeout() != time.Minute {
+			t.Errorf("RegisterCollector() didn't replace the memory collector")
+		}
+	}
+}
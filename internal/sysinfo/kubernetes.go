@@ -0,0 +1,149 @@
+package sysinfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// kubectlTimeout bounds each kubectl call, as the cluster may be unreachable
+	kubectlTimeout = 5 * time.Second
+	// maxPermissions limits the RBAC rules sent to the model
+	maxPermissions = 40
+)
+
+// KubernetesInfo describes the cluster kubectl talks to
+type KubernetesInfo struct {
+	Context       string   `json:"context"`                  // Current kubeconfig context
+	Namespace     string   `json:"namespace"`                // Default namespace of the context
+	ServerVersion string   `json:"server_version,omitempty"` // Cluster version, empty if unreachable
+	Permissions   []string `json:"permissions,omitempty"`    // Summary of kubectl auth can-i --list
+}
+
+// selfReviewResources are granted to everyone and only add noise to the permissions
+var selfReviewResources = []string{
+	"selfsubjectaccessreviews.authorization.k8s.io",
+	"selfsubjectrulesreviews.authorization.k8s.io",
+	"selfsubjectreviews.authentication.k8s.io",
+}
+
+// kubernetesCollector collects the kubeconfig context when kubectl is installed.
+// The cluster is queried for its version and the permissions of the user.
+var kubernetesCollector = collector{"kubernetes", 8 * time.Second, collectKubernetes}
+
+func collectKubernetes(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	if !slices.Contains(base.AvailableTools, "kubectl") {
+		return nil, nil
+	}
+	current := currentKubeContext(ctx)
+	if current == "" {
+		return nil, nil
+	}
+
+	k8s := &KubernetesInfo{Context: current, Namespace: "default"}
+	if ns, err := kubectl(ctx, "config", "view", "--minify", "-o", "jsonpath={..namespace}"); err == nil && ns != "" {
+		k8s.Namespace = ns
+	}
+
+	// Both calls may wait for the cluster: run them concurrently
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		// Exits non-zero when the server is unreachable, still printing the client version
+		output, _ := kubectl(ctx, "version", "-o", "json", "--request-timeout=3s")
+		var version struct {
+			ServerVersion struct {
+				GitVersion string `json:"gitVersion"`
+			} `json:"serverVersion"`
+		}
+		if json.Unmarshal([]byte(output), &version) == nil {
+			k8s.ServerVersion = version.ServerVersion.GitVersion
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if output, err := kubectl(ctx, "auth", "can-i", "--list", "--request-timeout=3s"); err == nil {
+			k8s.Permissions = summarizePermissions(output)
+		}
+	}()
+	wg.Wait()
+
+	return func(info *SystemInfo) { info.Kubernetes = k8s }, nil
+}
+
+// currentKubeContext returns the current kubeconfig context, or "" if there is none
+func currentKubeContext(ctx context.Context) string {
+	current, err := kubectl(ctx, "config", "current-context")
+	if err != nil {
+		return ""
+	}
+	return current
+}
+
+// refreshKubernetesInfo recollects the Kubernetes information of cached system info
+// when the current context changed, e.g. after kubectl config use-context. It
+// reports whether info changed.
+func refreshKubernetesInfo(info *SystemInfo) bool {
+	if !slices.Contains(info.AvailableTools, "kubectl") || collectorSettings(kubernetesCollector.name).Disabled {
+		return false
+	}
+	current := currentKubeContext(context.Background())
+	if info.Kubernetes != nil && info.Kubernetes.Context == current {
+		return false
+	}
+	if info.Kubernetes == nil && current == "" {
+		return false
+	}
+
+	info.Kubernetes = nil
+	runCollectors(info, []Collector{kubernetesCollector}, collectorSettings)
+	return true
+}
+
+func kubectl(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubectlTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "kubectl", args...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// summarizePermissions condenses kubectl auth can-i --list output into
+// "resource [verbs]" entries. Non-resource URLs are left out.
+//
+//	Resources   Non-Resource URLs   Resource Names   Verbs
+//	pods        []                  []               [get list watch]
+//	            [/healthz]          []               [get]
+func summarizePermissions(output string) []string {
+	var permissions []string
+	lines := strings.Split(output, "\n")
+	for _, line := range lines[min(1, len(lines)):] {
+		// Rows without a resource are non-resource URLs
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		resource := strings.Fields(line)[0]
+		if slices.Contains(selfReviewResources, resource) {
+			continue
+		}
+
+		verbs := ""
+		if i := strings.LastIndex(line, "["); i >= 0 {
+			verbs = strings.TrimSpace(line[i:])
+		}
+		permissions = append(permissions, resource+" "+verbs)
+	}
+
+	if len(permissions) > maxPermissions {
+		more := len(permissions) - maxPermissions
+		permissions = append(permissions[:maxPermissions], fmt.Sprintf("... and %d more", more))
+	}
+	return permissions
+}
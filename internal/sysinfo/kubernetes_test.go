@@ -0,0 +1,33 @@
+package sysinfo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSummarizePermissions(t *testing.T) {
+	output := `Resources                                       Non-Resource URLs   Resource Names   Verbs
+selfsubjectaccessreviews.authorization.k8s.io   []                  []               [create]
+selfsubjectrulesreviews.authorization.k8s.io    []                  []               [create]
+pods                                            []                  []               [get list watch]
+deployments.apps                                []                  [web api]        [get patch]
+                                                [/healthz]          []               [get]
+                                                [/version]          []               [get]
+`
+	want := []string{"pods [get list watch]", "deployments.apps [get patch]"}
+	if got := summarizePermissions(output); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("summarizePermissions() = %q, want %q", got, want)
+	}
+
+	// Long lists are capped
+	var sb strings.Builder
+	sb.WriteString("Resources   Non-Resource URLs   Resource Names   Verbs\n")
+	for i := range maxPermissions + 5 {
+		sb.WriteString(fmt.Sprintf("r%d.example.com   []   []   [get]\n", i))
+	}
+	got := summarizePermissions(sb.String())
+	if len(got) != maxPermissions+1 || got[maxPermissions] != "... and 5 more" {
+		t.Errorf("summarizePermissions() of %d rules returned %d entries ending with %q", maxPermissions+5, len(got), got[len(got)-1])
+	}
+}
@@ -0,0 +1,111 @@
+package sysinfo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// remoteScript prints the system information of a host as key=value lines.
+// It only relies on POSIX sh and common utilities.
+var remoteScript = `echo "os=$(uname -s)"
+echo "arch=$(uname -m)"
+echo "kernel=$(uname -r)"
+echo "hostname=$(hostname 2>/dev/null || uname -n)"
+echo "user=$(id -un)"
+echo "home=$HOME"
+echo "shell=$SHELL"
+[ -n "$SHELL" ] && echo "shell_version=$("$SHELL" --version 2>/dev/null | head -n 1)"
+[ -r /etc/os-release ] && (. /etc/os-release; echo "os_name=$NAME"; echo "os_version=$VERSION")
+command -v sw_vers >/dev/null 2>&1 && echo "os_name=macOS" && echo "os_version=$(sw_vers -productVersion)"
+command -v systemctl >/dev/null 2>&1 && echo "init=systemd"
+{ [ -f /.dockerenv ] || grep -qE 'docker|lxc|kubepods' /proc/1/cgroup 2>/dev/null; } && echo "container=1"
+sudo -n true >/dev/null 2>&1 && echo "sudo=1"
+for p in python3 python; do command -v $p >/dev/null 2>&1 && echo "python=$($p --version 2>&1)" && break; done
+for m in apt dnf yum pacman apk zypper brew; do command -v $m >/dev/null 2>&1 && echo "package_manager=$m" && break; done
+for t in ` + strings.Join(commonTools, " ") + `; do command -v $t >/dev/null 2>&1 && echo "tool=$t"; done
+command -v kubectl >/dev/null 2>&1 && echo "k8s_context=$(kubectl config current-context 2>/dev/null)" && echo "k8s_namespace=$(kubectl config view --minify -o 'jsonpath={..namespace}' 2>/dev/null)"
+exit 0`
+
+// CollectRemote collects the system information of another host. run executes a
+// shell script there and returns its output, e.g. over SSH.
+func CollectRemote(run func(script string) (string, error)) (*SystemInfo, error) {
+	output, err := run(remoteScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect remote system info: %w", err)
+	}
+
+	info := &SystemInfo{AvailableTools: []string{}}
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "os":
+			info.OS = strings.ToLower(value)
+		case "arch":
+			info.Arch = normalizeArch(value)
+		case "kernel":
+			info.Kernel = value
+		case "hostname":
+			info.Hostname = value
+		case "user":
+			info.User = value
+		case "home":
+			info.HomeDir = value
+		case "shell":
+			if i := strings.LastIndex(value, "/"); i >= 0 {
+				value = value[i+1:]
+			}
+			info.Shell = value
+		case "shell_version":
+			info.ShellVersion = value
+		case "os_name":
+			info.OSName = value
+		case "os_version":
+			info.OSVersion = value
+		case "init":
+			info.InitSystem = value
+		case "container":
+			info.IsContainer = true
+		case "sudo":
+			info.HasSudo = true
+		case "python":
+			info.PythonVersion = value
+		case "package_manager":
+			info.PackageManager = value
+		case "tool":
+			info.AvailableTools = append(info.AvailableTools, value)
+		case "k8s_context":
+			if value != "" {
+				info.Kubernetes = &KubernetesInfo{Context: value, Namespace: "default"}
+			}
+		case "k8s_namespace":
+			if info.Kubernetes != nil && value != "" {
+				info.Kubernetes.Namespace = value
+			}
+		}
+	}
+
+	if info.OS == "" {
+		return nil, fmt.Errorf("failed to collect remote system info: unexpected output %q", output)
+	}
+	if info.OS == "darwin" && info.InitSystem == "" {
+		info.InitSystem = "launchd"
+	}
+	return info, nil
+}
+
+// normalizeArch maps uname -m names to Go architecture names, as used locally
+func normalizeArch(arch string) string {
+	switch arch {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	case "i386", "i686":
+		return "386"
+	}
+	return arch
+}
@@ -0,0 +1,628 @@
+package sysinfo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxFilesystems  = 20 // Filesystems sent to the model
+	maxInterfaces   = 10 // Network interfaces sent to the model
+	maxPorts        = 40 // Listening ports sent to the model
+	maxFailedUnits  = 20 // Failed systemd units sent to the model
+	maxTopProcesses = 5  // Processes sent to the model
+)
+
+// CPUInfo describes the processors
+type CPUInfo struct {
+	Model string `json:"model,omitempty"` // CPU model name
+	Count int    `json:"count"`           // Logical CPUs
+}
+
+// MemoryInfo describes memory and swap, in bytes
+type MemoryInfo struct {
+	Total     uint64 `json:"total"`
+	Available uint64 `json:"available,omitempty"` // 0 when unknown
+	SwapTotal uint64 `json:"swap_total"`
+	SwapFree  uint64 `json:"swap_free"`
+}
+
+// Filesystem describes a mounted filesystem, sizes in bytes
+type Filesystem struct {
+	Device    string `json:"device"`
+	Mount     string `json:"mount"`
+	Size      uint64 `json:"size"`
+	Used      uint64 `json:"used"`
+	Available uint64 `json:"available"`
+}
+
+// NetworkInterface describes an interface that is up and has addresses
+type NetworkInterface struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"` // CIDR notation
+}
+
+// ListeningPort describes a socket accepting connections
+type ListeningPort struct {
+	Protocol string `json:"protocol"`          // tcp, tcp6, udp, ...
+	Address  string `json:"address"`           // Local address and port
+	Process  string `json:"process,omitempty"` // Owning process, when visible to the user
+}
+
+// CgroupLimits holds the resource limits of the cgroup of the process, e.g. of a container
+type CgroupLimits struct {
+	Memory uint64  `json:"memory,omitempty"` // Memory limit in bytes, 0 if unlimited
+	CPUs   float64 `json:"cpus,omitempty"`   // CPU quota in CPUs, 0 if unlimited
+}
+
+// Process describes a running process
+type Process struct {
+	PID     int     `json:"pid"`
+	Command string  `json:"command"`
+	CPU     float64 `json:"cpu"`    // CPU usage in percent
+	Memory  float64 `json:"memory"` // Memory usage in percent
+}
+
+// command runs a program and returns its output
+func command(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(output), err
+}
+
+func collectCPU(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	cpu := &CPUInfo{Count: runtime.NumCPU()}
+	switch base.OS {
+	case "linux":
+		if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+			cpu.Model = fieldValue(string(data), "model name")
+		}
+		// ARM kernels don't always report the model in /proc/cpuinfo
+		if cpu.Model == "" {
+			if output, err := command(ctx, "lscpu"); err == nil {
+				cpu.Model = fieldValue(output, "Model name")
+			}
+		}
+	case "darwin":
+		if output, err := command(ctx, "sysctl", "-n", "machdep.cpu.brand_string"); err == nil {
+			cpu.Model = strings.TrimSpace(output)
+		}
+	case "windows":
+		cpu.Model = os.Getenv("PROCESSOR_IDENTIFIER")
+	}
+	return func(info *SystemInfo) { info.CPU = cpu }, nil
+}
+
+// fieldValue returns the value of the first "key: value" line with key in text
+func fieldValue(text, key string) string {
+	for _, line := range strings.Split(text, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(name) == key {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func collectMemory(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	var memory *MemoryInfo
+	switch base.OS {
+	case "linux":
+		data, err := os.ReadFile("/proc/meminfo")
+		if err != nil {
+			return nil, err
+		}
+		memory = parseMeminfo(string(data))
+	case "darwin":
+		output, err := command(ctx, "sysctl", "-n", "hw.memsize")
+		if err != nil {
+			return nil, err
+		}
+		memory = &MemoryInfo{}
+		memory.Total, _ = strconv.ParseUint(strings.TrimSpace(output), 10, 64)
+		if output, err := command(ctx, "sysctl", "-n", "vm.swapusage"); err == nil {
+			memory.SwapTotal, memory.SwapFree = parseSwapUsage(output)
+		}
+	default:
+		return nil, nil
+	}
+	if memory.Total == 0 {
+		return nil, nil
+	}
+	return func(info *SystemInfo) { info.Memory = memory }, nil
+}
+
+// parseMeminfo parses /proc/meminfo
+func parseMeminfo(data string) *MemoryInfo {
+	memory := &MemoryInfo{}
+	fields := map[string]*uint64{
+		"MemTotal":     &memory.Total,
+		"MemAvailable": &memory.Available,
+		"SwapTotal":    &memory.SwapTotal,
+		"SwapFree":     &memory.SwapFree,
+	}
+	for _, line := range strings.Split(data, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		field, known := fields[name]
+		if !ok || !known {
+			continue
+		}
+		// Values are in kB: "MemTotal:       16315412 kB"
+		if kb, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64); err == nil {
+			*field = kb * 1024
+		}
+	}
+	return memory
+}
+
+// swapUsagePattern matches the sizes of sysctl vm.swapusage on macOS:
+// "total = 2048.00M  used = 1024.25M  free = 1023.75M  (encrypted)"
+var swapUsagePattern = regexp.MustCompile(`(total|free) = ([\d.]+)([KMG])`)
+
+func parseSwapUsage(output string) (total, free uint64) {
+	units := map[string]float64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+	for _, m := range swapUsagePattern.FindAllStringSubmatch(output, -1) {
+		size, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		bytes := uint64(size * units[m[3]])
+		if m[1] == "total" {
+			total = bytes
+		} else {
+			free = bytes
+		}
+	}
+	return total, free
+}
+
+func collectFilesystems(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	if base.OS == "windows" {
+		return nil, nil
+	}
+	// -P keeps each filesystem on one line, -k reports 1024-byte blocks
+	output, err := command(ctx, "df", "-kP")
+	if err != nil && output == "" {
+		return nil, err
+	}
+	filesystems := parseDf(output)
+	return func(info *SystemInfo) { info.Filesystems = filesystems }, nil
+}
+
+// dfLinePattern matches a line of df -kP; devices and mount points may contain spaces
+var dfLinePattern = regexp.MustCompile(`^(.+?)\s+(\d+)\s+(\d+)\s+(\d+)\s+\d+%\s+(/.*)$`)
+
+// pseudoDevices are virtual filesystems that don't hold data worth reporting
+var pseudoDevices = []string{"tmpfs", "devtmpfs", "udev", "none", "shm", "devfs", "efivarfs", "proc", "sysfs", "cgroup", "cgroup2"}
+
+// pseudoMounts are mount point prefixes of system internals
+var pseudoMounts = []string{"/dev", "/proc", "/sys", "/run", "/snap", "/System/Volumes", "/private/var/vm"}
+
+// parseDf parses df -kP output, leaving out pseudo filesystems
+func parseDf(output string) []Filesystem {
+	var filesystems []Filesystem
+	for _, line := range strings.Split(output, "\n") {
+		m := dfLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		device, mount := m[1], m[5]
+		size, _ := strconv.ParseUint(m[2], 10, 64)
+		if size == 0 || isPseudoFilesystem(device, mount) {
+			continue
+		}
+		used, _ := strconv.ParseUint(m[3], 10, 64)
+		available, _ := strconv.ParseUint(m[4], 10, 64)
+		filesystems = append(filesystems, Filesystem{
+			Device:    device,
+			Mount:     mount,
+			Size:      size * 1024,
+			Used:      used * 1024,
+			Available: available * 1024,
+		})
+		if len(filesystems) == maxFilesystems {
+			break
+		}
+	}
+	return filesystems
+}
+
+func isPseudoFilesystem(device, mount string) bool {
+	if slices.Contains(pseudoDevices, device) || strings.HasPrefix(device, "map ") {
+		return true
+	}
+	// The data volume of macOS holds the user files
+	if mount == "/System/Volumes/Data" {
+		return false
+	}
+	for _, prefix := range pseudoMounts {
+		if mount == prefix || strings.HasPrefix(mount, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func collectNetwork(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var network []NetworkInterface
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		var addresses []string
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+				addresses = append(addresses, ipNet.String())
+			}
+		}
+		// Skips interfaces like the veth pairs of containers
+		if len(addresses) == 0 {
+			continue
+		}
+		network = append(network, NetworkInterface{Name: iface.Name, Addresses: addresses})
+		if len(network) == maxInterfaces {
+			break
+		}
+	}
+	return func(info *SystemInfo) { info.Network = network }, nil
+}
+
+func collectListeningPorts(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	var ports []ListeningPort
+	switch base.OS {
+	case "linux":
+		output, err := command(ctx, "ss", "-ltnup")
+		if err == nil {
+			ports = parseSS(output)
+			break
+		}
+		output, err = command(ctx, "netstat", "-ltnup")
+		if err != nil && output == "" {
+			return nil, err
+		}
+		ports = parseNetstat(output)
+	case "darwin":
+		// Exits non-zero when nothing listens
+		output, _ := command(ctx, "lsof", "-nP", "-iTCP", "-sTCP:LISTEN")
+		ports = parseLsof(output)
+	default:
+		return nil, nil
+	}
+	return func(info *SystemInfo) { info.ListeningPorts = ports }, nil
+}
+
+// ssProcessPattern matches the process of an ss socket: users:(("sshd",pid=1,fd=3))
+var ssProcessPattern = regexp.MustCompile(`users:\(\("([^"]+)"`)
+
+// parseSS parses ss -ltnup output:
+//
+//	Netid State  Recv-Q Send-Q Local Address:Port Peer Address:Port Process
+//	tcp   LISTEN 0      128    0.0.0.0:22         0.0.0.0:*         users:(("sshd",pid=1,fd=3))
+func parseSS(output string) []ListeningPort {
+	var ports []ListeningPort
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] == "Netid" {
+			continue
+		}
+		port := ListeningPort{Protocol: fields[0], Address: fields[4]}
+		if m := ssProcessPattern.FindStringSubmatch(line); m != nil {
+			port.Process = m[1]
+		}
+		ports = appendPort(ports, port)
+	}
+	return ports
+}
+
+// parseNetstat parses netstat -ltnup output:
+//
+//	Proto Recv-Q Send-Q Local Address Foreign Address State  PID/Program name
+//	tcp        0      0 0.0.0.0:22    0.0.0.0:*       LISTEN 1/sshd
+func parseNetstat(output string) []ListeningPort {
+	var ports []ListeningPort
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !(strings.HasPrefix(fields[0], "tcp") || strings.HasPrefix(fields[0], "udp")) {
+			continue
+		}
+		port := ListeningPort{Protocol: fields[0], Address: fields[3]}
+		if _, program, ok := strings.Cut(fields[len(fields)-1], "/"); ok {
+			port.Process = program
+		}
+		ports = appendPort(ports, port)
+	}
+	return ports
+}
+
+// parseLsof parses lsof -nP -iTCP -sTCP:LISTEN output:
+//
+//	COMMAND PID USER FD  TYPE DEVICE  SIZE/OFF NODE NAME
+//	sshd    1   root 3u  IPv4 0x1234  0t0      TCP  *:22 (LISTEN)
+func parseLsof(output string) []ListeningPort {
+	var ports []ListeningPort
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 9 || fields[0] == "COMMAND" {
+			continue
+		}
+		ports = appendPort(ports, ListeningPort{
+			Protocol: strings.ToLower(fields[7]),
+			Address:  fields[8],
+			Process:  fields[0],
+		})
+	}
+	return ports
+}
+
+// appendPort appends port unless it's a duplicate, e.g. a socket per process worker
+func appendPort(ports []ListeningPort, port ListeningPort) []ListeningPort {
+	if len(ports) == maxPorts {
+		return ports
+	}
+	for _, p := range ports {
+		if p == port {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
+
+func collectFailedUnits(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	if base.InitSystem != "systemd" {
+		return nil, nil
+	}
+	output, err := command(ctx, "systemctl", "--failed", "--no-legend", "--plain")
+	if err != nil {
+		return nil, err
+	}
+
+	var units []string
+	for _, line := range strings.Split(output, "\n") {
+		// Older systemd versions print a bullet despite --plain
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "●"))
+		if len(fields) == 0 {
+			continue
+		}
+		units = append(units, fields[0])
+		if len(units) == maxFailedUnits {
+			break
+		}
+	}
+	return func(info *SystemInfo) { info.FailedUnits = units }, nil
+}
+
+func collectCgroupLimits(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	if base.OS != "linux" {
+		return nil, nil
+	}
+	self, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return nil, err
+	}
+	limits := readCgroupLimits("/sys/fs/cgroup", string(self))
+	return func(info *SystemInfo) { info.Cgroup = limits }, nil
+}
+
+// unlimitedCgroupV1 is the smallest cgroup v1 memory limit meaning unlimited
+// (the page-aligned maximum int64)
+const unlimitedCgroupV1 = 1 << 62
+
+// readCgroupLimits reads the memory and CPU limits of the cgroup of the process,
+// given the content of /proc/self/cgroup and where the cgroup filesystem is
+// mounted. It returns nil when nothing is limited.
+func readCgroupLimits(root, self string) *CgroupLimits {
+	limits := &CgroupLimits{}
+
+	// The cgroup of the process, by controller; "" is the cgroup v2 unified hierarchy.
+	// Lines look like "0::/system.slice/ssh.service" or "4:cpu,cpuacct:/docker/abc".
+	paths := map[string]string{}
+	for _, line := range strings.Split(self, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == "" {
+			paths[""] = parts[2]
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+
+	// Inside a container the own cgroup is usually mounted as the root
+	read := func(subdir, path, file string) string {
+		for _, dir := range []string{filepath.Join(root, subdir, path), filepath.Join(root, subdir)} {
+			if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+				return strings.TrimSpace(string(data))
+			}
+		}
+		return ""
+	}
+
+	if path, ok := paths[""]; ok {
+		if memory, err := strconv.ParseUint(read("", path, "memory.max"), 10, 64); err == nil {
+			limits.Memory = memory
+		}
+		// "max 100000" or "<quota> <period>"
+		if quota, period, ok := strings.Cut(read("", path, "cpu.max"), " "); ok {
+			limits.CPUs = cpuQuota(quota, period)
+		}
+	} else {
+		if memory, err := strconv.ParseUint(read("memory", paths["memory"], "memory.limit_in_bytes"), 10, 64); err == nil && memory < unlimitedCgroupV1 {
+			limits.Memory = memory
+		}
+		quota := read("cpu", paths["cpu"], "cpu.cfs_quota_us")
+		period := read("cpu", paths["cpu"], "cpu.cfs_period_us")
+		limits.CPUs = cpuQuota(quota, period)
+	}
+
+	if limits.Memory == 0 && limits.CPUs == 0 {
+		return nil
+	}
+	return limits
+}
+
+// cpuQuota converts a CFS quota and period in microseconds to CPUs, 0 if unlimited
+func cpuQuota(quota, period string) float64 {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+	return q / p
+}
+
+func collectTopProcesses(ctx context.Context, base SystemInfo) (func(*SystemInfo), error) {
+	if base.OS == "windows" {
+		return nil, nil
+	}
+	output, err := command(ctx, "ps", "-Ao", "pid,pcpu,pmem,comm")
+	if err != nil {
+		return nil, err
+	}
+	processes := parsePS(output)
+	return func(info *SystemInfo) { info.TopProcesses = processes }, nil
+}
+
+// parsePS parses ps -Ao pid,pcpu,pmem,comm output and returns the processes using
+// the most CPU, then memory
+func parsePS(output string) []Process {
+	var processes []Process
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue // Header
+		}
+		cpu, _ := strconv.ParseFloat(fields[1], 64)
+		memory, _ := strconv.ParseFloat(fields[2], 64)
+		// macOS reports the executable path, which may contain spaces
+		processes = append(processes, Process{
+			PID:     pid,
+			Command: filepath.Base(strings.Join(fields[3:], " ")),
+			CPU:     cpu,
+			Memory:  memory,
+		})
+	}
+
+	sort.SliceStable(processes, func(i, j int) bool {
+		if processes[i].CPU != processes[j].CPU {
+			return processes[i].CPU > processes[j].CPU
+		}
+		return processes[i].Memory > processes[j].Memory
+	})
+	return processes[:min(len(processes), maxTopProcesses)]
+}
+
+// formatBytes formats a size in bytes with a binary unit, e.g. "15.6 GiB"
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTP"[exp])
+}
+
+// formatResources formats the collected resources as context lines
+func (s *SystemInfo) formatResources(sb *strings.Builder) {
+	if c := s.CPU; c != nil {
+		sb.WriteString(fmt.Sprintf("CPUs: %d", c.Count))
+		if c.Model != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", c.Model))
+		}
+		sb.WriteString("\n")
+	}
+	if m := s.Memory; m != nil {
+		sb.WriteString(fmt.Sprintf("Memory: %s total", formatBytes(m.Total)))
+		if m.Available > 0 {
+			sb.WriteString(fmt.Sprintf(", %s available", formatBytes(m.Available)))
+		}
+		sb.WriteString("\n")
+		if m.SwapTotal > 0 {
+			sb.WriteString(fmt.Sprintf("Swap: %s total, %s free\n", formatBytes(m.SwapTotal), formatBytes(m.SwapFree)))
+		} else {
+			sb.WriteString("Swap: none\n")
+		}
+	}
+	if c := s.Cgroup; c != nil {
+		var limits []string
+		if c.Memory > 0 {
+			limits = append(limits, "memory "+formatBytes(c.Memory))
+		}
+		if c.CPUs > 0 {
+			limits = append(limits, fmt.Sprintf("%g CPUs", c.CPUs))
+		}
+		sb.WriteString(fmt.Sprintf("Cgroup Limits: %s\n", strings.Join(limits, ", ")))
+	}
+	if len(s.Filesystems) > 0 {
+		var filesystems []string
+		for _, fs := range s.Filesystems {
+			usage := 0
+			if total := fs.Used + fs.Available; total > 0 {
+				// Like df, relative to the space available to users
+				usage = int((fs.Used*100 + total - 1) / total)
+			}
+			filesystems = append(filesystems, fmt.Sprintf("%s %d%% of %s (%s)", fs.Mount, usage, formatBytes(fs.Size), fs.Device))
+		}
+		sb.WriteString(fmt.Sprintf("Filesystems: %s\n", strings.Join(filesystems, "; ")))
+	}
+	if len(s.Network) > 0 {
+		var interfaces []string
+		for _, iface := range s.Network {
+			interfaces = append(interfaces, fmt.Sprintf("%s %s", iface.Name, strings.Join(iface.Addresses, ", ")))
+		}
+		sb.WriteString(fmt.Sprintf("Network Interfaces: %s\n", strings.Join(interfaces, "; ")))
+	}
+	if len(s.ListeningPorts) > 0 {
+		var ports []string
+		for _, p := range s.ListeningPorts {
+			port := p.Protocol + " " + p.Address
+			if p.Process != "" {
+				port += " (" + p.Process + ")"
+			}
+			ports = append(ports, port)
+		}
+		sb.WriteString(fmt.Sprintf("Listening Ports: %s\n", strings.Join(ports, ", ")))
+	}
+	if len(s.FailedUnits) > 0 {
+		sb.WriteString(fmt.Sprintf("Failed Units: %s\n", strings.Join(s.FailedUnits, ", ")))
+	}
+	if len(s.TopProcesses) > 0 {
+		var processes []string
+		for _, p := range s.TopProcesses {
+			processes = append(processes, fmt.Sprintf("%s (pid %d, %.1f%% CPU, %.1f%% memory)", p.Command, p.PID, p.CPU, p.Memory))
+		}
+		sb.WriteString(fmt.Sprintf("Top Processes: %s\n", strings.Join(processes, "; ")))
+	}
+}
@@ -0,0 +1,188 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	data := `MemTotal:       16315412 kB
+MemFree:         1234567 kB
+MemAvailable:    9876543 kB
+SwapCached:            0 kB
+SwapTotal:       2097148 kB
+SwapFree:        2000000 kB
+`
+	want := &MemoryInfo{Total: 16315412 * 1024, Available: 9876543 * 1024, SwapTotal: 2097148 * 1024, SwapFree: 2000000 * 1024}
+	if got := parseMeminfo(data); *got != *want {
+		t.Errorf("parseMeminfo() = %+v, want %+v", got, want)
+	}
+
+	total, free := parseSwapUsage("total = 2048.00M  used = 1024.25M  free = 1023.75M  (encrypted)")
+	if total != 2048<<20 || free != uint64(1023.75*(1<<20)) {
+		t.Errorf("parseSwapUsage() = %d, %d", total, free)
+	}
+}
+
+func TestParseDf(t *testing.T) {
+	output := `Filesystem     1024-blocks      Used Available Capacity Mounted on
+udev               8123456         0   8123456       0% /dev
+tmpfs              1632000      2100   1629900       1% /run
+/dev/nvme0n1p2   479596204 201234567 253921234      45% /
+/dev/nvme0n1p1      523248      6220    517028       2% /boot/efi
+/dev/loop3           56832     56832         0     100% /snap/core18/2812
+/dev/sdb1        960000000 100000000 860000000      11% /mnt/My Backups
+map auto_home            0         0         0     100% /System/Volumes/Data/home
+/dev/disk3s5     971350180 500000000 400000000      56% /System/Volumes/Data
+`
+	got := parseDf(output)
+	var mounts []string
+	for _, fs := range got {
+		mounts = append(mounts, fs.Mount)
+	}
+	want := []string{"/", "/boot/efi", "/mnt/My Backups", "/System/Volumes/Data"}
+	if !reflect.DeepEqual(mounts, want) {
+		t.Fatalf("parseDf() mounts = %q, want %q", mounts, want)
+	}
+	if got[0].Device != "/dev/nvme0n1p2" || got[0].Size != 479596204*1024 || got[0].Used != 201234567*1024 {
+		t.Errorf("parseDf() root = %+v", got[0])
+	}
+}
+
+func TestParseListeningPorts(t *testing.T) {
+	ss := `Netid State  Recv-Q Send-Q Local Address:Port  Peer Address:Port Process
+udp   UNCONN 0      0      127.0.0.53%lo:53     0.0.0.0:*         users:(("systemd-resolve",pid=512,fd=13))
+tcp   LISTEN 0      4096   0.0.0.0:22           0.0.0.0:*         users:(("sshd",pid=901,fd=3))
+tcp   LISTEN 0      511    0.0.0.0:80           0.0.0.0:*         users:(("nginx",pid=1201,fd=6),("nginx",pid=1200,fd=6))
+tcp   LISTEN 0      4096   [::]:22              [::]:*
+`
+	want := []ListeningPort{
+		{"udp", "127.0.0.53%lo:53", "systemd-resolve"},
+		{"tcp", "0.0.0.0:22", "sshd"},
+		{"tcp", "0.0.0.0:80", "nginx"},
+		{"tcp", "[::]:22", ""},
+	}
+	if got := parseSS(ss); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSS() = %+v, want %+v", got, want)
+	}
+
+	netstat := `Active Internet connections (only servers)
+Proto Recv-Q Send-Q Local Address           Foreign Address         State       PID/Program name
+tcp        0      0 0.0.0.0:22              0.0.0.0:*               LISTEN      901/sshd
+tcp6       0      0 :::80                   :::*                    LISTEN      -
+udp        0      0 127.0.0.53:53           0.0.0.0:*                           512/systemd-resolve
+`
+	want = []ListeningPort{
+		{"tcp", "0.0.0.0:22", "sshd"},
+		{"tcp6", ":::80", ""},
+		{"udp", "127.0.0.53:53", "systemd-resolve"},
+	}
+	if got := parseNetstat(netstat); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNetstat() = %+v, want %+v", got, want)
+	}
+
+	lsof := `COMMAND   PID  USER   FD   TYPE             DEVICE SIZE/OFF NODE NAME
+postgres  611  me     7u  IPv6 0x5f5a2e1b2c3d4e5f      0t0  TCP [::1]:5432 (LISTEN)
+postgres  611  me     8u  IPv4 0x5f5a2e1b2c3d4e60      0t0  TCP 127.0.0.1:5432 (LISTEN)
+`
+	want = []ListeningPort{
+		{"tcp", "[::1]:5432", "postgres"},
+		{"tcp", "127.0.0.1:5432", "postgres"},
+	}
+	if got := parseLsof(lsof); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLsof() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadCgroupLimits(t *testing.T) {
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// cgroup v2, limited container with its cgroup mounted as the root
+	root := t.TempDir()
+	write(filepath.Join(root, "memory.max"), "536870912\n")
+	write(filepath.Join(root, "cpu.max"), "150000 100000\n")
+	want := &CgroupLimits{Memory: 512 << 20, CPUs: 1.5}
+	if got := readCgroupLimits(root, "0::/\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("readCgroupLimits() v2 = %+v, want %+v", got, want)
+	}
+
+	// cgroup v2, unlimited service
+	root = t.TempDir()
+	write(filepath.Join(root, "system.slice/ssh.service/memory.max"), "max\n")
+	write(filepath.Join(root, "system.slice/ssh.service/cpu.max"), "max 100000\n")
+	if got := readCgroupLimits(root, "0::/system.slice/ssh.service\n"); got != nil {
+		t.Errorf("readCgroupLimits() unlimited = %+v, want nil", got)
+	}
+
+	// cgroup v1 with a memory limit only
+	root = t.TempDir()
+	write(filepath.Join(root, "memory/docker/abc/memory.limit_in_bytes"), "1073741824\n")
+	write(filepath.Join(root, "cpu/docker/abc/cpu.cfs_quota_us"), "-1\n")
+	write(filepath.Join(root, "cpu/docker/abc/cpu.cfs_period_us"), "100000\n")
+	self := "12:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n"
+	want = &CgroupLimits{Memory: 1 << 30}
+	if got := readCgroupLimits(root, self); !reflect.DeepEqual(got, want) {
+		t.Errorf("readCgroupLimits() v1 = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePS(t *testing.T) {
+	output := `  PID  %CPU %MEM COMM
+    1   0.0  0.1 /sbin/launchd
+  411  35.2  2.0 /Applications/Google Chrome.app/Contents/MacOS/Google Chrome
+  977   0.0 12.5 java
+ 1200   3.1  0.2 nginx
+ 1300   3.1  0.9 postgres
+ 1400   0.5  0.0 sshd
+`
+	var commands []string
+	for _, p := range parsePS(output) {
+		commands = append(commands, p.Command)
+	}
+	want := []string{"Google Chrome", "postgres", "nginx", "sshd", "java"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("parsePS() = %q, want %q", commands, want)
+	}
+}
+
+func TestFormatResources(t *testing.T) {
+	info := &SystemInfo{
+		OSName:         "Ubuntu",
+		OS:             "linux",
+		CPU:            &CPUInfo{Model: "AMD EPYC 7B13", Count: 8},
+		Memory:         &MemoryInfo{Total: 16 << 30, Available: 9 << 30},
+		Cgroup:         &CgroupLimits{Memory: 512 << 20},
+		Filesystems:    []Filesystem{{Device: "/dev/sda1", Mount: "/", Size: 100 << 30, Used: 45 << 30, Available: 55 << 30}},
+		Network:        []NetworkInterface{{Name: "eth0", Addresses: []string{"10.0.0.5/24"}}},
+		ListeningPorts: []ListeningPort{{"tcp", "0.0.0.0:22", "sshd"}},
+		FailedUnits:    []string{"nginx.service"},
+		TopProcesses:   []Process{{PID: 977, Command: "java", CPU: 35.2, Memory: 12.5}},
+	}
+	context := info.FormatAsContext()
+	for _, want := range []string{
+		"CPUs: 8 (AMD EPYC 7B13)\n",
+		"Memory: 16.0 GiB total, 9.0 GiB available\n",
+		"Swap: none\n",
+		"Cgroup Limits: memory 512.0 MiB\n",
+		"Filesystems: / 45% of 100.0 GiB (/dev/sda1)\n",
+		"Network Interfaces: eth0 10.0.0.5/24\n",
+		"Listening Ports: tcp 0.0.0.0:22 (sshd)\n",
+		"Failed Units: nginx.service\n",
+		"Top Processes: java (pid 977, 35.2% CPU, 12.5% memory)\n",
+	} {
+		if !strings.Contains(context, want) {
+			t.Errorf("FormatAsContext() = %q, missing %q", context, want)
+		}
+	}
+}
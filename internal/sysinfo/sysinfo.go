@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
 const (
@@ -33,6 +34,20 @@ type SystemInfo struct {
 	HomeDir        string   `json:"home_dir"`        // Home directory
 	PythonVersion  string   `json:"python_version"`  // Python version if available
 	AvailableTools []string `json:"available_tools"` // Common tools available (docker, git, curl, etc.)
+
+	CPU            *CPUInfo           `json:"cpu,omitempty"`             // Processors
+	Memory         *MemoryInfo        `json:"memory,omitempty"`          // Memory and swap
+	Filesystems    []Filesystem       `json:"filesystems,omitempty"`     // Mounted filesystems and their usage
+	Network        []NetworkInterface `json:"network,omitempty"`         // Network interfaces with addresses
+	ListeningPorts []ListeningPort    `json:"listening_ports,omitempty"` // Sockets accepting connections
+	FailedUnits    []string           `json:"failed_units,omitempty"`    // Failed systemd units
+	Cgroup         *CgroupLimits      `json:"cgroup,omitempty"`          // Resource limits, e.g. of the container
+	TopProcesses   []Process          `json:"top_processes,omitempty"`   // Processes using the most CPU
+
+	Kubernetes *KubernetesInfo `json:"kubernetes,omitempty"` // Cluster kubectl talks to, nil without kubectl or context
+
+	CollectedAt time.Time `json:"collected_at"`          // When the information was collected
+	Fingerprint string    `json:"fingerprint,omitempty"` // System state the information was collected in
 }
 
 func GetConfigDir() (string, error) {
@@ -51,20 +66,29 @@ func GetSysInfoPath() (string, error) {
 	return filepath.Join(dir, sysInfoFile), nil
 }
 
-// LoadOrCollect loads system info from cache or collects if not exists
+// LoadOrCollect loads system info from cache, collecting it if the cache doesn't
+// exist or the system changed since it was collected. A cache older than the
+// configured TTL is used and refreshed in the background. Facts of volatile
+// collectors, like memory and disk usage, are always collected anew.
 func LoadOrCollect() (*SystemInfo, error) {
-	path, err := GetSysInfoPath()
-	if err != nil {
-		return nil, err
+	info, err := Load()
+	if err != nil || info.Fingerprint != fingerprint() {
+		// Missing, unreadable, or stale: the kernel, distribution or tools changed
+		return CollectAndSave()
 	}
 
-	// If file exists, load from cache
-	if _, err := os.Stat(path); err == nil {
-		return Load()
+	// The kubeconfig context changes far more often than the rest
+	if refreshKubernetesInfo(info) {
+		if err := Save(info); err != nil {
+			return nil, err
+		}
 	}
 
-	// File doesn't exist, collect and save
-	return CollectAndSave()
+	if time.Since(info.CollectedAt) > cacheTTL() {
+		refreshInBackground()
+	}
+	refreshVolatileInfo(info)
+	return info, nil
 }
 
 func Load() (*SystemInfo, error) {
@@ -88,8 +112,10 @@ func Load() (*SystemInfo, error) {
 
 func Collect() (*SystemInfo, error) {
 	info := &SystemInfo{
-		OS:   runtime.GOOS,
-		Arch: runtime.GOARCH,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		CollectedAt: time.Now(),
+		Fingerprint: fingerprint(),
 	}
 
 	// Get current user and home directory
@@ -124,6 +150,9 @@ func Collect() (*SystemInfo, error) {
 	collectPythonVersion(info)
 	collectAvailableTools(info)
 
+	// Everything else comes from collectors, which may take longer
+	runCollectors(info, registeredCollectors(), collectorSettings)
+
 	return info, nil
 }
 
@@ -161,7 +190,8 @@ func Save(info *SystemInfo) error {
 		return fmt.Errorf("failed to marshal sysinfo: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	// Sessions may load the cache while another one refreshes it
+	if err := writeFileAtomic(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write sysinfo file: %w", err)
 	}
 
@@ -280,6 +310,16 @@ func (s *SystemInfo) FormatAsContext() string {
 	if s.Hostname != "" {
 		sb.WriteString(fmt.Sprintf("Hostname: %s\n", s.Hostname))
 	}
+	s.formatResources(&sb)
+	if k := s.Kubernetes; k != nil {
+		sb.WriteString(fmt.Sprintf("Kubernetes Context: %s (namespace: %s)\n", k.Context, k.Namespace))
+		if k.ServerVersion != "" {
+			sb.WriteString(fmt.Sprintf("Kubernetes Server: %s\n", k.ServerVersion))
+		}
+		if len(k.Permissions) > 0 {
+			sb.WriteString(fmt.Sprintf("Kubernetes Permissions: %s\n", strings.Join(k.Permissions, "; ")))
+		}
+	}
 	return sb.String()
 }
 
@@ -339,11 +379,13 @@ func collectPythonVersion(info *SystemInfo) {
 	}
 }
 
+// commonTools are reported in AvailableTools when installed
+var commonTools = []string{"docker", "git", "curl", "wget", "kubectl", "helm", "terraform", "ansible"}
+
 func collectAvailableTools(info *SystemInfo) {
-	tools := []string{"docker", "git", "curl", "wget", "kubectl", "helm", "terraform", "ansible"}
 	available := []string{}
 
-	for _, tool := range tools {
+	for _, tool := range commonTools {
 		if _, err := exec.LookPath(tool); err == nil {
 			available = append(available, tool)
 		}
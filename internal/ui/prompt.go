@@ -3,6 +3,7 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -15,6 +16,33 @@ import (
 // The caller should treat this as a clean exit request.
 var ErrInterrupted = errors.New("interrupted")
 
+// promptInput is the terminal prompts read from. When nil, BubbleTea reads
+// from stdin, or opens the terminal itself if stdin is not one.
+var promptInput *os.File
+
+// UseTTY opens the controlling terminal and reads all further prompt input from it,
+// so prompts keep working after stdin was consumed by piped data.
+// The returned function closes the terminal and restores the default input.
+func UseTTY() (func(), error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return nil, fmt.Errorf("no terminal available for interactive input: %w", err)
+	}
+	promptInput = tty
+	return func() {
+		promptInput = nil
+		tty.Close()
+	}, nil
+}
+
+// newProgram creates a BubbleTea program reading from the configured prompt input
+func newProgram(model tea.Model) *tea.Program {
+	if promptInput != nil {
+		return tea.NewProgram(model, tea.WithInput(promptInput))
+	}
+	return tea.NewProgram(model)
+}
+
 // inputModel is a custom text input model using bubbletea
 type inputModel struct {
 	textInput   textinput.Model
@@ -59,7 +87,22 @@ func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m inputModel) View() string {
-	return m.prompt + "\n" + m.textInput.View()
+	view := m.prompt + "\n" + m.textInput.View()
+
+	// List the completions when there is a choice, Tab accepts the highlighted one
+	if matches := m.textInput.MatchedSuggestions(); len(matches) > 1 {
+		current := m.textInput.CurrentSuggestionIndex()
+		items := make([]string, len(matches))
+		for i, match := range matches {
+			color := lipgloss.Color("240")
+			if i == current {
+				color = lipgloss.Color("36")
+			}
+			items[i] = lipgloss.NewStyle().Foreground(color).Render(match)
+		}
+		view += "\n  " + strings.Join(items, "  ")
+	}
+	return view
 }
 
 // selectModel is a custom select model using bubbletea
@@ -122,11 +165,114 @@ func (m selectModel) View() string {
 	return b.String()
 }
 
+// multiSelectModel is a select model allowing several options to be chosen
+type multiSelectModel struct {
+	prompt      string
+	options     []string
+	chosen      []bool
+	cursor      int
+	interrupted bool // set to true when user pressed Ctrl+C
+}
+
+func newMultiSelectModel(prompt string, options []string, chosen []bool) multiSelectModel {
+	m := multiSelectModel{
+		prompt:  prompt,
+		options: options,
+		chosen:  make([]bool, len(options)),
+	}
+	copy(m.chosen, chosen)
+	return m
+}
+
+func (m multiSelectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEnter:
+			return m, tea.Quit
+		case tea.KeyCtrlC:
+			m.interrupted = true
+			return m, tea.Quit
+		case tea.KeyUp, tea.KeyLeft:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case tea.KeyDown, tea.KeyRight:
+			if m.cursor < len(m.options)-1 {
+				m.cursor++
+			}
+		case tea.KeySpace:
+			m.chosen[m.cursor] = !m.chosen[m.cursor]
+		case tea.KeyRunes:
+			// "a" toggles all options
+			if string(msg.Runes) == "a" {
+				all := true
+				for _, c := range m.chosen {
+					all = all && c
+				}
+				for i := range m.chosen {
+					m.chosen[i] = !all
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m multiSelectModel) View() string {
+	var b strings.Builder
+	b.WriteString(m.prompt + "\n\n")
+
+	for i, option := range m.options {
+		box := "[ ] "
+		if m.chosen[i] {
+			box = "[x] "
+		}
+		if i == m.cursor {
+			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("36")).Render("> "+box+option) + "\n")
+		} else {
+			b.WriteString("  " + box + option + "\n")
+		}
+	}
+
+	return b.String()
+}
+
 // PromptInput displays an input prompt and returns the user's input.
 // Returns ErrInterrupted if the user pressed Ctrl+C.
 func PromptInput(prompt string, translator *i18n.I18n) (string, error) {
+	return PromptInputWithValue(prompt, "", translator)
+}
+
+// PromptInputWithValue displays an input prompt prefilled with value, which the
+// user can edit before pressing Enter.
+// Returns ErrInterrupted if the user pressed Ctrl+C.
+func PromptInputWithValue(prompt, value string, translator *i18n.I18n) (string, error) {
 	model := newInputModel(prompt)
-	p := tea.NewProgram(model)
+	if value != "" {
+		model.textInput.SetValue(value)
+		model.textInput.CursorEnd()
+	}
+	return runInput(model)
+}
+
+// PromptInputWithSuggestions displays an input prompt completing the input with
+// suggestions starting with it: Tab accepts the suggestion, ↑/↓ choose another.
+// Returns ErrInterrupted if the user pressed Ctrl+C.
+func PromptInputWithSuggestions(prompt string, suggestions []string, translator *i18n.I18n) (string, error) {
+	model := newInputModel(prompt)
+	model.textInput.ShowSuggestions = true
+	model.textInput.SetSuggestions(suggestions)
+	return runInput(model)
+}
+
+func runInput(model inputModel) (string, error) {
+	p := newProgram(model)
 	final, err := p.Run()
 	if err != nil {
 		return "", fmt.Errorf("input error: %w", err)
@@ -139,20 +285,68 @@ func PromptInput(prompt string, translator *i18n.I18n) (string, error) {
 	return m.textInput.Value(), nil
 }
 
-// PromptConfirm displays a confirmation prompt and returns the result.
+// PromptSelect displays a list of options and returns the index of the chosen one.
 // Returns ErrInterrupted if the user pressed Ctrl+C.
-func PromptConfirm(prompt string, translator *i18n.I18n) (bool, error) {
-	options := []string{"Yes", "No"}
+func PromptSelect(prompt string, options []string, translator *i18n.I18n) (int, error) {
 	model := newSelectModel(prompt, options)
-	p := tea.NewProgram(model)
+	p := newProgram(model)
 	final, err := p.Run()
 	if err != nil {
-		return false, fmt.Errorf("confirmation error: %w", err)
+		return 0, fmt.Errorf("selection error: %w", err)
 	}
 
 	m := final.(selectModel)
 	if m.interrupted {
-		return false, ErrInterrupted
+		return 0, ErrInterrupted
 	}
-	return m.selected == 0, nil
+	return m.selected, nil
+}
+
+// PromptMultiSelect displays a list of options, initially chosen as given, and returns
+// the indices of the options chosen with Space ("a" toggles all, Enter confirms).
+// Returns ErrInterrupted if the user pressed Ctrl+C.
+func PromptMultiSelect(prompt string, options []string, chosen []bool, translator *i18n.I18n) ([]int, error) {
+	model := newMultiSelectModel(prompt, options, chosen)
+	p := newProgram(model)
+	final, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("selection error: %w", err)
+	}
+
+	m := final.(multiSelectModel)
+	if m.interrupted {
+		return nil, ErrInterrupted
+	}
+
+	var indices []int
+	for i, c := range m.chosen {
+		if c {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
+
+// PromptConfirm displays a confirmation prompt and returns the result.
+// Returns ErrInterrupted if the user pressed Ctrl+C.
+func PromptConfirm(prompt string, translator *i18n.I18n) (bool, error) {
+	selected, err := PromptSelect(prompt, []string{"Yes", "No"}, translator)
+	if err != nil {
+		return false, err
+	}
+	return selected == 0, nil
+}
+
+// Choices returned by PromptConfirmOrEdit
+const (
+	ChoiceYes = iota
+	ChoiceEdit
+	ChoiceNo
+)
+
+// PromptConfirmOrEdit displays a confirmation prompt that also offers to edit
+// the subject before confirming. Returns ChoiceYes, ChoiceEdit or ChoiceNo.
+// Returns ErrInterrupted if the user pressed Ctrl+C.
+func PromptConfirmOrEdit(prompt string, translator *i18n.I18n) (int, error) {
+	return PromptSelect(prompt, []string{"Yes", "Edit", "No"}, translator)
 }